@@ -155,11 +155,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.Metal3MachineTemplateSpec)(nil), (*Metal3MachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTemplateSpec(a.(*v1alpha4.Metal3MachineTemplateSpec), b.(*Metal3MachineTemplateSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddConversionFunc((*Metal3ClusterSpec)(nil), (*v1alpha4.Metal3ClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Metal3ClusterSpec_To_v1alpha4_Metal3ClusterSpec(a.(*Metal3ClusterSpec), b.(*v1alpha4.Metal3ClusterSpec), scope)
 	}); err != nil {
@@ -200,6 +195,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha4.Metal3MachineTemplateSpec)(nil), (*Metal3MachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTemplateSpec(a.(*v1alpha4.Metal3MachineTemplateSpec), b.(*Metal3MachineTemplateSpec), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -372,7 +372,18 @@ func autoConvert_v1alpha2_Metal3ClusterSpec_To_v1alpha4_Metal3ClusterSpec(in *Me
 
 func autoConvert_v1alpha4_Metal3ClusterSpec_To_v1alpha2_Metal3ClusterSpec(in *v1alpha4.Metal3ClusterSpec, out *Metal3ClusterSpec, s conversion.Scope) error {
 	// WARNING: in.ControlPlaneEndpoint requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointFromIPPool requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneVIP requires manual conversion: does not exist in peer-type
+	// WARNING: in.LoadBalancerBackends requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointHealthCheck requires manual conversion: does not exist in peer-type
 	out.NoCloudProvider = in.NoCloudProvider
+	// WARNING: in.SetProviderIDOnNodes requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomainLabelKey requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeLabelPrefixes requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeAnnotationPrefixes requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProviderIDFormat requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeDeletion requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -390,6 +401,9 @@ func autoConvert_v1alpha4_Metal3ClusterStatus_To_v1alpha2_Metal3ClusterStatus(in
 	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointLastHealthy requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -493,6 +507,12 @@ func autoConvert_v1alpha4_Metal3MachineSpec_To_v1alpha2_Metal3MachineSpec(in *v1
 	if err := Convert_v1alpha4_HostSelector_To_v1alpha2_HostSelector(&in.HostSelector, &out.HostSelector, s); err != nil {
 		return err
 	}
+	// WARNING: in.HostScoring requires manual conversion: does not exist in peer-type
+	// WARNING: in.HardwareRequirements requires manual conversion: does not exist in peer-type
+	// WARNING: in.AntiAffinity requires manual conversion: does not exist in peer-type
+	// WARNING: in.InPlaceUpgrade requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeLabels requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeTaints requires manual conversion: does not exist in peer-type
 	// WARNING: in.DataTemplate requires manual conversion: does not exist in peer-type
 	// WARNING: in.MetaData requires manual conversion: does not exist in peer-type
 	// WARNING: in.NetworkData requires manual conversion: does not exist in peer-type
@@ -520,6 +540,9 @@ func autoConvert_v1alpha4_Metal3MachineStatus_To_v1alpha2_Metal3MachineStatus(in
 	// WARNING: in.RenderedData requires manual conversion: does not exist in peer-type
 	// WARNING: in.MetaData requires manual conversion: does not exist in peer-type
 	// WARNING: in.NetworkData requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageUpgrade requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostSelection requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -631,10 +654,6 @@ func autoConvert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTem
 	if err := Convert_v1alpha4_Metal3MachineTemplateResource_To_v1alpha2_Metal3MachineTemplateResource(&in.Template, &out.Template, s); err != nil {
 		return err
 	}
+	// WARNING: in.NodeReuse requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTemplateSpec is an autogenerated conversion function.
-func Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTemplateSpec(in *v1alpha4.Metal3MachineTemplateSpec, out *Metal3MachineTemplateSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha2_Metal3MachineTemplateSpec(in, out, s)
-}
@@ -30,12 +30,46 @@ const (
 
 func (src *Metal3Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha4.Metal3Cluster)
-	return Convert_v1alpha3_Metal3Cluster_To_v1alpha4_Metal3Cluster(src, dst, nil)
+	if err := Convert_v1alpha3_Metal3Cluster_To_v1alpha4_Metal3Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1alpha4.Metal3Cluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.FailureDomainLabelKey = restored.Spec.FailureDomainLabelKey
+	dst.Spec.FailureDomains = restored.Spec.FailureDomains
+	dst.Spec.NodeLabelPrefixes = restored.Spec.NodeLabelPrefixes
+	dst.Spec.NodeAnnotationPrefixes = restored.Spec.NodeAnnotationPrefixes
+	dst.Spec.SetProviderIDOnNodes = restored.Spec.SetProviderIDOnNodes
+	dst.Spec.ProviderIDFormat = restored.Spec.ProviderIDFormat
+	dst.Spec.NodeDeletion = restored.Spec.NodeDeletion
+	dst.Spec.ControlPlaneEndpointFromIPPool = restored.Spec.ControlPlaneEndpointFromIPPool
+	dst.Spec.ControlPlaneVIP = restored.Spec.ControlPlaneVIP
+	dst.Spec.LoadBalancerBackends = restored.Spec.LoadBalancerBackends
+	dst.Spec.ControlPlaneEndpointHealthCheck = restored.Spec.ControlPlaneEndpointHealthCheck
+	dst.Status.FailureDomains = restored.Status.FailureDomains
+	dst.Status.ControlPlaneEndpointLastHealthy = restored.Status.ControlPlaneEndpointLastHealthy
+	dst.Status.Conditions = restored.Status.Conditions
+
+	return nil
 }
 
 func (dst *Metal3Cluster) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha4.Metal3Cluster)
-	return Convert_v1alpha4_Metal3Cluster_To_v1alpha3_Metal3Cluster(src, dst, nil)
+	if err := Convert_v1alpha4_Metal3Cluster_To_v1alpha3_Metal3Cluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *Metal3ClusterList) ConvertTo(dstRaw conversion.Hub) error {
@@ -63,11 +97,20 @@ func (src *Metal3Machine) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.MetaData = restored.Spec.MetaData
 	dst.Spec.NetworkData = restored.Spec.NetworkData
 	dst.Spec.DataTemplate = restored.Spec.DataTemplate
+	dst.Spec.HostScoring = restored.Spec.HostScoring
+	dst.Spec.HardwareRequirements = restored.Spec.HardwareRequirements
+	dst.Spec.AntiAffinity = restored.Spec.AntiAffinity
+	dst.Spec.InPlaceUpgrade = restored.Spec.InPlaceUpgrade
+	dst.Spec.NodeLabels = restored.Spec.NodeLabels
+	dst.Spec.NodeTaints = restored.Spec.NodeTaints
 	dst.Spec.Image = restored.Spec.Image
 	dst.Status.UserData = restored.Status.UserData
 	dst.Status.MetaData = restored.Status.MetaData
 	dst.Status.NetworkData = restored.Status.NetworkData
 	dst.Status.RenderedData = restored.Status.RenderedData
+	dst.Status.ImageUpgrade = restored.Status.ImageUpgrade
+	dst.Status.HostSelection = restored.Status.HostSelection
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -111,7 +154,14 @@ func (src *Metal3MachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.Template.Spec.MetaData = restored.Spec.Template.Spec.MetaData
 	dst.Spec.Template.Spec.NetworkData = restored.Spec.Template.Spec.NetworkData
 	dst.Spec.Template.Spec.DataTemplate = restored.Spec.Template.Spec.DataTemplate
+	dst.Spec.Template.Spec.HostScoring = restored.Spec.Template.Spec.HostScoring
+	dst.Spec.Template.Spec.HardwareRequirements = restored.Spec.Template.Spec.HardwareRequirements
+	dst.Spec.Template.Spec.AntiAffinity = restored.Spec.Template.Spec.AntiAffinity
+	dst.Spec.Template.Spec.InPlaceUpgrade = restored.Spec.Template.Spec.InPlaceUpgrade
+	dst.Spec.Template.Spec.NodeLabels = restored.Spec.Template.Spec.NodeLabels
+	dst.Spec.Template.Spec.NodeTaints = restored.Spec.Template.Spec.NodeTaints
 	dst.Spec.Template.Spec.Image = restored.Spec.Template.Spec.Image
+	dst.Spec.NodeReuse = restored.Spec.NodeReuse
 
 	return nil
 }
@@ -140,6 +190,30 @@ func (dst *Metal3MachineTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
 	return Convert_v1alpha4_Metal3MachineTemplateList_To_v1alpha3_Metal3MachineTemplateList(src, dst, nil)
 }
 
+func Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(in *v1alpha4.Metal3MachineTemplateSpec, out *Metal3MachineTemplateSpec, s apiconversion.Scope) error {
+	if err := autoConvert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(in *v1alpha4.Metal3ClusterSpec, out *Metal3ClusterSpec, s apiconversion.Scope) error {
+	if err := autoConvert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Convert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(in *v1alpha4.Metal3ClusterStatus, out *Metal3ClusterStatus, s apiconversion.Scope) error {
+	if err := autoConvert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func Convert_v1alpha4_Metal3MachineSpec_To_v1alpha3_Metal3MachineSpec(in *v1alpha4.Metal3MachineSpec, out *Metal3MachineSpec, s apiconversion.Scope) error {
 	if err := autoConvert_v1alpha4_Metal3MachineSpec_To_v1alpha3_Metal3MachineSpec(in, out, s); err != nil {
 		return err
@@ -100,21 +100,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.Metal3ClusterSpec)(nil), (*Metal3ClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(a.(*v1alpha4.Metal3ClusterSpec), b.(*Metal3ClusterSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Metal3ClusterStatus)(nil), (*v1alpha4.Metal3ClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Metal3ClusterStatus_To_v1alpha4_Metal3ClusterStatus(a.(*Metal3ClusterStatus), b.(*v1alpha4.Metal3ClusterStatus), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.Metal3ClusterStatus)(nil), (*Metal3ClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(a.(*v1alpha4.Metal3ClusterStatus), b.(*Metal3ClusterStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Metal3Machine)(nil), (*v1alpha4.Metal3Machine)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Metal3Machine_To_v1alpha4_Metal3Machine(a.(*Metal3Machine), b.(*v1alpha4.Metal3Machine), scope)
 	}); err != nil {
@@ -180,13 +170,18 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.Metal3MachineTemplateSpec)(nil), (*Metal3MachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(a.(*v1alpha4.Metal3MachineTemplateSpec), b.(*Metal3MachineTemplateSpec), scope)
+	if err := s.AddConversionFunc((*v1alpha4.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Image_To_v1alpha3_Image(a.(*v1alpha4.Image), b.(*Image), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddConversionFunc((*v1alpha4.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Image_To_v1alpha3_Image(a.(*v1alpha4.Image), b.(*Image), scope)
+	if err := s.AddConversionFunc((*v1alpha4.Metal3ClusterSpec)(nil), (*Metal3ClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(a.(*v1alpha4.Metal3ClusterSpec), b.(*Metal3ClusterSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha4.Metal3ClusterStatus)(nil), (*Metal3ClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(a.(*v1alpha4.Metal3ClusterStatus), b.(*Metal3ClusterStatus), scope)
 	}); err != nil {
 		return err
 	}
@@ -200,6 +195,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha4.Metal3MachineTemplateSpec)(nil), (*Metal3MachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(a.(*v1alpha4.Metal3MachineTemplateSpec), b.(*Metal3MachineTemplateSpec), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -326,7 +326,17 @@ func Convert_v1alpha4_Metal3Cluster_To_v1alpha3_Metal3Cluster(in *v1alpha4.Metal
 
 func autoConvert_v1alpha3_Metal3ClusterList_To_v1alpha4_Metal3ClusterList(in *Metal3ClusterList, out *v1alpha4.Metal3ClusterList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1alpha4.Metal3Cluster)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha4.Metal3Cluster, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_Metal3Cluster_To_v1alpha4_Metal3Cluster(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -337,7 +347,17 @@ func Convert_v1alpha3_Metal3ClusterList_To_v1alpha4_Metal3ClusterList(in *Metal3
 
 func autoConvert_v1alpha4_Metal3ClusterList_To_v1alpha3_Metal3ClusterList(in *v1alpha4.Metal3ClusterList, out *Metal3ClusterList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]Metal3Cluster)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Metal3Cluster, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_Metal3Cluster_To_v1alpha3_Metal3Cluster(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -363,15 +383,21 @@ func autoConvert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(in *v1
 	if err := Convert_v1alpha4_APIEndpoint_To_v1alpha3_APIEndpoint(&in.ControlPlaneEndpoint, &out.ControlPlaneEndpoint, s); err != nil {
 		return err
 	}
+	// WARNING: in.ControlPlaneEndpointFromIPPool requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneVIP requires manual conversion: does not exist in peer-type
+	// WARNING: in.LoadBalancerBackends requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointHealthCheck requires manual conversion: does not exist in peer-type
 	out.NoCloudProvider = in.NoCloudProvider
+	// WARNING: in.SetProviderIDOnNodes requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomainLabelKey requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeLabelPrefixes requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeAnnotationPrefixes requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProviderIDFormat requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeDeletion requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec is an autogenerated conversion function.
-func Convert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(in *v1alpha4.Metal3ClusterSpec, out *Metal3ClusterSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha4_Metal3ClusterSpec_To_v1alpha3_Metal3ClusterSpec(in, out, s)
-}
-
 func autoConvert_v1alpha3_Metal3ClusterStatus_To_v1alpha4_Metal3ClusterStatus(in *Metal3ClusterStatus, out *v1alpha4.Metal3ClusterStatus, s conversion.Scope) error {
 	out.LastUpdated = (*v1.Time)(unsafe.Pointer(in.LastUpdated))
 	out.FailureReason = (*errors.ClusterStatusError)(unsafe.Pointer(in.FailureReason))
@@ -390,14 +416,12 @@ func autoConvert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(in
 	out.FailureReason = (*errors.ClusterStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Ready = in.Ready
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointLastHealthy requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus is an autogenerated conversion function.
-func Convert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(in *v1alpha4.Metal3ClusterStatus, out *Metal3ClusterStatus, s conversion.Scope) error {
-	return autoConvert_v1alpha4_Metal3ClusterStatus_To_v1alpha3_Metal3ClusterStatus(in, out, s)
-}
-
 func autoConvert_v1alpha3_Metal3Machine_To_v1alpha4_Metal3Machine(in *Metal3Machine, out *v1alpha4.Metal3Machine, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha3_Metal3MachineSpec_To_v1alpha4_Metal3MachineSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -498,6 +522,12 @@ func autoConvert_v1alpha4_Metal3MachineSpec_To_v1alpha3_Metal3MachineSpec(in *v1
 	if err := Convert_v1alpha4_HostSelector_To_v1alpha3_HostSelector(&in.HostSelector, &out.HostSelector, s); err != nil {
 		return err
 	}
+	// WARNING: in.HostScoring requires manual conversion: does not exist in peer-type
+	// WARNING: in.HardwareRequirements requires manual conversion: does not exist in peer-type
+	// WARNING: in.AntiAffinity requires manual conversion: does not exist in peer-type
+	// WARNING: in.InPlaceUpgrade requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeLabels requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeTaints requires manual conversion: does not exist in peer-type
 	// WARNING: in.DataTemplate requires manual conversion: does not exist in peer-type
 	// WARNING: in.MetaData requires manual conversion: does not exist in peer-type
 	// WARNING: in.NetworkData requires manual conversion: does not exist in peer-type
@@ -530,6 +560,9 @@ func autoConvert_v1alpha4_Metal3MachineStatus_To_v1alpha3_Metal3MachineStatus(in
 	// WARNING: in.RenderedData requires manual conversion: does not exist in peer-type
 	// WARNING: in.MetaData requires manual conversion: does not exist in peer-type
 	// WARNING: in.NetworkData requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageUpgrade requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostSelection requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -641,10 +674,6 @@ func autoConvert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTem
 	if err := Convert_v1alpha4_Metal3MachineTemplateResource_To_v1alpha3_Metal3MachineTemplateResource(&in.Template, &out.Template, s); err != nil {
 		return err
 	}
+	// WARNING: in.NodeReuse requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec is an autogenerated conversion function.
-func Convert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(in *v1alpha4.Metal3MachineTemplateSpec, out *Metal3MachineTemplateSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha4_Metal3MachineTemplateSpec_To_v1alpha3_Metal3MachineTemplateSpec(in, out, s)
-}
@@ -23,6 +23,11 @@ import (
 const (
 	// UnhealthyAnnotation is the annotation that sets unhealthy status of BMH
 	UnhealthyAnnotation = "capi.metal3.io/unhealthy"
+
+	// RebootAnnotation is the annotation requesting the baremetal-operator to
+	// power cycle the BMH. The baremetal-operator removes it once the host is
+	// powered on again.
+	RebootAnnotation = "reboot.metal3.io"
 )
 
 // APIEndpoint represents a reachable Kubernetes API endpoint.
@@ -51,6 +56,120 @@ type HostSelectorRequirement struct {
 	Values   []string           `json:"values"`
 }
 
+// HostScoringPolicy is the strategy used to rank the BareMetalHosts matching
+// the HostSelector.
+type HostScoringPolicy string
+
+const (
+	// HostScoringRandom picks one of the matching hosts at random.
+	HostScoringRandom HostScoringPolicy = "Random"
+
+	// HostScoringLeastCapableFit prefers the matching host with the least
+	// resources (RAM, then CPU count, then storage), keeping the bigger hosts
+	// for more demanding roles.
+	HostScoringLeastCapableFit HostScoringPolicy = "LeastCapableFit"
+
+	// HostScoringMostCapableFit prefers the matching host with the most
+	// resources (RAM, then CPU count, then storage).
+	HostScoringMostCapableFit HostScoringPolicy = "MostCapableFit"
+
+	// HostScoringPackByLabel prefers the matching hosts whose label value is
+	// shared by the largest number of already consumed hosts.
+	HostScoringPackByLabel HostScoringPolicy = "PackByLabel"
+
+	// HostScoringSpreadByLabel prefers the matching hosts whose label value is
+	// shared by the smallest number of already consumed hosts.
+	HostScoringSpreadByLabel HostScoringPolicy = "SpreadByLabel"
+)
+
+// HostScoring specifies how to choose a BareMetalHost among the ones matching
+// the HostSelector. Hosts with the same score are picked at random.
+type HostScoring struct {
+	// Policy is the scoring policy to apply.
+	// +kubebuilder:validation:Enum=Random;LeastCapableFit;MostCapableFit;PackByLabel;SpreadByLabel
+	Policy HostScoringPolicy `json:"policy"`
+
+	// LabelKey is the key of the BareMetalHost label used to group the hosts.
+	// It is required by the PackByLabel and SpreadByLabel policies.
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+}
+
+// HardwareRequirements specifies the minimal hardware a BareMetalHost must
+// have to be claimed. The requirements are evaluated against the hardware
+// details found during inspection, so hosts that were not inspected never
+// match. Unset fields are not checked.
+type HardwareRequirements struct {
+	// MinCPUCount is the minimal number of CPUs of the host.
+	// +optional
+	MinCPUCount int `json:"minCPUCount,omitempty"`
+
+	// MinRAMMebibytes is the minimal amount of RAM of the host, in MiB.
+	// +optional
+	MinRAMMebibytes int `json:"minRAMMebibytes,omitempty"`
+
+	// MinRootDiskGibibytes is the minimal size of the root disk of the host,
+	// in GiB. The root disk is the one named in the root device hints of the
+	// host if any, the smallest disk of at least 4GiB otherwise.
+	// +optional
+	MinRootDiskGibibytes int `json:"minRootDiskGibibytes,omitempty"`
+
+	// MinNICCount is the minimal number of NICs of the host. If MinNICSpeedGbps
+	// is set, only the NICs at least that fast are counted.
+	// +optional
+	MinNICCount int `json:"minNICCount,omitempty"`
+
+	// MinNICSpeedGbps is the minimal speed of the NICs of the host, in Gbps.
+	// If MinNICCount is unset, at least one NIC must be that fast.
+	// +optional
+	MinNICSpeedGbps int `json:"minNICSpeedGbps,omitempty"`
+
+	// CPUArchitecture is the architecture of the CPUs of the host, as reported
+	// by the inspection, e.g. x86_64 or aarch64.
+	// +optional
+	CPUArchitecture string `json:"cpuArchitecture,omitempty"`
+}
+
+// HostAntiAffinity specifies which BareMetalHosts to avoid because a sibling
+// Metal3Machine already consumes a host in the same topology domain.
+type HostAntiAffinity struct {
+	// Required lists the terms a BareMetalHost must not violate to be
+	// claimed.
+	// +optional
+	Required []HostAntiAffinityTerm `json:"required,omitempty"`
+
+	// Preferred lists the terms a BareMetalHost should not violate. The hosts
+	// with the lowest sum of the weights of the violated terms are preferred.
+	// +optional
+	Preferred []WeightedHostAntiAffinityTerm `json:"preferred,omitempty"`
+}
+
+// HostAntiAffinityTerm defines a set of sibling Metal3Machines and a
+// BareMetalHost label. A host violates the term if its value for the label is
+// the same as the one of a host consumed by a sibling.
+type HostAntiAffinityTerm struct {
+	// TopologyKey is the key of the BareMetalHost label defining the topology
+	// domain, e.g. topology.metal3.io/chassis. Hosts without this label never
+	// violate the term.
+	TopologyKey string `json:"topologyKey"`
+
+	// MachineLabelKeys are the keys of the Machine labels defining the
+	// siblings: the Metal3Machines whose Machine has the same values for all
+	// these labels as the Machine of this Metal3Machine, e.g.
+	// cluster.x-k8s.io/cluster-name and cluster.x-k8s.io/control-plane.
+	// +kubebuilder:validation:MinItems=1
+	MachineLabelKeys []string `json:"machineLabelKeys"`
+}
+
+// WeightedHostAntiAffinityTerm is a HostAntiAffinityTerm with a weight.
+type WeightedHostAntiAffinityTerm struct {
+	// Weight of the term, in the range 1-100.
+	Weight int32 `json:"weight"`
+
+	// Term is the anti-affinity term.
+	Term HostAntiAffinityTerm `json:"term"`
+}
+
 // Image holds the details of an image to use during provisioning.
 type Image struct {
 	// URL is a location of an image to deploy.
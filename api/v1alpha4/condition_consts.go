@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+// Conditions and condition Reasons for the Metal3Machine object
+
+const (
+	// AssociateBMHCondition documents the association of the Metal3Machine
+	// with a BareMetalHost.
+	AssociateBMHCondition capi.ConditionType = "AssociateBMH"
+
+	// WaitingForClusterInfrastructureReason is used when the Metal3Machine is
+	// waiting for the cluster infrastructure to be ready.
+	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
+	// NoHostAvailableReason is used when no BareMetalHost matches the
+	// Metal3Machine.
+	NoHostAvailableReason = "NoHostAvailable"
+	// AssociateBMHFailedReason is used when the association of the
+	// Metal3Machine with a BareMetalHost failed.
+	AssociateBMHFailedReason = "AssociateBMHFailed"
+
+	// Metal3DataReadyCondition documents the rendering of the Metal3Data of the
+	// Metal3Machine, if it has a data template.
+	Metal3DataReadyCondition capi.ConditionType = "Metal3DataReady"
+
+	// WaitingForMetal3DataReason is used when the Metal3Machine is waiting for
+	// its Metal3Data to be rendered.
+	WaitingForMetal3DataReason = "WaitingForMetal3Data"
+	// AssociateM3MetaDataFailedReason is used when the Metal3Data of the
+	// Metal3Machine could not be fetched.
+	AssociateM3MetaDataFailedReason = "AssociateM3MetaDataFailed"
+
+	// HostProvisionedCondition documents the provisioning of the BareMetalHost
+	// associated with the Metal3Machine.
+	HostProvisionedCondition capi.ConditionType = "HostProvisioned"
+
+	// MissingBMHReason is used when the BareMetalHost associated with the
+	// Metal3Machine is not found.
+	MissingBMHReason = "MissingBMH"
+	// WaitingForHostProvisioningReason is used when the BareMetalHost is not
+	// provisioned yet.
+	WaitingForHostProvisioningReason = "WaitingForHostProvisioning"
+	// UpdateBMHFailedReason is used when the BareMetalHost could not be
+	// updated.
+	UpdateBMHFailedReason = "UpdateBMHFailed"
+
+	// KubernetesNodeReadyCondition documents the transition of the
+	// Metal3Machine into a Kubernetes Node.
+	KubernetesNodeReadyCondition capi.ConditionType = "KubernetesNodeReady"
+
+	// WaitingForNodeReason is used when the Node of the Metal3Machine is not
+	// found in the target cluster.
+	WaitingForNodeReason = "WaitingForNode"
+	// SettingProviderIDOnNodeFailedReason is used when the provider ID could
+	// not be set on the Node in the target cluster.
+	SettingProviderIDOnNodeFailedReason = "SettingProviderIDOnNodeFailed"
+	// NodeNotFoundReason is used when the Node of a provisioned Metal3Machine
+	// was deleted from the target cluster.
+	NodeNotFoundReason = "NodeNotFound"
+)
+
+// Conditions and condition Reasons for the Metal3Cluster object
+
+const (
+	// BaremetalInfrastructureReadyCondition documents the readiness of the
+	// cluster infrastructure.
+	BaremetalInfrastructureReadyCondition capi.ConditionType = "BaremetalInfrastructureReady"
+
+	// InvalidConfigurationReason is used when the Metal3Cluster spec is not
+	// valid.
+	InvalidConfigurationReason = "InvalidConfiguration"
+	// ControlPlaneEndpointFailedReason is used when the control plane endpoint
+	// is not set.
+	ControlPlaneEndpointFailedReason = "ControlPlaneEndpointFailed"
+	// WaitingForControlPlaneEndpointReason is used while the host of the
+	// control plane endpoint is being allocated from an IPPool.
+	WaitingForControlPlaneEndpointReason = "WaitingForControlPlaneEndpoint"
+	// DeletionBlockedReason is used while the deletion of the Metal3Cluster
+	// waits for the deletion of its Machines or of the resources left behind
+	// by them.
+	DeletionBlockedReason = "DeletionBlocked"
+
+	// ControlPlaneEndpointHealthyCondition documents the probing of the
+	// control plane endpoint of the Metal3Cluster.
+	ControlPlaneEndpointHealthyCondition capi.ConditionType = "ControlPlaneEndpointHealthy"
+
+	// WaitingForControlPlaneInitializedReason is used while the control
+	// plane of the cluster is not initialized, and the endpoint not probed.
+	WaitingForControlPlaneInitializedReason = "WaitingForControlPlaneInitialized"
+	// ControlPlaneEndpointUnreachableReason is used when the probe of the
+	// control plane endpoint failed.
+	ControlPlaneEndpointUnreachableReason = "ControlPlaneEndpointUnreachable"
+)
+
+// Conditions and condition Reasons for the Metal3Data object
+
+const (
+	// SecretsReadyCondition documents the rendering of the metadata and
+	// network data secrets.
+	SecretsReadyCondition capi.ConditionType = "SecretsReady"
+
+	// WaitingForSecretsRenderingReason is used when the secrets can not be
+	// rendered yet, for example while waiting for an IP address.
+	WaitingForSecretsRenderingReason = "WaitingForSecretsRendering"
+	// Metal3DataSecretsErrorReason is used when the secrets could not be
+	// rendered.
+	Metal3DataSecretsErrorReason = "Metal3DataSecretsError"
+)
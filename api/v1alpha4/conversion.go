@@ -25,3 +25,5 @@ func (*Metal3MachineTemplateList) Hub() {}
 func (*Metal3DataTemplate) Hub()        {}
 func (*Metal3Data) Hub()                {}
 func (*Metal3DataClaim) Hub()           {}
+func (*Metal3Remediation) Hub()         {}
+func (*Metal3RemediationTemplate) Hub() {}
@@ -18,8 +18,10 @@ package v1alpha4
 
 import (
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
@@ -29,23 +31,268 @@ const (
 	ClusterFinalizer = "metal3cluster.infrastructure.cluster.x-k8s.io"
 )
 
+// ProviderIDFormat defines the format of the providerID of the Metal3Machines.
+type ProviderIDFormat string
+
+const (
+	// ProviderIDFormatUID is the metal3://<bmh-uid> format. The UID of a
+	// BareMetalHost changes when it is moved to another cluster.
+	ProviderIDFormatUID ProviderIDFormat = "UID"
+
+	// ProviderIDFormatName is the
+	// metal3://<namespace>/<bmh-name>/<metal3machine-name> format, that is
+	// kept when the objects are moved to another cluster.
+	ProviderIDFormatName ProviderIDFormat = "Name"
+)
+
+// NodeDeletionAction defines what is done when the Node of a provisioned
+// Metal3Machine is deleted from the workload cluster.
+type NodeDeletionAction string
+
+const (
+	// NodeDeletionActionNone only reports the deletion in the
+	// KubernetesNodeReady condition of the Metal3Machine.
+	NodeDeletionActionNone NodeDeletionAction = "None"
+
+	// NodeDeletionActionReboot reboots the BareMetalHost once, so that its
+	// kubelet registers the Node again.
+	NodeDeletionActionReboot NodeDeletionAction = "Reboot"
+
+	// NodeDeletionActionReplace deletes the Machine, so that it is replaced
+	// by its owner.
+	NodeDeletionActionReplace NodeDeletionAction = "Replace"
+
+	// DefaultNodeDeletionGracePeriod is the default time the Node can be
+	// missing before the action is taken.
+	DefaultNodeDeletionGracePeriod = 5 * time.Minute
+)
+
+// NodeDeletionPolicy defines how the deletion of the Node of a provisioned
+// Metal3Machine is handled.
+type NodeDeletionPolicy struct {
+	// Action is taken once the Node has been missing for the GracePeriod.
+	// +kubebuilder:validation:Enum=None;Reboot;Replace
+	// +optional
+	Action NodeDeletionAction `json:"action,omitempty"`
+
+	// GracePeriod is the time the Node can be missing before the Action is
+	// taken, 5 minutes by default.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// ControlPlaneVIPMode defines the static pod announcing the control plane
+// endpoint VIP.
+type ControlPlaneVIPMode string
+
+const (
+	// ControlPlaneVIPModeKubeVIP announces the VIP with kube-vip, using ARP
+	// and a leader election among the control plane nodes.
+	ControlPlaneVIPModeKubeVIP ControlPlaneVIPMode = "kube-vip"
+
+	// ControlPlaneVIPModeKeepalived announces the VIP with keepalived, using
+	// VRRP.
+	ControlPlaneVIPModeKeepalived ControlPlaneVIPMode = "keepalived"
+)
+
+// ControlPlaneVIP defines the static pod announcing the host of the
+// ControlPlaneEndpoint, that must be an IP address, from the control plane
+// nodes.
+type ControlPlaneVIP struct {
+	// Mode is the static pod announcing the VIP, kube-vip or keepalived.
+	// +kubebuilder:validation:Enum=kube-vip;keepalived
+	Mode ControlPlaneVIPMode `json:"mode"`
+
+	// Interface is the name of the network interface of the control plane
+	// nodes the VIP is announced on.
+	Interface string `json:"interface"`
+
+	// VirtualRouterID is the VRRP virtual router id of the VIP, unique in the
+	// network. It is required with keepalived.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=255
+	// +optional
+	VirtualRouterID int `json:"virtualRouterID,omitempty"`
+
+	// Image is the image of the static pod, defaulting to the image of the
+	// mode.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// LoadBalancerBackendsFormat defines the format of the control plane backends
+// rendered for an external load balancer.
+type LoadBalancerBackendsFormat string
+
+const (
+	// LoadBalancerBackendsFormatJSON renders the backends as a JSON list under
+	// the backends.json key.
+	LoadBalancerBackendsFormatJSON LoadBalancerBackendsFormat = "JSON"
+
+	// LoadBalancerBackendsFormatHAProxy renders the backends as an HAProxy
+	// backend section under the haproxy.cfg key.
+	LoadBalancerBackendsFormatHAProxy LoadBalancerBackendsFormat = "HAProxy"
+
+	// DefaultLoadBalancerBackendPort is the default port of the API server of
+	// the control plane backends.
+	DefaultLoadBalancerBackendPort = 6443
+)
+
+// LoadBalancerBackends defines the ConfigMap listing the addresses of the
+// control plane machines, for an external load balancer.
+type LoadBalancerBackends struct {
+	// Format of the backends, JSON or HAProxy.
+	// +kubebuilder:validation:Enum=JSON;HAProxy
+	Format LoadBalancerBackendsFormat `json:"format"`
+
+	// AddressType is the type of the addresses of the Metal3Machines used as
+	// backends, InternalIP by default.
+	// +kubebuilder:validation:Enum=Hostname;ExternalIP;InternalIP;ExternalDNS;InternalDNS
+	// +optional
+	AddressType capi.MachineAddressType `json:"addressType,omitempty"`
+
+	// Port is the port of the API server of the backends, 6443 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int `json:"port,omitempty"`
+}
+
+// ControlPlaneEndpointHealthCheckMode defines how the control plane endpoint
+// is probed.
+type ControlPlaneEndpointHealthCheckMode string
+
+const (
+	// ControlPlaneEndpointHealthCheckModeTCP opens a TCP connection to the
+	// control plane endpoint.
+	ControlPlaneEndpointHealthCheckModeTCP ControlPlaneEndpointHealthCheckMode = "TCP"
+
+	// ControlPlaneEndpointHealthCheckModeHTTPS gets the /healthz path of the
+	// control plane endpoint, verifying its certificate with the CA of the
+	// cluster.
+	ControlPlaneEndpointHealthCheckModeHTTPS ControlPlaneEndpointHealthCheckMode = "HTTPS"
+
+	// DefaultControlPlaneEndpointHealthCheckPeriod is the default time
+	// between two probes of the control plane endpoint.
+	DefaultControlPlaneEndpointHealthCheckPeriod = time.Minute
+
+	// DefaultControlPlaneEndpointHealthCheckTimeout is the default timeout of
+	// a probe of the control plane endpoint.
+	DefaultControlPlaneEndpointHealthCheckTimeout = 5 * time.Second
+)
+
+// ControlPlaneEndpointHealthCheck defines the probing of the control plane
+// endpoint once the control plane is initialized.
+type ControlPlaneEndpointHealthCheck struct {
+	// Mode of the probe, TCP or HTTPS.
+	// +kubebuilder:validation:Enum=TCP;HTTPS
+	Mode ControlPlaneEndpointHealthCheckMode `json:"mode"`
+
+	// Period is the time between two probes, 1 minute by default.
+	// +optional
+	Period *metav1.Duration `json:"period,omitempty"`
+
+	// Timeout is the timeout of a probe, 5 seconds by default.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
 // Metal3ClusterSpec defines the desired state of Metal3Cluster.
 type Metal3ClusterSpec struct {
 	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
-	ControlPlaneEndpoint APIEndpoint `json:"controlPlaneEndpoint"`
-	NoCloudProvider      bool        `json:"noCloudProvider,omitempty"`
+	// The host is allocated from the ControlPlaneEndpointFromIPPool if it is
+	// not set.
+	// +optional
+	ControlPlaneEndpoint APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// ControlPlaneEndpointFromIPPool is the name of the IPPool, in the
+	// namespace of the Metal3Cluster, the host of the ControlPlaneEndpoint is
+	// allocated from. The IPClaim of the address is released when the
+	// Metal3Cluster is deleted.
+	// +optional
+	ControlPlaneEndpointFromIPPool *string `json:"controlPlaneEndpointFromIPPool,omitempty"`
+
+	// ControlPlaneVIP defines the static pod announcing the host of the
+	// ControlPlaneEndpoint from the control plane nodes. Its manifest is
+	// rendered in the metadata of the control plane Metal3Datas.
+	// +optional
+	ControlPlaneVIP *ControlPlaneVIP `json:"controlPlaneVIP,omitempty"`
+
+	// LoadBalancerBackends defines the ConfigMap listing the addresses of the
+	// control plane machines, kept in sync for an external load balancer. The
+	// ConfigMap is named after the Metal3Cluster, with a -lb-backends suffix.
+	// +optional
+	LoadBalancerBackends *LoadBalancerBackends `json:"loadBalancerBackends,omitempty"`
+
+	// ControlPlaneEndpointHealthCheck defines the probing of the
+	// ControlPlaneEndpoint once the control plane is initialized, reported in
+	// the ControlPlaneEndpointHealthy condition. The endpoint is not probed by
+	// default.
+	// +optional
+	ControlPlaneEndpointHealthCheck *ControlPlaneEndpointHealthCheck `json:"controlPlaneEndpointHealthCheck,omitempty"`
+
+	NoCloudProvider bool `json:"noCloudProvider,omitempty"`
+
+	// SetProviderIDOnNodes sets the providerID on the Nodes of the workload
+	// cluster that have none when a cloud provider is deployed, as the kubelet
+	// leaves it empty with --cloud-provider=external. The providerIDs already
+	// set are kept. It has no effect with NoCloudProvider, the providerID is
+	// set on all the Nodes then.
+	// +optional
+	SetProviderIDOnNodes bool `json:"setProviderIDOnNodes,omitempty"`
+
+	// FailureDomainLabelKey is the key of the BareMetalHost label holding the
+	// failure domain of the host, e.g. topology.metal3.io/rack. It is required
+	// if FailureDomains is set.
+	// +optional
+	FailureDomainLabelKey string `json:"failureDomainLabelKey,omitempty"`
+
+	// FailureDomains is the list of failure domains of the cluster, keyed by
+	// the value of the FailureDomainLabelKey label of the BareMetalHosts. A
+	// Machine with a failure domain is only given a host from that domain.
+	// +optional
+	FailureDomains capi.FailureDomains `json:"failureDomains,omitempty"`
+
+	// NodeLabelPrefixes is the allow-list of the prefixes of the labels copied
+	// to the Nodes of the workload cluster, e.g. topology.metal3.io/. The
+	// labels of a BareMetalHost and of its Metal3Machine with one of these
+	// prefixes are set on its Node and kept in sync, the labels of the
+	// Metal3Machine taking precedence.
+	// +optional
+	NodeLabelPrefixes []string `json:"nodeLabelPrefixes,omitempty"`
+
+	// NodeAnnotationPrefixes is the allow-list of the prefixes of the
+	// annotations copied to the Nodes of the workload cluster. The annotations
+	// of a BareMetalHost and of its Metal3Machine with one of these prefixes
+	// are set on its Node and kept in sync, the annotations of the
+	// Metal3Machine taking precedence.
+	// +optional
+	NodeAnnotationPrefixes []string `json:"nodeAnnotationPrefixes,omitempty"`
+
+	// ProviderIDFormat is the format of the providerID given to the new
+	// Metal3Machines, UID by default. The existing providerIDs are kept.
+	// +kubebuilder:validation:Enum=UID;Name
+	// +optional
+	ProviderIDFormat ProviderIDFormat `json:"providerIDFormat,omitempty"`
+
+	// NodeDeletion defines how the deletion of the Node of a provisioned
+	// Metal3Machine is handled, when deploying without cloud provider. The
+	// deletion is only reported in the KubernetesNodeReady condition of the
+	// Metal3Machine by default.
+	// +optional
+	NodeDeletion *NodeDeletionPolicy `json:"nodeDeletion,omitempty"`
 }
 
 // IsValid returns an error if the object is not valid, otherwise nil. The
 // string representation of the error is suitable for human consumption.
 func (s *Metal3ClusterSpec) IsValid() error {
 	missing := []string{}
-	if s.ControlPlaneEndpoint.Host == "" {
+	if s.ControlPlaneEndpoint.Host == "" && s.ControlPlaneEndpointFromIPPool == nil {
 		missing = append(missing, "ControlPlaneEndpoint.Host")
 	}
 
 	if s.ControlPlaneEndpoint.Port == 0 {
-		missing = append(missing, "ControlPlaneEndpoint.Host")
+		missing = append(missing, "ControlPlaneEndpoint.Port")
 	}
 
 	if len(missing) > 0 {
@@ -76,6 +323,20 @@ type Metal3ClusterStatus struct {
 	// steps need to be performed. Required by Cluster API. Set to True by the
 	// metal3Cluster controller after creation.
 	Ready bool `json:"ready"`
+
+	// FailureDomains is the list of failure domains of the cluster, copied
+	// from the spec for Cluster API to consume.
+	// +optional
+	FailureDomains capi.FailureDomains `json:"failureDomains,omitempty"`
+
+	// ControlPlaneEndpointLastHealthy is the time of the last successful
+	// probe of the control plane endpoint.
+	// +optional
+	ControlPlaneEndpointLastHealthy *metav1.Time `json:"controlPlaneEndpointLastHealthy,omitempty"`
+
+	// Conditions defines current service state of the Metal3Cluster.
+	// +optional
+	Conditions capi.Conditions `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -97,6 +358,16 @@ type Metal3Cluster struct {
 	Status Metal3ClusterStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the list of conditions for a Metal3Cluster.
+func (c *Metal3Cluster) GetConditions() capi.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on a Metal3Cluster.
+func (c *Metal3Cluster) SetConditions(conditions capi.Conditions) {
+	c.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // Metal3ClusterList contains a list of Metal3Cluster
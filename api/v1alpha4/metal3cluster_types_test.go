@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -24,6 +25,7 @@ func TestClusterSpecIsValid(t *testing.T) {
 	cases := []struct {
 		Spec          Metal3ClusterSpec
 		ErrorExpected bool
+		ErrorMissing  []string
 		Name          string
 	}{
 		{
@@ -49,6 +51,7 @@ func TestClusterSpecIsValid(t *testing.T) {
 				},
 			},
 			ErrorExpected: true,
+			ErrorMissing:  []string{"ControlPlaneEndpoint.Host", "ControlPlaneEndpoint.Port"},
 			Name:          "Incorrect spec, no host and port",
 		},
 		{
@@ -59,6 +62,7 @@ func TestClusterSpecIsValid(t *testing.T) {
 				},
 			},
 			ErrorExpected: true,
+			ErrorMissing:  []string{"ControlPlaneEndpoint.Host"},
 			Name:          "Incorrect spec, no host",
 		},
 		{
@@ -69,6 +73,7 @@ func TestClusterSpecIsValid(t *testing.T) {
 				},
 			},
 			ErrorExpected: true,
+			ErrorMissing:  []string{"ControlPlaneEndpoint.Port"},
 			Name:          "Incorrect spec, no port",
 		},
 	}
@@ -81,5 +86,12 @@ func TestClusterSpecIsValid(t *testing.T) {
 		if !tc.ErrorExpected && err != nil {
 			t.Errorf("Got unexpected error from case \"%v\": %v", tc.Name, err)
 		}
+		for _, missing := range tc.ErrorMissing {
+			if err == nil || !strings.Contains(err.Error(), missing) {
+				t.Errorf("Error from case \"%v\" does not report %v: %v",
+					tc.Name, missing, err,
+				)
+			}
+		}
 	}
 }
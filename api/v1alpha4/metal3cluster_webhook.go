@@ -14,6 +14,8 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"net"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -56,7 +58,8 @@ func (c *Metal3Cluster) ValidateDelete() error {
 
 func (c *Metal3Cluster) validate() error {
 	var allErrs field.ErrorList
-	if len(c.Spec.ControlPlaneEndpoint.Host) == 0 {
+	if len(c.Spec.ControlPlaneEndpoint.Host) == 0 &&
+		c.Spec.ControlPlaneEndpointFromIPPool == nil {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
@@ -68,8 +71,203 @@ func (c *Metal3Cluster) validate() error {
 
 	}
 
+	if c.Spec.ControlPlaneEndpointFromIPPool != nil &&
+		*c.Spec.ControlPlaneEndpointFromIPPool == "" {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "controlPlaneEndpointFromIPPool"),
+				*c.Spec.ControlPlaneEndpointFromIPPool,
+				"must not be empty",
+			),
+		)
+	}
+
+	if c.Spec.ControlPlaneVIP != nil {
+		allErrs = append(allErrs, c.validateControlPlaneVIP()...)
+	}
+
+	if backends := c.Spec.LoadBalancerBackends; backends != nil {
+		backendsPath := field.NewPath("spec", "loadBalancerBackends")
+		switch backends.Format {
+		case LoadBalancerBackendsFormatJSON, LoadBalancerBackendsFormatHAProxy:
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(backendsPath.Child("format"),
+					backends.Format, []string{
+						string(LoadBalancerBackendsFormatJSON),
+						string(LoadBalancerBackendsFormatHAProxy),
+					},
+				),
+			)
+		}
+		if backends.Port < 0 || backends.Port > 65535 {
+			allErrs = append(allErrs,
+				field.Invalid(backendsPath.Child("port"), backends.Port,
+					"must be between 1 and 65535",
+				),
+			)
+		}
+	}
+
+	if healthCheck := c.Spec.ControlPlaneEndpointHealthCheck; healthCheck != nil {
+		healthCheckPath := field.NewPath("spec", "controlPlaneEndpointHealthCheck")
+		switch healthCheck.Mode {
+		case ControlPlaneEndpointHealthCheckModeTCP,
+			ControlPlaneEndpointHealthCheckModeHTTPS:
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(healthCheckPath.Child("mode"),
+					healthCheck.Mode, []string{
+						string(ControlPlaneEndpointHealthCheckModeTCP),
+						string(ControlPlaneEndpointHealthCheckModeHTTPS),
+					},
+				),
+			)
+		}
+		if healthCheck.Period != nil && healthCheck.Period.Duration <= 0 {
+			allErrs = append(allErrs,
+				field.Invalid(healthCheckPath.Child("period"),
+					healthCheck.Period.Duration.String(), "must be positive",
+				),
+			)
+		}
+		if healthCheck.Timeout != nil && healthCheck.Timeout.Duration <= 0 {
+			allErrs = append(allErrs,
+				field.Invalid(healthCheckPath.Child("timeout"),
+					healthCheck.Timeout.Duration.String(), "must be positive",
+				),
+			)
+		}
+	}
+
+	if len(c.Spec.FailureDomains) > 0 && c.Spec.FailureDomainLabelKey == "" {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "failureDomainLabelKey"),
+				c.Spec.FailureDomainLabelKey,
+				"is required when failureDomains is set",
+			),
+		)
+	}
+
+	switch c.Spec.ProviderIDFormat {
+	case "", ProviderIDFormatUID, ProviderIDFormatName:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec", "providerIDFormat"),
+				c.Spec.ProviderIDFormat, []string{string(ProviderIDFormatUID),
+					string(ProviderIDFormatName),
+				},
+			),
+		)
+	}
+
+	if c.Spec.NodeDeletion != nil {
+		switch c.Spec.NodeDeletion.Action {
+		case "", NodeDeletionActionNone, NodeDeletionActionReboot,
+			NodeDeletionActionReplace:
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(field.NewPath("spec", "nodeDeletion", "action"),
+					c.Spec.NodeDeletion.Action, []string{
+						string(NodeDeletionActionNone),
+						string(NodeDeletionActionReboot),
+						string(NodeDeletionActionReplace),
+					},
+				),
+			)
+		}
+		gracePeriod := c.Spec.NodeDeletion.GracePeriod
+		if gracePeriod != nil && gracePeriod.Duration < 0 {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec", "nodeDeletion", "gracePeriod"),
+					gracePeriod.Duration.String(), "must not be negative",
+				),
+			)
+		}
+	}
+
+	for i, prefix := range c.Spec.NodeLabelPrefixes {
+		if prefix == "" {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "nodeLabelPrefixes").Index(i),
+					prefix,
+					"must not be empty",
+				),
+			)
+		}
+	}
+
+	for i, prefix := range c.Spec.NodeAnnotationPrefixes {
+		if prefix == "" {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "nodeAnnotationPrefixes").Index(i),
+					prefix,
+					"must not be empty",
+				),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Cluster").GroupKind(), c.Name, allErrs)
 }
+
+// validateControlPlaneVIP validates the static pod announcing the control
+// plane endpoint VIP.
+func (c *Metal3Cluster) validateControlPlaneVIP() field.ErrorList {
+	var allErrs field.ErrorList
+	vip := c.Spec.ControlPlaneVIP
+	vipPath := field.NewPath("spec", "controlPlaneVIP")
+
+	switch vip.Mode {
+	case ControlPlaneVIPModeKubeVIP:
+	case ControlPlaneVIPModeKeepalived:
+		if vip.VirtualRouterID == 0 {
+			allErrs = append(allErrs,
+				field.Required(vipPath.Child("virtualRouterID"),
+					"is required with keepalived",
+				),
+			)
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(vipPath.Child("mode"), vip.Mode, []string{
+				string(ControlPlaneVIPModeKubeVIP),
+				string(ControlPlaneVIPModeKeepalived),
+			}),
+		)
+	}
+
+	if vip.Interface == "" {
+		allErrs = append(allErrs,
+			field.Required(vipPath.Child("interface"), "is required"),
+		)
+	}
+
+	if vip.VirtualRouterID < 0 || vip.VirtualRouterID > 255 {
+		allErrs = append(allErrs,
+			field.Invalid(vipPath.Child("virtualRouterID"),
+				vip.VirtualRouterID, "must be between 1 and 255",
+			),
+		)
+	}
+
+	host := c.Spec.ControlPlaneEndpoint.Host
+	if host != "" && net.ParseIP(host) == nil {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "controlPlaneEndpoint", "host"),
+				host, "must be an IP address when controlPlaneVIP is set",
+			),
+		)
+	}
+	return allErrs
+}
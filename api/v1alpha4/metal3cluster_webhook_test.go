@@ -15,9 +15,12 @@ package v1alpha4
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
 func TestMetal3ClusterDefault(t *testing.T) {
@@ -51,6 +54,86 @@ func TestMetal3ClusterValidation(t *testing.T) {
 	invalidHost := valid.DeepCopy()
 	invalidHost.Spec.ControlPlaneEndpoint.Host = ""
 
+	validHostFromIPPool := invalidHost.DeepCopy()
+	validHostFromIPPool.Spec.ControlPlaneEndpointFromIPPool = pointer.StringPtr("pool1")
+
+	invalidHostFromIPPool := invalidHost.DeepCopy()
+	invalidHostFromIPPool.Spec.ControlPlaneEndpointFromIPPool = pointer.StringPtr("")
+
+	validKubeVIP := valid.DeepCopy()
+	validKubeVIP.Spec.ControlPlaneEndpoint.Host = "192.168.111.249"
+	validKubeVIP.Spec.ControlPlaneVIP = &ControlPlaneVIP{
+		Mode:      ControlPlaneVIPModeKubeVIP,
+		Interface: "enp2s0",
+	}
+
+	validKeepalived := validKubeVIP.DeepCopy()
+	validKeepalived.Spec.ControlPlaneVIP.Mode = ControlPlaneVIPModeKeepalived
+	validKeepalived.Spec.ControlPlaneVIP.VirtualRouterID = 1
+
+	invalidKeepalived := validKeepalived.DeepCopy()
+	invalidKeepalived.Spec.ControlPlaneVIP.VirtualRouterID = 0
+	invalidKeepalived.Spec.ControlPlaneVIP.Interface = ""
+
+	invalidVIPHost := validKubeVIP.DeepCopy()
+	invalidVIPHost.Spec.ControlPlaneEndpoint.Host = "abc.com"
+
+	validLoadBalancerBackends := valid.DeepCopy()
+	validLoadBalancerBackends.Spec.LoadBalancerBackends = &LoadBalancerBackends{
+		Format: LoadBalancerBackendsFormatHAProxy,
+		Port:   6443,
+	}
+
+	invalidLoadBalancerBackends := valid.DeepCopy()
+	invalidLoadBalancerBackends.Spec.LoadBalancerBackends = &LoadBalancerBackends{
+		Format: "YAML",
+		Port:   70000,
+	}
+
+	validHealthCheck := valid.DeepCopy()
+	validHealthCheck.Spec.ControlPlaneEndpointHealthCheck = &ControlPlaneEndpointHealthCheck{
+		Mode:   ControlPlaneEndpointHealthCheckModeHTTPS,
+		Period: &metav1.Duration{Duration: 30 * time.Second},
+	}
+
+	invalidHealthCheck := valid.DeepCopy()
+	invalidHealthCheck.Spec.ControlPlaneEndpointHealthCheck = &ControlPlaneEndpointHealthCheck{
+		Mode:    "HTTP",
+		Timeout: &metav1.Duration{},
+	}
+
+	invalidFailureDomains := valid.DeepCopy()
+	invalidFailureDomains.Spec.FailureDomains = capi.FailureDomains{
+		"rack-1": capi.FailureDomainSpec{ControlPlane: true},
+	}
+
+	validFailureDomains := invalidFailureDomains.DeepCopy()
+	validFailureDomains.Spec.FailureDomainLabelKey = "topology.metal3.io/rack"
+
+	invalidNodeLabelPrefixes := valid.DeepCopy()
+	invalidNodeLabelPrefixes.Spec.NodeLabelPrefixes = []string{"topology.metal3.io/", ""}
+
+	invalidNodeAnnotationPrefixes := valid.DeepCopy()
+	invalidNodeAnnotationPrefixes.Spec.NodeAnnotationPrefixes = []string{""}
+
+	invalidProviderIDFormat := valid.DeepCopy()
+	invalidProviderIDFormat.Spec.ProviderIDFormat = "Serial"
+
+	validProviderIDFormat := valid.DeepCopy()
+	validProviderIDFormat.Spec.ProviderIDFormat = ProviderIDFormatName
+
+	invalidNodeDeletion := valid.DeepCopy()
+	invalidNodeDeletion.Spec.NodeDeletion = &NodeDeletionPolicy{
+		Action:      "Reprovision",
+		GracePeriod: &metav1.Duration{Duration: -time.Minute},
+	}
+
+	validNodeDeletion := valid.DeepCopy()
+	validNodeDeletion.Spec.NodeDeletion = &NodeDeletionPolicy{
+		Action:      NodeDeletionActionReboot,
+		GracePeriod: &metav1.Duration{Duration: time.Minute},
+	}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -61,6 +144,96 @@ func TestMetal3ClusterValidation(t *testing.T) {
 			expectErr: true,
 			c:         invalidHost,
 		},
+		{
+			name:      "should succeed when endpoint is allocated from an IPPool",
+			expectErr: false,
+			c:         validHostFromIPPool,
+		},
+		{
+			name:      "should return error when the IPPool of the endpoint is empty",
+			expectErr: true,
+			c:         invalidHostFromIPPool,
+		},
+		{
+			name:      "should succeed when the kube-vip VIP is valid",
+			expectErr: false,
+			c:         validKubeVIP,
+		},
+		{
+			name:      "should succeed when the keepalived VIP is valid",
+			expectErr: false,
+			c:         validKeepalived,
+		},
+		{
+			name:      "should return error when the keepalived VIP has no router id",
+			expectErr: true,
+			c:         invalidKeepalived,
+		},
+		{
+			name:      "should return error when the VIP is not an IP address",
+			expectErr: true,
+			c:         invalidVIPHost,
+		},
+		{
+			name:      "should succeed when the load balancer backends are valid",
+			expectErr: false,
+			c:         validLoadBalancerBackends,
+		},
+		{
+			name:      "should return error when the load balancer backends are invalid",
+			expectErr: true,
+			c:         invalidLoadBalancerBackends,
+		},
+		{
+			name:      "should succeed when the health check is valid",
+			expectErr: false,
+			c:         validHealthCheck,
+		},
+		{
+			name:      "should return error when the health check is invalid",
+			expectErr: true,
+			c:         invalidHealthCheck,
+		},
+		{
+			name:      "should return error when failure domains have no label key",
+			expectErr: true,
+			c:         invalidFailureDomains,
+		},
+		{
+			name:      "should succeed when failure domains have a label key",
+			expectErr: false,
+			c:         validFailureDomains,
+		},
+		{
+			name:      "should return error when a node label prefix is empty",
+			expectErr: true,
+			c:         invalidNodeLabelPrefixes,
+		},
+		{
+			name:      "should return error when a node annotation prefix is empty",
+			expectErr: true,
+			c:         invalidNodeAnnotationPrefixes,
+		},
+		{
+			name:      "should return error when the providerID format is unknown",
+			expectErr: true,
+			c:         invalidProviderIDFormat,
+		},
+		{
+			name:      "should succeed when the providerID format is known",
+			expectErr: false,
+			c:         validProviderIDFormat,
+		},
+		{
+			name:      "should return error when the node deletion policy is invalid",
+			expectErr: true,
+			c:         invalidNodeDeletion,
+		},
+		{
+			name:      "should succeed when the node deletion policy is valid",
+			expectErr: false,
+			c:         validNodeDeletion,
+		},
 		{
 			name:      "should succeed when endpoint correct",
 			expectErr: false,
@@ -82,3 +255,24 @@ func TestMetal3ClusterValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMetal3ClusterHealthCheckValidationPaths(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &Metal3Cluster{
+		Spec: Metal3ClusterSpec{
+			ControlPlaneEndpoint: APIEndpoint{Host: "abc.com", Port: 443},
+			ControlPlaneEndpointHealthCheck: &ControlPlaneEndpointHealthCheck{
+				Mode:    "HTTP",
+				Period:  &metav1.Duration{Duration: -time.Second},
+				Timeout: &metav1.Duration{},
+			},
+		},
+	}
+
+	err := c.ValidateCreate()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.controlPlaneEndpointHealthCheck.mode"))
+	g.Expect(err.Error()).To(ContainSubstring("spec.controlPlaneEndpointHealthCheck.period"))
+	g.Expect(err.Error()).To(ContainSubstring("spec.controlPlaneEndpointHealthCheck.timeout"))
+}
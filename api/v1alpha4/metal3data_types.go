@@ -19,6 +19,7 @@ package v1alpha4
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
 const (
@@ -52,6 +53,10 @@ type Metal3DataStatus struct {
 
 	// ErrorMessage contains the error message
 	ErrorMessage *string `json:"errorMessage,omitempty"`
+
+	// Conditions defines current service state of the Metal3Data.
+	// +optional
+	Conditions capi.Conditions `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -68,6 +73,16 @@ type Metal3Data struct {
 	Status Metal3DataStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the list of conditions for a Metal3Data.
+func (c *Metal3Data) GetConditions() capi.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on a Metal3Data.
+func (c *Metal3Data) SetConditions(conditions capi.Conditions) {
+	c.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // Metal3DataList contains a list of Metal3Data
@@ -34,7 +34,8 @@ const (
 // Metal3MachineSpec defines the desired state of Metal3Machine
 type Metal3MachineSpec struct {
 	// ProviderID will be the Metal3 machine in ProviderID format
-	// (metal3://<bmh-uuid>)
+	// (metal3://<bmh-uuid> or
+	// metal3://<namespace>/<bmh-name>/<metal3machine-name>)
 	// +optional
 	ProviderID *string `json:"providerID,omitempty"`
 
@@ -51,6 +52,39 @@ type Metal3MachineSpec struct {
 	// claiming for a metal3machine.
 	HostSelector HostSelector `json:"hostSelector,omitempty"`
 
+	// HostScoring specifies how to choose among the BareMetalHosts matching
+	// the HostSelector. If unset, one of them is picked at random.
+	// +optional
+	HostScoring *HostScoring `json:"hostScoring,omitempty"`
+
+	// HardwareRequirements specifies the minimal hardware, as found during
+	// inspection, of the BareMetalHosts considered for claiming.
+	// +optional
+	HardwareRequirements *HardwareRequirements `json:"hardwareRequirements,omitempty"`
+
+	// AntiAffinity specifies the BareMetalHosts to avoid because they share a
+	// topology domain with the hosts consumed by sibling Metal3Machines.
+	// +optional
+	AntiAffinity *HostAntiAffinity `json:"antiAffinity,omitempty"`
+
+	// InPlaceUpgrade allows changing the Image of a provisioned metal3machine.
+	// The BareMetalHost is then deprovisioned and provisioned again with the
+	// new image, keeping its association with the metal3machine, its
+	// Metal3Data and its IP address allocations.
+	// +optional
+	InPlaceUpgrade bool `json:"inPlaceUpgrade,omitempty"`
+
+	// NodeLabels are set on the Node of the workload cluster once the
+	// providerID is set, and kept in sync. They take precedence over the
+	// labels copied with the nodeLabelPrefixes of the Metal3Cluster.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// NodeTaints are set on the Node of the workload cluster once the
+	// providerID is set, and kept in sync.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+
 	// MetadataTemplate is a reference to a Metal3DataTemplate object containing
 	// a template of metadata to be rendered. Metadata keys defined in the
 	// metadataTemplate take precendence over keys defined in metadata field.
@@ -132,7 +166,8 @@ type Metal3MachineStatus struct {
 	Addresses capi.MachineAddresses `json:"addresses,omitempty"`
 
 	// Phase represents the current phase of machine actuation.
-	// E.g. Pending, Running, Terminating, Failed etc.
+	// One of Pending, Associating, WaitingForData, Provisioning, Provisioned,
+	// Deprovisioning or Failed.
 	// +optional
 	Phase string `json:"phase,omitempty"`
 
@@ -159,6 +194,162 @@ type Metal3MachineStatus struct {
 	// NetworkData is an object storing the reference to the secret containing the
 	// network data used to deploy the BareMetalHost.
 	NetworkData *corev1.SecretReference `json:"networkData,omitempty"`
+
+	// ImageUpgrade reports the progress of the last in-place image upgrade.
+	// +optional
+	ImageUpgrade *ImageUpgradeStatus `json:"imageUpgrade,omitempty"`
+
+	// HostSelection explains why no BareMetalHost could be associated with the
+	// Metal3Machine. It is cleared once a BareMetalHost is associated.
+	// +optional
+	HostSelection *HostSelectionStatus `json:"hostSelection,omitempty"`
+
+	// Conditions defines current service state of the Metal3Machine.
+	// +optional
+	Conditions capi.Conditions `json:"conditions,omitempty"`
+}
+
+// Metal3MachinePhase is the phase of a Metal3Machine.
+type Metal3MachinePhase string
+
+const (
+	// Metal3MachinePhasePending is the phase of a Metal3Machine waiting for
+	// the cluster infrastructure or the bootstrap data.
+	Metal3MachinePhasePending = Metal3MachinePhase("Pending")
+
+	// Metal3MachinePhaseAssociating is the phase of a Metal3Machine being
+	// associated with a BareMetalHost.
+	Metal3MachinePhaseAssociating = Metal3MachinePhase("Associating")
+
+	// Metal3MachinePhaseWaitingForData is the phase of a Metal3Machine
+	// waiting for its Metal3Data to be rendered.
+	Metal3MachinePhaseWaitingForData = Metal3MachinePhase("WaitingForData")
+
+	// Metal3MachinePhaseProvisioning is the phase of a Metal3Machine whose
+	// BareMetalHost is being provisioned.
+	Metal3MachinePhaseProvisioning = Metal3MachinePhase("Provisioning")
+
+	// Metal3MachinePhaseProvisioned is the phase of a Metal3Machine whose
+	// BareMetalHost is provisioned.
+	Metal3MachinePhaseProvisioned = Metal3MachinePhase("Provisioned")
+
+	// Metal3MachinePhaseDeprovisioning is the phase of a Metal3Machine whose
+	// BareMetalHost is being deprovisioned.
+	Metal3MachinePhaseDeprovisioning = Metal3MachinePhase("Deprovisioning")
+
+	// Metal3MachinePhaseFailed is the phase of a Metal3Machine with a
+	// failure, or whose BareMetalHost is in error.
+	Metal3MachinePhaseFailed = Metal3MachinePhase("Failed")
+)
+
+// SetTypedPhase sets the Phase field to the string representation of
+// Metal3MachinePhase.
+func (m *Metal3MachineStatus) SetTypedPhase(p Metal3MachinePhase) {
+	m.Phase = string(p)
+}
+
+// GetTypedPhase attempts to parse the Phase field and return
+// the typed Metal3MachinePhase representation.
+func (m *Metal3MachineStatus) GetTypedPhase() Metal3MachinePhase {
+	return Metal3MachinePhase(m.Phase)
+}
+
+// ImageUpgradeState is the state of an in-place image upgrade.
+type ImageUpgradeState string
+
+const (
+	// ImageUpgradeDeprovisioning is the state of an upgrade waiting for the
+	// BareMetalHost to be deprovisioned.
+	ImageUpgradeDeprovisioning ImageUpgradeState = "Deprovisioning"
+
+	// ImageUpgradeProvisioning is the state of an upgrade waiting for the
+	// BareMetalHost to be provisioned with the new image.
+	ImageUpgradeProvisioning ImageUpgradeState = "Provisioning"
+
+	// ImageUpgradeCompleted is the state of an upgrade once the
+	// BareMetalHost is provisioned with the new image.
+	ImageUpgradeCompleted ImageUpgradeState = "Completed"
+)
+
+// ImageUpgradeStatus reports the progress of an in-place image upgrade.
+type ImageUpgradeStatus struct {
+	// State is the state of the upgrade.
+	State ImageUpgradeState `json:"state"`
+
+	// Image is the image the BareMetalHost is upgraded to.
+	Image Image `json:"image"`
+
+	// StartTime identifies when the upgrade started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime identifies when the upgrade completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// HostSelectionStatus reports the BareMetalHosts that were rejected when
+// choosing a host for a Metal3Machine.
+type HostSelectionStatus struct {
+	// Hosts is the number of BareMetalHosts in the namespace of the
+	// Metal3Machine.
+	Hosts int `json:"hosts"`
+
+	// Rejected counts the BareMetalHosts that were rejected, by reason.
+	Rejected HostRejections `json:"rejected"`
+}
+
+// HostRejections counts the BareMetalHosts rejected for a Metal3Machine, by
+// reason. A host is only counted for the first reason it is rejected for.
+type HostRejections struct {
+	// Consumed is the number of hosts already consumed by another machine.
+	// +optional
+	Consumed int `json:"consumed,omitempty"`
+
+	// Deleting is the number of hosts being deleted.
+	// +optional
+	Deleting int `json:"deleting,omitempty"`
+
+	// Error is the number of hosts with an error message.
+	// +optional
+	Error int `json:"error,omitempty"`
+
+	// ProvisioningState is the number of hosts that are neither ready nor
+	// available.
+	// +optional
+	ProvisioningState int `json:"provisioningState,omitempty"`
+
+	// Paused is the number of paused hosts.
+	// +optional
+	Paused int `json:"paused,omitempty"`
+
+	// Unhealthy is the number of hosts with the unhealthy annotation.
+	// +optional
+	Unhealthy int `json:"unhealthy,omitempty"`
+
+	// LabelMismatch is the number of hosts not matching the HostSelector.
+	// +optional
+	LabelMismatch int `json:"labelMismatch,omitempty"`
+
+	// FailureDomain is the number of hosts outside of the failure domain of
+	// the Machine.
+	// +optional
+	FailureDomain int `json:"failureDomain,omitempty"`
+
+	// HardwareRequirements is the number of hosts not matching the
+	// HardwareRequirements.
+	// +optional
+	HardwareRequirements int `json:"hardwareRequirements,omitempty"`
+
+	// AntiAffinity is the number of hosts violating a required anti-affinity
+	// term.
+	// +optional
+	AntiAffinity int `json:"antiAffinity,omitempty"`
+
+	// NodeReuse is the number of hosts held back while waiting for a host
+	// released for reuse by the owner of the Machine.
+	// +optional
+	NodeReuse int `json:"nodeReuse,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -166,10 +357,12 @@ type Metal3MachineStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:storageversion
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
-// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="metal3machine is Ready"
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this M3Machine belongs"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="metal3machine current phase"
+// +kubebuilder:printcolumn:name="Host",type="string",JSONPath=".metadata.annotations.metal3\\.io/BareMetalHost",description="BareMetalHost associated with the M3Machine"
+// +kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="metal3machine is Ready"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of Metal3Machine"
 
 // Metal3Machine is the Schema for the metal3machines API
 type Metal3Machine struct {
@@ -180,6 +373,16 @@ type Metal3Machine struct {
 	Status Metal3MachineStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the list of conditions for a Metal3Machine.
+func (c *Metal3Machine) GetConditions() capi.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on a Metal3Machine.
+func (c *Metal3Machine) SetConditions(conditions capi.Conditions) {
+	c.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // Metal3MachineList contains a list of Metal3Machine
@@ -14,8 +14,11 @@ limitations under the License.
 package v1alpha4
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -76,8 +79,158 @@ func (c *Metal3Machine) validate() error {
 
 	}
 
+	allErrs = append(allErrs, validateHostScoring(c.Spec.HostScoring,
+		field.NewPath("spec", "HostScoring"),
+	)...)
+
+	allErrs = append(allErrs, validateHardwareRequirements(
+		c.Spec.HardwareRequirements,
+		field.NewPath("spec", "HardwareRequirements"),
+	)...)
+
+	allErrs = append(allErrs, validateHostAntiAffinity(c.Spec.AntiAffinity,
+		field.NewPath("spec", "AntiAffinity"),
+	)...)
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(c.Spec.NodeLabels,
+		field.NewPath("spec", "NodeLabels"),
+	)...)
+
+	allErrs = append(allErrs, validateNodeTaints(c.Spec.NodeTaints,
+		field.NewPath("spec", "NodeTaints"),
+	)...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Machine").GroupKind(), c.Name, allErrs)
 }
+
+// validateHostScoring checks that the label based scoring policies are given
+// a label key.
+func validateHostScoring(scoring *HostScoring, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if scoring == nil {
+		return allErrs
+	}
+	switch scoring.Policy {
+	case HostScoringPackByLabel, HostScoringSpreadByLabel:
+		if scoring.LabelKey == "" {
+			allErrs = append(allErrs,
+				field.Invalid(fldPath.Child("LabelKey"), scoring.LabelKey,
+					"is required for the "+string(scoring.Policy)+" policy",
+				),
+			)
+		}
+	}
+	return allErrs
+}
+
+// validateHardwareRequirements checks that the hardware requirements are not
+// negative.
+func validateHardwareRequirements(requirements *HardwareRequirements,
+	fldPath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if requirements == nil {
+		return allErrs
+	}
+	minimums := []struct {
+		name  string
+		value int
+	}{
+		{"MinCPUCount", requirements.MinCPUCount},
+		{"MinRAMMebibytes", requirements.MinRAMMebibytes},
+		{"MinRootDiskGibibytes", requirements.MinRootDiskGibibytes},
+		{"MinNICCount", requirements.MinNICCount},
+		{"MinNICSpeedGbps", requirements.MinNICSpeedGbps},
+	}
+	for _, minimum := range minimums {
+		if minimum.value < 0 {
+			allErrs = append(allErrs,
+				field.Invalid(fldPath.Child(minimum.name), minimum.value,
+					"must be greater than or equal to 0",
+				),
+			)
+		}
+	}
+	return allErrs
+}
+
+// validateHostAntiAffinity checks that the anti-affinity terms are complete
+// and that the weights are in range.
+func validateHostAntiAffinity(antiAffinity *HostAntiAffinity,
+	fldPath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if antiAffinity == nil {
+		return allErrs
+	}
+	for i, term := range antiAffinity.Required {
+		allErrs = append(allErrs, validateHostAntiAffinityTerm(term,
+			fldPath.Child("Required").Index(i),
+		)...)
+	}
+	for i, weightedTerm := range antiAffinity.Preferred {
+		termPath := fldPath.Child("Preferred").Index(i)
+		if weightedTerm.Weight < 1 || weightedTerm.Weight > 100 {
+			allErrs = append(allErrs,
+				field.Invalid(termPath.Child("Weight"), weightedTerm.Weight,
+					"must be in the range 1-100",
+				),
+			)
+		}
+		allErrs = append(allErrs, validateHostAntiAffinityTerm(
+			weightedTerm.Term, termPath.Child("Term"),
+		)...)
+	}
+	return allErrs
+}
+
+// validateNodeTaints checks that the taints have a valid key and effect.
+func validateNodeTaints(taints []corev1.Taint, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, taint := range taints {
+		taintPath := fldPath.Index(i)
+		for _, msg := range validation.IsQualifiedName(taint.Key) {
+			allErrs = append(allErrs,
+				field.Invalid(taintPath.Child("Key"), taint.Key, msg),
+			)
+		}
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule,
+			corev1.TaintEffectNoExecute:
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(taintPath.Child("Effect"), taint.Effect,
+					[]string{string(corev1.TaintEffectNoSchedule),
+						string(corev1.TaintEffectPreferNoSchedule),
+						string(corev1.TaintEffectNoExecute),
+					},
+				),
+			)
+		}
+	}
+	return allErrs
+}
+
+func validateHostAntiAffinityTerm(term HostAntiAffinityTerm,
+	fldPath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if term.TopologyKey == "" {
+		allErrs = append(allErrs,
+			field.Invalid(fldPath.Child("TopologyKey"), term.TopologyKey,
+				"is required",
+			),
+		)
+	}
+	if len(term.MachineLabelKeys) == 0 {
+		allErrs = append(allErrs,
+			field.Invalid(fldPath.Child("MachineLabelKeys"),
+				term.MachineLabelKeys, "must not be empty",
+			),
+		)
+	}
+	return allErrs
+}
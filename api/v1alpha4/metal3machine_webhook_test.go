@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -49,6 +50,85 @@ func TestMetal3MachineValidation(t *testing.T) {
 	invalidChecksum := valid.DeepCopy()
 	invalidChecksum.Spec.Image.Checksum = ""
 
+	invalidHostScoring := valid.DeepCopy()
+	invalidHostScoring.Spec.HostScoring = &HostScoring{
+		Policy: HostScoringSpreadByLabel,
+	}
+
+	validHostScoring := valid.DeepCopy()
+	validHostScoring.Spec.HostScoring = &HostScoring{
+		Policy:   HostScoringPackByLabel,
+		LabelKey: "rack",
+	}
+
+	invalidHardwareRequirements := valid.DeepCopy()
+	invalidHardwareRequirements.Spec.HardwareRequirements = &HardwareRequirements{
+		MinRAMMebibytes: -1,
+	}
+
+	validHardwareRequirements := valid.DeepCopy()
+	validHardwareRequirements.Spec.HardwareRequirements = &HardwareRequirements{
+		MinCPUCount:          4,
+		MinRAMMebibytes:      16384,
+		MinRootDiskGibibytes: 100,
+		MinNICCount:          2,
+		MinNICSpeedGbps:      10,
+		CPUArchitecture:      "x86_64",
+	}
+
+	invalidAntiAffinity := valid.DeepCopy()
+	invalidAntiAffinity.Spec.AntiAffinity = &HostAntiAffinity{
+		Required: []HostAntiAffinityTerm{
+			{TopologyKey: "topology.metal3.io/chassis"},
+		},
+	}
+
+	invalidAntiAffinityWeight := valid.DeepCopy()
+	invalidAntiAffinityWeight.Spec.AntiAffinity = &HostAntiAffinity{
+		Preferred: []WeightedHostAntiAffinityTerm{
+			{
+				Weight: 0,
+				Term: HostAntiAffinityTerm{
+					TopologyKey:      "topology.metal3.io/chassis",
+					MachineLabelKeys: []string{"cluster.x-k8s.io/cluster-name"},
+				},
+			},
+		},
+	}
+
+	validAntiAffinity := valid.DeepCopy()
+	validAntiAffinity.Spec.AntiAffinity = &HostAntiAffinity{
+		Required: []HostAntiAffinityTerm{
+			{
+				TopologyKey:      "topology.metal3.io/chassis",
+				MachineLabelKeys: []string{"cluster.x-k8s.io/cluster-name"},
+			},
+		},
+		Preferred: []WeightedHostAntiAffinityTerm{
+			{
+				Weight: 100,
+				Term: HostAntiAffinityTerm{
+					TopologyKey:      "topology.metal3.io/rack",
+					MachineLabelKeys: []string{"cluster.x-k8s.io/cluster-name"},
+				},
+			},
+		},
+	}
+
+	invalidNodeLabels := valid.DeepCopy()
+	invalidNodeLabels.Spec.NodeLabels = map[string]string{"node-class": "big memory"}
+
+	invalidNodeTaints := valid.DeepCopy()
+	invalidNodeTaints.Spec.NodeTaints = []corev1.Taint{
+		{Key: "node-class", Value: "storage", Effect: "Evict"},
+	}
+
+	validNodeLabelsAndTaints := valid.DeepCopy()
+	validNodeLabelsAndTaints.Spec.NodeLabels = map[string]string{"node-class": "storage"}
+	validNodeLabelsAndTaints.Spec.NodeTaints = []corev1.Taint{
+		{Key: "node-class", Value: "storage", Effect: corev1.TaintEffectNoSchedule},
+	}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -64,6 +144,56 @@ func TestMetal3MachineValidation(t *testing.T) {
 			expectErr: true,
 			c:         invalidChecksum,
 		},
+		{
+			name:      "should return error when label key missing for label policy",
+			expectErr: true,
+			c:         invalidHostScoring,
+		},
+		{
+			name:      "should succeed when label policy has a label key",
+			expectErr: false,
+			c:         validHostScoring,
+		},
+		{
+			name:      "should return error when a hardware requirement is negative",
+			expectErr: true,
+			c:         invalidHardwareRequirements,
+		},
+		{
+			name:      "should succeed when hardware requirements are positive",
+			expectErr: false,
+			c:         validHardwareRequirements,
+		},
+		{
+			name:      "should return error when anti-affinity term is incomplete",
+			expectErr: true,
+			c:         invalidAntiAffinity,
+		},
+		{
+			name:      "should return error when anti-affinity weight is out of range",
+			expectErr: true,
+			c:         invalidAntiAffinityWeight,
+		},
+		{
+			name:      "should succeed when anti-affinity is valid",
+			expectErr: false,
+			c:         validAntiAffinity,
+		},
+		{
+			name:      "should return error when a node label value is invalid",
+			expectErr: true,
+			c:         invalidNodeLabels,
+		},
+		{
+			name:      "should return error when a node taint effect is invalid",
+			expectErr: true,
+			c:         invalidNodeTaints,
+		},
+		{
+			name:      "should succeed when node labels and taints are valid",
+			expectErr: false,
+			c:         validNodeLabelsAndTaints,
+		},
 		{
 			name:      "should succeed when image correct",
 			expectErr: false,
@@ -20,9 +20,41 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// NodeReuseLabelName is the label set on a BareMetalHost released by a
+	// Metal3Machine created from a template with node reuse. Its value is the
+	// name of the MachineDeployment or KubeadmControlPlane of the Machine.
+	NodeReuseLabelName = "infrastructure.cluster.x-k8s.io/node-reuse"
+)
+
+// NodeReusePolicy defines whether the BareMetalHosts released by the
+// Metal3Machines of a MachineDeployment or KubeadmControlPlane are reused for
+// its next Metal3Machines.
+type NodeReusePolicy string
+
+const (
+	// NodeReusePreferred prefers the available hosts released by the same
+	// owner, and falls back to any available host.
+	NodeReusePreferred NodeReusePolicy = "Preferred"
+
+	// NodeReuseRequired only considers the hosts released by the same owner
+	// as long as any of them is not consumed, waiting for them to become
+	// available again. The released hosts that are deleted, in error or
+	// unhealthy are not waited for.
+	NodeReuseRequired NodeReusePolicy = "Required"
+)
+
 // Metal3MachineTemplateSpec defines the desired state of Metal3MachineTemplate
 type Metal3MachineTemplateSpec struct {
 	Template Metal3MachineTemplateResource `json:"template"`
+
+	// NodeReuse enables reusing, during a rolling upgrade, the BareMetalHosts
+	// released by the Metal3Machines of the same MachineDeployment or
+	// KubeadmControlPlane, in order to keep their local storage. If unset,
+	// the hosts are not reused.
+	// +kubebuilder:validation:Enum=Preferred;Required
+	// +optional
+	NodeReuse NodeReusePolicy `json:"nodeReuse,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -15,6 +15,7 @@ package v1alpha4
 
 import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -76,6 +77,42 @@ func (c *Metal3MachineTemplate) validate() error {
 
 	}
 
+	allErrs = append(allErrs, validateHostScoring(c.Spec.Template.Spec.HostScoring,
+		field.NewPath("spec", "Template", "Spec", "HostScoring"),
+	)...)
+
+	allErrs = append(allErrs, validateHardwareRequirements(
+		c.Spec.Template.Spec.HardwareRequirements,
+		field.NewPath("spec", "Template", "Spec", "HardwareRequirements"),
+	)...)
+
+	allErrs = append(allErrs, validateHostAntiAffinity(
+		c.Spec.Template.Spec.AntiAffinity,
+		field.NewPath("spec", "Template", "Spec", "AntiAffinity"),
+	)...)
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(
+		c.Spec.Template.Spec.NodeLabels,
+		field.NewPath("spec", "Template", "Spec", "NodeLabels"),
+	)...)
+
+	allErrs = append(allErrs, validateNodeTaints(
+		c.Spec.Template.Spec.NodeTaints,
+		field.NewPath("spec", "Template", "Spec", "NodeTaints"),
+	)...)
+
+	switch c.Spec.NodeReuse {
+	case "", NodeReusePreferred, NodeReuseRequired:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec", "NodeReuse"),
+				c.Spec.NodeReuse, []string{string(NodeReusePreferred),
+					string(NodeReuseRequired),
+				},
+			),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -53,6 +53,35 @@ func TestMetal3MachineTemplateValidation(t *testing.T) {
 	invalidChecksum := valid.DeepCopy()
 	invalidChecksum.Spec.Template.Spec.Image.Checksum = ""
 
+	invalidHostScoring := valid.DeepCopy()
+	invalidHostScoring.Spec.Template.Spec.HostScoring = &HostScoring{
+		Policy: HostScoringSpreadByLabel,
+	}
+
+	validHostScoring := valid.DeepCopy()
+	validHostScoring.Spec.Template.Spec.HostScoring = &HostScoring{
+		Policy:   HostScoringPackByLabel,
+		LabelKey: "rack",
+	}
+
+	invalidHardwareRequirements := valid.DeepCopy()
+	invalidHardwareRequirements.Spec.Template.Spec.HardwareRequirements = &HardwareRequirements{
+		MinNICSpeedGbps: -10,
+	}
+
+	invalidAntiAffinity := valid.DeepCopy()
+	invalidAntiAffinity.Spec.Template.Spec.AntiAffinity = &HostAntiAffinity{
+		Required: []HostAntiAffinityTerm{
+			{MachineLabelKeys: []string{"cluster.x-k8s.io/cluster-name"}},
+		},
+	}
+
+	invalidNodeReuse := valid.DeepCopy()
+	invalidNodeReuse.Spec.NodeReuse = "Always"
+
+	validNodeReuse := valid.DeepCopy()
+	validNodeReuse.Spec.NodeReuse = NodeReuseRequired
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -68,6 +97,36 @@ func TestMetal3MachineTemplateValidation(t *testing.T) {
 			expectErr: true,
 			c:         invalidChecksum,
 		},
+		{
+			name:      "should return error when label key missing for label policy",
+			expectErr: true,
+			c:         invalidHostScoring,
+		},
+		{
+			name:      "should succeed when label policy has a label key",
+			expectErr: false,
+			c:         validHostScoring,
+		},
+		{
+			name:      "should return error when a hardware requirement is negative",
+			expectErr: true,
+			c:         invalidHardwareRequirements,
+		},
+		{
+			name:      "should return error when anti-affinity term is incomplete",
+			expectErr: true,
+			c:         invalidAntiAffinity,
+		},
+		{
+			name:      "should return error when node reuse policy is unknown",
+			expectErr: true,
+			c:         invalidNodeReuse,
+		},
+		{
+			name:      "should succeed when node reuse policy is known",
+			expectErr: false,
+			c:         validNodeReuse,
+		},
 		{
 			name:      "should succeed when image correct",
 			expectErr: false,
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationType is the type of remediation applied to the BareMetalHost of
+// an unhealthy Machine.
+type RemediationType string
+
+const (
+	// RebootRemediationStrategy power cycles the BareMetalHost.
+	RebootRemediationStrategy RemediationType = "Reboot"
+
+	// DefaultRemediationRetryLimit is the default number of reboots before
+	// the Machine is deleted.
+	DefaultRemediationRetryLimit = 1
+
+	// DefaultRemediationTimeout is the default time to wait for the node to
+	// become healthy after a reboot.
+	DefaultRemediationTimeout = 600 * time.Second
+)
+
+const (
+	// PhaseRunning is the phase of a remediation rebooting the host.
+	PhaseRunning = "Running"
+
+	// PhaseWaiting is the phase of a remediation waiting for the node to
+	// become healthy after a reboot.
+	PhaseWaiting = "Waiting"
+
+	// PhaseDeleting is the phase of a remediation that ran out of retries.
+	// The host is marked unhealthy and the Machine is deleted.
+	PhaseDeleting = "Deleting machine"
+)
+
+// Metal3RemediationSpec defines the desired state of Metal3Remediation.
+type Metal3RemediationSpec struct {
+	// Strategy is the remediation strategy.
+	// +optional
+	Strategy *RemediationStrategy `json:"strategy,omitempty"`
+}
+
+// RemediationStrategy describes how to remediate an unhealthy Machine.
+type RemediationStrategy struct {
+	// Type of remediation.
+	// +kubebuilder:validation:Enum=Reboot
+	// +optional
+	Type RemediationType `json:"type,omitempty"`
+
+	// RetryLimit is the maximum number of reboots, 1 if unset. Once reached,
+	// the host is marked unhealthy and the Machine is deleted so that it gets
+	// replaced. With 0, the Machine is deleted without reboot.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetryLimit *int32 `json:"retryLimit,omitempty"`
+
+	// Timeout is the time to wait for the node to become healthy after a
+	// reboot before retrying.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Metal3RemediationStatus defines the observed state of Metal3Remediation.
+type Metal3RemediationStatus struct {
+	// Phase represents the current phase of the remediation.
+	// E.g. Running, Waiting, Deleting machine.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// RetryCount is the number of reboots done so far.
+	// +optional
+	RetryCount int `json:"retryCount,omitempty"`
+
+	// LastRemediated identifies when the host was last rebooted.
+	// +optional
+	LastRemediated *metav1.Time `json:"lastRemediated,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=metal3remediations,scope=Namespaced,categories=cluster-api,shortName=m3r;m3remediation
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Strategy",type="string",JSONPath=".spec.strategy.type",description="Type of the remediation strategy"
+// +kubebuilder:printcolumn:name="Retry limit",type="string",JSONPath=".spec.strategy.retryLimit",description="How many times the host is rebooted"
+// +kubebuilder:printcolumn:name="Retry count",type="string",JSONPath=".status.retryCount",description="How many times the host was rebooted"
+// +kubebuilder:printcolumn:name="Last Remediated",type="string",JSONPath=".status.lastRemediated",description="Timestamp of the last reboot"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Phase of the remediation"
+// Metal3Remediation is the Schema for the metal3remediations API
+type Metal3Remediation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   Metal3RemediationSpec   `json:"spec,omitempty"`
+	Status Metal3RemediationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Metal3RemediationList contains a list of Metal3Remediation
+type Metal3RemediationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Metal3Remediation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Metal3Remediation{}, &Metal3RemediationList{})
+}
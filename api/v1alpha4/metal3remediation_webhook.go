@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (c *Metal3Remediation) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(c).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha4-metal3remediation,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,versions=v1alpha4,name=validation.metal3remediation.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha4-metal3remediation,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,versions=v1alpha4,name=default.metal3remediation.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent
+
+var _ webhook.Defaulter = &Metal3Remediation{}
+var _ webhook.Validator = &Metal3Remediation{}
+
+func (c *Metal3Remediation) Default() {
+	c.Spec.Strategy = defaultRemediationStrategy(c.Spec.Strategy)
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (c *Metal3Remediation) ValidateCreate() error {
+	return c.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (c *Metal3Remediation) ValidateUpdate(old runtime.Object) error {
+	return c.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (c *Metal3Remediation) ValidateDelete() error {
+	return nil
+}
+
+func (c *Metal3Remediation) validate() error {
+	allErrs := validateRemediationStrategy(c.Spec.Strategy,
+		field.NewPath("spec", "strategy"),
+	)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Remediation").GroupKind(), c.Name, allErrs)
+}
+
+// defaultRemediationStrategy returns the strategy with the unset fields set to
+// their default value.
+func defaultRemediationStrategy(strategy *RemediationStrategy) *RemediationStrategy {
+	if strategy == nil {
+		strategy = &RemediationStrategy{}
+	}
+	if strategy.Type == "" {
+		strategy.Type = RebootRemediationStrategy
+	}
+	if strategy.RetryLimit == nil {
+		retryLimit := int32(DefaultRemediationRetryLimit)
+		strategy.RetryLimit = &retryLimit
+	}
+	if strategy.Timeout == nil {
+		strategy.Timeout = &metav1.Duration{Duration: DefaultRemediationTimeout}
+	}
+	return strategy
+}
+
+// validateRemediationStrategy checks that the strategy type is supported and
+// that the retry limit and timeout are positive.
+func validateRemediationStrategy(strategy *RemediationStrategy,
+	fldPath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if strategy == nil {
+		return allErrs
+	}
+	if strategy.Type != "" && strategy.Type != RebootRemediationStrategy {
+		allErrs = append(allErrs,
+			field.NotSupported(fldPath.Child("type"), strategy.Type,
+				[]string{string(RebootRemediationStrategy)},
+			),
+		)
+	}
+	if strategy.RetryLimit != nil && *strategy.RetryLimit < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(fldPath.Child("retryLimit"), *strategy.RetryLimit,
+				"must be greater than or equal to 0",
+			),
+		)
+	}
+	if strategy.Timeout != nil && strategy.Timeout.Duration <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(fldPath.Child("timeout"), strategy.Timeout.Duration.String(),
+				"must be greater than 0",
+			),
+		)
+	}
+	return allErrs
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestMetal3RemediationDefault(t *testing.T) {
+	g := NewWithT(t)
+	c := &Metal3Remediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "fooboo",
+		},
+		Spec: Metal3RemediationSpec{},
+	}
+	c.Default()
+
+	g.Expect(c.Spec.Strategy).To(Equal(&RemediationStrategy{
+		Type:       RebootRemediationStrategy,
+		RetryLimit: pointer.Int32Ptr(DefaultRemediationRetryLimit),
+		Timeout:    &metav1.Duration{Duration: DefaultRemediationTimeout},
+	}))
+
+	c.Spec.Strategy = &RemediationStrategy{
+		RetryLimit: pointer.Int32Ptr(3),
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}
+	c.Default()
+
+	g.Expect(c.Spec.Strategy).To(Equal(&RemediationStrategy{
+		Type:       RebootRemediationStrategy,
+		RetryLimit: pointer.Int32Ptr(3),
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}))
+
+	c.Spec.Strategy = &RemediationStrategy{RetryLimit: pointer.Int32Ptr(0)}
+	c.Default()
+
+	g.Expect(*c.Spec.Strategy.RetryLimit).To(BeZero())
+}
+
+func TestMetal3RemediationValidation(t *testing.T) {
+	valid := &Metal3Remediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: Metal3RemediationSpec{
+			Strategy: &RemediationStrategy{
+				Type:       RebootRemediationStrategy,
+				RetryLimit: pointer.Int32Ptr(2),
+				Timeout:    &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	invalidType := valid.DeepCopy()
+	invalidType.Spec.Strategy.Type = "PowerOff"
+
+	invalidRetryLimit := valid.DeepCopy()
+	invalidRetryLimit.Spec.Strategy.RetryLimit = pointer.Int32Ptr(-1)
+
+	noRetry := valid.DeepCopy()
+	noRetry.Spec.Strategy.RetryLimit = pointer.Int32Ptr(0)
+
+	invalidTimeout := valid.DeepCopy()
+	invalidTimeout.Spec.Strategy.Timeout = &metav1.Duration{}
+
+	tests := []struct {
+		name      string
+		expectErr bool
+		c         *Metal3Remediation
+	}{
+		{
+			name:      "should return error when type is unsupported",
+			expectErr: true,
+			c:         invalidType,
+		},
+		{
+			name:      "should return error when retry limit is negative",
+			expectErr: true,
+			c:         invalidRetryLimit,
+		},
+		{
+			name:      "should succeed when retry limit is zero",
+			expectErr: false,
+			c:         noRetry,
+		},
+		{
+			name:      "should return error when timeout is zero",
+			expectErr: true,
+			c:         invalidTimeout,
+		},
+		{
+			name:      "should succeed when strategy is unset",
+			expectErr: false,
+			c:         &Metal3Remediation{},
+		},
+		{
+			name:      "should succeed when strategy is correct",
+			expectErr: false,
+			c:         valid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.expectErr {
+				g.Expect(tt.c.ValidateCreate()).NotTo(Succeed())
+				g.Expect(tt.c.ValidateUpdate(nil)).NotTo(Succeed())
+			} else {
+				g.Expect(tt.c.ValidateCreate()).To(Succeed())
+				g.Expect(tt.c.ValidateUpdate(nil)).To(Succeed())
+			}
+			g.Expect(tt.c.ValidateDelete()).To(Succeed())
+		})
+	}
+}
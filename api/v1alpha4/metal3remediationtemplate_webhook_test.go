@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestMetal3RemediationTemplateDefault(t *testing.T) {
+	g := NewWithT(t)
+	c := &Metal3RemediationTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "fooboo",
+		},
+		Spec: Metal3RemediationTemplateSpec{},
+	}
+	c.Default()
+
+	g.Expect(c.Spec.Template.Spec.Strategy).To(Equal(&RemediationStrategy{
+		Type:       RebootRemediationStrategy,
+		RetryLimit: pointer.Int32Ptr(DefaultRemediationRetryLimit),
+		Timeout:    &metav1.Duration{Duration: DefaultRemediationTimeout},
+	}))
+}
+
+func TestMetal3RemediationTemplateValidation(t *testing.T) {
+	valid := &Metal3RemediationTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: Metal3RemediationTemplateSpec{
+			Template: Metal3RemediationTemplateResource{
+				Spec: Metal3RemediationSpec{
+					Strategy: &RemediationStrategy{
+						Type:       RebootRemediationStrategy,
+						RetryLimit: pointer.Int32Ptr(1),
+					},
+				},
+			},
+		},
+	}
+	invalidType := valid.DeepCopy()
+	invalidType.Spec.Template.Spec.Strategy.Type = "PowerOff"
+
+	tests := []struct {
+		name      string
+		expectErr bool
+		c         *Metal3RemediationTemplate
+	}{
+		{
+			name:      "should return error when type is unsupported",
+			expectErr: true,
+			c:         invalidType,
+		},
+		{
+			name:      "should succeed when strategy is correct",
+			expectErr: false,
+			c:         valid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.expectErr {
+				g.Expect(tt.c.ValidateCreate()).NotTo(Succeed())
+				g.Expect(tt.c.ValidateUpdate(nil)).NotTo(Succeed())
+			} else {
+				g.Expect(tt.c.ValidateCreate()).To(Succeed())
+				g.Expect(tt.c.ValidateUpdate(nil)).To(Succeed())
+			}
+		})
+	}
+}
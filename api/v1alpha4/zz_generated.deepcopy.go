@@ -22,7 +22,8 @@ package v1alpha4
 
 import (
 	"github.com/metal3-io/ip-address-manager/api/v1alpha1"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/errors"
@@ -43,6 +44,46 @@ func (in *APIEndpoint) DeepCopy() *APIEndpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneEndpointHealthCheck) DeepCopyInto(out *ControlPlaneEndpointHealthCheck) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneEndpointHealthCheck.
+func (in *ControlPlaneEndpointHealthCheck) DeepCopy() *ControlPlaneEndpointHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneEndpointHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneVIP) DeepCopyInto(out *ControlPlaneVIP) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneVIP.
+func (in *ControlPlaneVIP) DeepCopy() *ControlPlaneVIP {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneVIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FromPool) DeepCopyInto(out *FromPool) {
 	*out = *in
@@ -58,6 +99,116 @@ func (in *FromPool) DeepCopy() *FromPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareRequirements) DeepCopyInto(out *HardwareRequirements) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareRequirements.
+func (in *HardwareRequirements) DeepCopy() *HardwareRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostAntiAffinity) DeepCopyInto(out *HostAntiAffinity) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = make([]HostAntiAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preferred != nil {
+		in, out := &in.Preferred, &out.Preferred
+		*out = make([]WeightedHostAntiAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostAntiAffinity.
+func (in *HostAntiAffinity) DeepCopy() *HostAntiAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(HostAntiAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostAntiAffinityTerm) DeepCopyInto(out *HostAntiAffinityTerm) {
+	*out = *in
+	if in.MachineLabelKeys != nil {
+		in, out := &in.MachineLabelKeys, &out.MachineLabelKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostAntiAffinityTerm.
+func (in *HostAntiAffinityTerm) DeepCopy() *HostAntiAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(HostAntiAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostRejections) DeepCopyInto(out *HostRejections) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostRejections.
+func (in *HostRejections) DeepCopy() *HostRejections {
+	if in == nil {
+		return nil
+	}
+	out := new(HostRejections)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostScoring) DeepCopyInto(out *HostScoring) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostScoring.
+func (in *HostScoring) DeepCopy() *HostScoring {
+	if in == nil {
+		return nil
+	}
+	out := new(HostScoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostSelectionStatus) DeepCopyInto(out *HostSelectionStatus) {
+	*out = *in
+	out.Rejected = in.Rejected
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostSelectionStatus.
+func (in *HostSelectionStatus) DeepCopy() *HostSelectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HostSelectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostSelector) DeepCopyInto(out *HostSelector) {
 	*out = *in
@@ -132,6 +283,45 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageUpgradeStatus) DeepCopyInto(out *ImageUpgradeStatus) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageUpgradeStatus.
+func (in *ImageUpgradeStatus) DeepCopy() *ImageUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerBackends) DeepCopyInto(out *LoadBalancerBackends) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerBackends.
+func (in *LoadBalancerBackends) DeepCopy() *LoadBalancerBackends {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerBackends)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetaData) DeepCopyInto(out *MetaData) {
 	*out = *in
@@ -342,7 +532,7 @@ func (in *Metal3Cluster) DeepCopyInto(out *Metal3Cluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -400,6 +590,48 @@ func (in *Metal3ClusterList) DeepCopyObject() runtime.Object {
 func (in *Metal3ClusterSpec) DeepCopyInto(out *Metal3ClusterSpec) {
 	*out = *in
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.ControlPlaneEndpointFromIPPool != nil {
+		in, out := &in.ControlPlaneEndpointFromIPPool, &out.ControlPlaneEndpointFromIPPool
+		*out = new(string)
+		**out = **in
+	}
+	if in.ControlPlaneVIP != nil {
+		in, out := &in.ControlPlaneVIP, &out.ControlPlaneVIP
+		*out = new(ControlPlaneVIP)
+		**out = **in
+	}
+	if in.LoadBalancerBackends != nil {
+		in, out := &in.LoadBalancerBackends, &out.LoadBalancerBackends
+		*out = new(LoadBalancerBackends)
+		**out = **in
+	}
+	if in.ControlPlaneEndpointHealthCheck != nil {
+		in, out := &in.ControlPlaneEndpointHealthCheck, &out.ControlPlaneEndpointHealthCheck
+		*out = new(ControlPlaneEndpointHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make(v1alpha3.FailureDomains, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.NodeLabelPrefixes != nil {
+		in, out := &in.NodeLabelPrefixes, &out.NodeLabelPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeAnnotationPrefixes != nil {
+		in, out := &in.NodeAnnotationPrefixes, &out.NodeAnnotationPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeDeletion != nil {
+		in, out := &in.NodeDeletion, &out.NodeDeletion
+		*out = new(NodeDeletionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3ClusterSpec.
@@ -429,6 +661,24 @@ func (in *Metal3ClusterStatus) DeepCopyInto(out *Metal3ClusterStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make(v1alpha3.FailureDomains, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ControlPlaneEndpointLastHealthy != nil {
+		in, out := &in.ControlPlaneEndpointLastHealthy, &out.ControlPlaneEndpointLastHealthy
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1alpha3.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3ClusterStatus.
@@ -548,7 +798,7 @@ func (in *Metal3DataClaimStatus) DeepCopyInto(out *Metal3DataClaimStatus) {
 	*out = *in
 	if in.RenderedData != nil {
 		in, out := &in.RenderedData, &out.RenderedData
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.ErrorMessage != nil {
@@ -605,12 +855,12 @@ func (in *Metal3DataSpec) DeepCopyInto(out *Metal3DataSpec) {
 	*out = *in
 	if in.MetaData != nil {
 		in, out := &in.MetaData, &out.MetaData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	if in.NetworkData != nil {
 		in, out := &in.NetworkData, &out.NetworkData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	out.Claim = in.Claim
@@ -635,6 +885,13 @@ func (in *Metal3DataStatus) DeepCopyInto(out *Metal3DataStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1alpha3.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3DataStatus.
@@ -827,23 +1084,52 @@ func (in *Metal3MachineSpec) DeepCopyInto(out *Metal3MachineSpec) {
 	in.Image.DeepCopyInto(&out.Image)
 	if in.UserData != nil {
 		in, out := &in.UserData, &out.UserData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	in.HostSelector.DeepCopyInto(&out.HostSelector)
+	if in.HostScoring != nil {
+		in, out := &in.HostScoring, &out.HostScoring
+		*out = new(HostScoring)
+		**out = **in
+	}
+	if in.HardwareRequirements != nil {
+		in, out := &in.HardwareRequirements, &out.HardwareRequirements
+		*out = new(HardwareRequirements)
+		**out = **in
+	}
+	if in.AntiAffinity != nil {
+		in, out := &in.AntiAffinity, &out.AntiAffinity
+		*out = new(HostAntiAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeTaints != nil {
+		in, out := &in.NodeTaints, &out.NodeTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.DataTemplate != nil {
 		in, out := &in.DataTemplate, &out.DataTemplate
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.MetaData != nil {
 		in, out := &in.MetaData, &out.MetaData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	if in.NetworkData != nil {
 		in, out := &in.NetworkData, &out.NetworkData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 }
@@ -882,24 +1168,41 @@ func (in *Metal3MachineStatus) DeepCopyInto(out *Metal3MachineStatus) {
 	}
 	if in.UserData != nil {
 		in, out := &in.UserData, &out.UserData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	if in.RenderedData != nil {
 		in, out := &in.RenderedData, &out.RenderedData
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.MetaData != nil {
 		in, out := &in.MetaData, &out.MetaData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	if in.NetworkData != nil {
 		in, out := &in.NetworkData, &out.NetworkData
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
+	if in.ImageUpgrade != nil {
+		in, out := &in.ImageUpgrade, &out.ImageUpgrade
+		*out = new(ImageUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostSelection != nil {
+		in, out := &in.HostSelection, &out.HostSelection
+		*out = new(HostSelectionStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1alpha3.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3MachineStatus.
@@ -1002,6 +1305,194 @@ func (in *Metal3MachineTemplateSpec) DeepCopy() *Metal3MachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3Remediation) DeepCopyInto(out *Metal3Remediation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3Remediation.
+func (in *Metal3Remediation) DeepCopy() *Metal3Remediation {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3Remediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3Remediation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationList) DeepCopyInto(out *Metal3RemediationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Metal3Remediation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationList.
+func (in *Metal3RemediationList) DeepCopy() *Metal3RemediationList {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3RemediationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationSpec) DeepCopyInto(out *Metal3RemediationSpec) {
+	*out = *in
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RemediationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSpec.
+func (in *Metal3RemediationSpec) DeepCopy() *Metal3RemediationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationStatus) DeepCopyInto(out *Metal3RemediationStatus) {
+	*out = *in
+	if in.LastRemediated != nil {
+		in, out := &in.LastRemediated, &out.LastRemediated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationStatus.
+func (in *Metal3RemediationStatus) DeepCopy() *Metal3RemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationTemplate) DeepCopyInto(out *Metal3RemediationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationTemplate.
+func (in *Metal3RemediationTemplate) DeepCopy() *Metal3RemediationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3RemediationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationTemplateList) DeepCopyInto(out *Metal3RemediationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Metal3RemediationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationTemplateList.
+func (in *Metal3RemediationTemplateList) DeepCopy() *Metal3RemediationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3RemediationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationTemplateResource) DeepCopyInto(out *Metal3RemediationTemplateResource) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationTemplateResource.
+func (in *Metal3RemediationTemplateResource) DeepCopy() *Metal3RemediationTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationTemplateSpec) DeepCopyInto(out *Metal3RemediationTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationTemplateSpec.
+func (in *Metal3RemediationTemplateSpec) DeepCopy() *Metal3RemediationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkData) DeepCopyInto(out *NetworkData) {
 	*out = *in
@@ -1442,3 +1933,64 @@ func (in *NetworkLinkEthernetMac) DeepCopy() *NetworkLinkEthernetMac {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDeletionPolicy) DeepCopyInto(out *NodeDeletionPolicy) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDeletionPolicy.
+func (in *NodeDeletionPolicy) DeepCopy() *NodeDeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationStrategy) DeepCopyInto(out *RemediationStrategy) {
+	*out = *in
+	if in.RetryLimit != nil {
+		in, out := &in.RetryLimit, &out.RetryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStrategy.
+func (in *RemediationStrategy) DeepCopy() *RemediationStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedHostAntiAffinityTerm) DeepCopyInto(out *WeightedHostAntiAffinityTerm) {
+	*out = *in
+	in.Term.DeepCopyInto(&out.Term)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedHostAntiAffinityTerm.
+func (in *WeightedHostAntiAffinityTerm) DeepCopy() *WeightedHostAntiAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedHostAntiAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
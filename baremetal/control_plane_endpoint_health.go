@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// DialFunc opens a connection to the address on the named network, as
+// net.Dialer.DialContext does. It is used to probe the control plane
+// endpoint.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// CheckControlPlaneEndpoint probes the control plane endpoint, once the
+// control plane of the cluster is initialized, and reports the result in the
+// ControlPlaneEndpointHealthy condition of the Metal3Cluster. It asks for
+// requeue after the period of the health check, so that the endpoint is
+// probed periodically.
+func (s *ClusterManager) CheckControlPlaneEndpoint(ctx context.Context) error {
+	healthCheck := s.Metal3Cluster.Spec.ControlPlaneEndpointHealthCheck
+	if healthCheck == nil {
+		conditions.Delete(s.Metal3Cluster, capm3.ControlPlaneEndpointHealthyCondition)
+		return nil
+	}
+
+	period := capm3.DefaultControlPlaneEndpointHealthCheckPeriod
+	if healthCheck.Period != nil {
+		period = healthCheck.Period.Duration
+	}
+	nextCheck := &RequeueAfterError{RequeueAfter: period,
+		Reason: "checking the control plane endpoint",
+	}
+
+	if !s.Cluster.Status.ControlPlaneInitialized {
+		conditions.MarkFalse(s.Metal3Cluster,
+			capm3.ControlPlaneEndpointHealthyCondition,
+			capm3.WaitingForControlPlaneInitializedReason,
+			capi.ConditionSeverityInfo, "",
+		)
+		// The Cluster is watched, it is reconciled once initialized
+		return nil
+	}
+
+	timeout := capm3.DefaultControlPlaneEndpointHealthCheckTimeout
+	if healthCheck.Timeout != nil {
+		timeout = healthCheck.Timeout.Duration
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := s.probeControlPlaneEndpoint(probeCtx, healthCheck.Mode); err != nil {
+		s.Log.Info("Control plane endpoint unreachable", "error", err.Error())
+		conditions.MarkFalse(s.Metal3Cluster,
+			capm3.ControlPlaneEndpointHealthyCondition,
+			capm3.ControlPlaneEndpointUnreachableReason,
+			capi.ConditionSeverityWarning, "%s", err.Error(),
+		)
+		return nextCheck
+	}
+
+	conditions.MarkTrue(s.Metal3Cluster, capm3.ControlPlaneEndpointHealthyCondition)
+	now := metav1.Now()
+	s.Metal3Cluster.Status.ControlPlaneEndpointLastHealthy = &now
+	return nextCheck
+}
+
+// probeControlPlaneEndpoint opens a TCP connection to the control plane
+// endpoint, or gets its /healthz path over HTTPS.
+func (s *ClusterManager) probeControlPlaneEndpoint(ctx context.Context,
+	mode capm3.ControlPlaneEndpointHealthCheckMode,
+) error {
+	endpoint := s.Metal3Cluster.Spec.ControlPlaneEndpoint
+	address := net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+
+	switch mode {
+	case capm3.ControlPlaneEndpointHealthCheckModeTCP:
+		conn, err := s.dial(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case capm3.ControlPlaneEndpointHealthCheckModeHTTPS:
+		return s.getHealthz(ctx, address, endpoint.Host)
+	default:
+		return errors.Errorf("unknown control plane endpoint health check mode %s",
+			mode,
+		)
+	}
+}
+
+// getHealthz gets the /healthz path of the control plane endpoint, verifying
+// its certificate with the CA of the cluster.
+func (s *ClusterManager) getHealthz(ctx context.Context, address, serverName string,
+) error {
+	caSecret, err := secret.Get(ctx, s.client, util.ObjectKey(s.Cluster),
+		secret.ClusterCA,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the CA of the cluster")
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caSecret.Data[secret.TLSCrtDataName]) {
+		return errors.New("failed to parse the CA of the cluster")
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: s.dial,
+			TLSClientConfig: &tls.Config{
+				RootCAs:    caPool,
+				ServerName: serverName,
+			},
+			DisableKeepAlives: true,
+		},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("https://%s/healthz", address), nil,
+	)
+	if err != nil {
+		return err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, 256))
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("/healthz returned %d: %s", response.StatusCode,
+			string(body),
+		)
+	}
+	return nil
+}
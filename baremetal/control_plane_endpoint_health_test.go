@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Control plane endpoint health check", func() {
+
+	type testCaseCheckControlPlaneEndpoint struct {
+		HealthCheck    *capm3.ControlPlaneEndpointHealthCheck
+		NotInitialized bool
+		DialError      bool
+		NoCASecret     bool
+		StatusCode     int
+		ExpectRequeue  bool
+		ExpectedReason string
+		ExpectHealthy  bool
+	}
+
+	DescribeTable("Test CheckControlPlaneEndpoint",
+		func(tc testCaseCheckControlPlaneEndpoint) {
+			server := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/healthz" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.WriteHeader(tc.StatusCode)
+				},
+			))
+			defer server.Close()
+
+			objects := []runtime.Object{}
+			if !tc.NoCASecret {
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      secret.Name(clusterName, secret.ClusterCA),
+						Namespace: namespaceName,
+					},
+					Data: map[string][]byte{
+						secret.TLSCrtDataName: pem.EncodeToMemory(&pem.Block{
+							Type:  "CERTIFICATE",
+							Bytes: server.Certificate().Raw,
+						}),
+					},
+				})
+			}
+			cluster := newCluster(clusterName)
+			cluster.Status.ControlPlaneInitialized = !tc.NotInitialized
+			bmCluster := newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+				&capm3.Metal3ClusterSpec{
+					ControlPlaneEndpoint: capm3.APIEndpoint{
+						Host: "127.0.0.1",
+						Port: 6443,
+					},
+					ControlPlaneEndpointHealthCheck: tc.HealthCheck,
+				}, nil,
+			)
+
+			// The endpoint is redirected to the local server
+			dialed := ""
+			clusterMgr := &ClusterManager{
+				client: fakeclient.NewFakeClientWithScheme(setupScheme(),
+					objects...,
+				),
+				dial: func(ctx context.Context, network, address string,
+				) (net.Conn, error) {
+					dialed = address
+					if tc.DialError {
+						return nil, errors.New("connection refused")
+					}
+					return (&net.Dialer{}).DialContext(ctx, network,
+						server.Listener.Addr().String(),
+					)
+				},
+				Metal3Cluster: bmCluster,
+				Cluster:       cluster,
+				Log:           klogr.New(),
+			}
+
+			err := clusterMgr.CheckControlPlaneEndpoint(context.TODO())
+			if tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				requeueErr, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+				Expect(requeueErr.GetRequeueAfter()).To(Equal(
+					capm3.DefaultControlPlaneEndpointHealthCheckPeriod,
+				))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			condition := conditions.Get(bmCluster,
+				capm3.ControlPlaneEndpointHealthyCondition,
+			)
+			switch {
+			case tc.HealthCheck == nil:
+				Expect(condition).To(BeNil())
+			case tc.ExpectHealthy:
+				Expect(conditions.IsTrue(bmCluster,
+					capm3.ControlPlaneEndpointHealthyCondition,
+				)).To(BeTrue())
+			default:
+				Expect(conditions.IsFalse(bmCluster,
+					capm3.ControlPlaneEndpointHealthyCondition,
+				)).To(BeTrue())
+				Expect(condition.Reason).To(Equal(tc.ExpectedReason))
+			}
+			if tc.ExpectHealthy {
+				Expect(dialed).To(Equal("127.0.0.1:6443"))
+				Expect(bmCluster.Status.ControlPlaneEndpointLastHealthy).NotTo(BeNil())
+			} else {
+				Expect(bmCluster.Status.ControlPlaneEndpointLastHealthy).To(BeNil())
+			}
+		},
+		Entry("No health check", testCaseCheckControlPlaneEndpoint{}),
+		Entry("Control plane not initialized", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode: capm3.ControlPlaneEndpointHealthCheckModeTCP,
+			},
+			NotInitialized: true,
+			ExpectedReason: capm3.WaitingForControlPlaneInitializedReason,
+		}),
+		Entry("TCP", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode: capm3.ControlPlaneEndpointHealthCheckModeTCP,
+			},
+			ExpectRequeue: true,
+			ExpectHealthy: true,
+		}),
+		Entry("TCP, connection refused", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode: capm3.ControlPlaneEndpointHealthCheckModeTCP,
+			},
+			DialError:      true,
+			ExpectRequeue:  true,
+			ExpectedReason: capm3.ControlPlaneEndpointUnreachableReason,
+		}),
+		Entry("HTTPS", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode:    capm3.ControlPlaneEndpointHealthCheckModeHTTPS,
+				Timeout: &metav1.Duration{Duration: 10 * time.Second},
+			},
+			StatusCode:    http.StatusOK,
+			ExpectRequeue: true,
+			ExpectHealthy: true,
+		}),
+		Entry("HTTPS, unhealthy", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode: capm3.ControlPlaneEndpointHealthCheckModeHTTPS,
+			},
+			StatusCode:     http.StatusInternalServerError,
+			ExpectRequeue:  true,
+			ExpectedReason: capm3.ControlPlaneEndpointUnreachableReason,
+		}),
+		Entry("HTTPS, CA not found", testCaseCheckControlPlaneEndpoint{
+			HealthCheck: &capm3.ControlPlaneEndpointHealthCheck{
+				Mode: capm3.ControlPlaneEndpointHealthCheckModeHTTPS,
+			},
+			NoCASecret:     true,
+			StatusCode:     http.StatusOK,
+			ExpectRequeue:  true,
+			ExpectedReason: capm3.ControlPlaneEndpointUnreachableReason,
+		}),
+	)
+})
@@ -0,0 +1,228 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ControlPlaneVIPManifestKey is the metadata key of the base64 encoded
+	// static pod manifest announcing the control plane endpoint VIP.
+	ControlPlaneVIPManifestKey = "controlPlaneVIPManifest"
+
+	// DefaultKubeVIPImage is the default image of the kube-vip static pod.
+	DefaultKubeVIPImage = "ghcr.io/kube-vip/kube-vip:v0.3.8"
+	// DefaultKeepalivedImage is the default image of the keepalived static
+	// pod.
+	DefaultKeepalivedImage = "quay.io/metal3-io/keepalived"
+
+	// kubeconfigPath is the path of the kubeconfig of the control plane nodes
+	// used by kube-vip for its leader election.
+	kubeconfigPath = "/etc/kubernetes/admin.conf"
+)
+
+// getControlPlaneVIPCluster returns the Metal3Cluster of the machine if it is
+// a control plane machine and the Metal3Cluster has a control plane VIP,
+// otherwise nil. It asks for requeue until the host of the control plane
+// endpoint is set.
+func (m *DataManager) getControlPlaneVIPCluster(ctx context.Context,
+	machine *capi.Machine,
+) (*capm3.Metal3Cluster, error) {
+	if !util.IsControlPlaneMachine(machine) {
+		return nil, nil
+	}
+
+	cluster, err := util.GetClusterByName(ctx, m.client, machine.Namespace,
+		machine.Spec.ClusterName,
+	)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Cluster not found",
+			}
+		}
+		return nil, errors.Wrapf(err, "failed to get the Cluster of Machine %s",
+			machine.Name,
+		)
+	}
+	infraRef := cluster.Spec.InfrastructureRef
+	if infraRef == nil || infraRef.Kind != "Metal3Cluster" {
+		return nil, nil
+	}
+
+	m3c := &capm3.Metal3Cluster{}
+	key := client.ObjectKey{Name: infraRef.Name, Namespace: cluster.Namespace}
+	if err := m.client.Get(ctx, key, m3c); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Metal3Cluster not found",
+			}
+		}
+		return nil, err
+	}
+	if m3c.Spec.ControlPlaneVIP == nil {
+		return nil, nil
+	}
+	if m3c.Spec.ControlPlaneEndpoint.Host == "" {
+		return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the control plane endpoint",
+		}
+	}
+	return m3c, nil
+}
+
+// renderControlPlaneVIPManifest renders the base64 encoded static pod
+// manifest announcing the control plane endpoint VIP of the Metal3Cluster.
+func renderControlPlaneVIPManifest(m3c *capm3.Metal3Cluster) (string, error) {
+	vip := m3c.Spec.ControlPlaneVIP
+	endpoint := m3c.Spec.ControlPlaneEndpoint
+
+	var pod *corev1.Pod
+	switch vip.Mode {
+	case capm3.ControlPlaneVIPModeKubeVIP:
+		pod = kubeVIPPod(vip, endpoint)
+	case capm3.ControlPlaneVIPModeKeepalived:
+		pod = keepalivedPod(vip, endpoint)
+	default:
+		return "", errors.Errorf("unknown control plane VIP mode %s", vip.Mode)
+	}
+
+	manifest, err := yaml.Marshal(pod)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(manifest), nil
+}
+
+// kubeVIPPod returns the kube-vip static pod announcing the VIP with ARP.
+func kubeVIPPod(vip *capm3.ControlPlaneVIP, endpoint capm3.APIEndpoint,
+) *corev1.Pod {
+	image := vip.Image
+	if image == "" {
+		image = DefaultKubeVIPImage
+	}
+	pod := staticPod("kube-vip", corev1.Container{
+		Name:  "kube-vip",
+		Image: image,
+		Args:  []string{"manager"},
+		Env: []corev1.EnvVar{
+			{Name: "vip_arp", Value: "true"},
+			{Name: "vip_interface", Value: vip.Interface},
+			{Name: "vip_address", Value: endpoint.Host},
+			{Name: "port", Value: strconv.Itoa(endpoint.Port)},
+			{Name: "vip_cidr", Value: vipCIDR(endpoint.Host)},
+			{Name: "cp_enable", Value: "true"},
+			{Name: "cp_namespace", Value: "kube-system"},
+			{Name: "vip_leaderelection", Value: "true"},
+			{Name: "vip_leaseduration", Value: "5"},
+			{Name: "vip_renewdeadline", Value: "3"},
+			{Name: "vip_retryperiod", Value: "1"},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "kubeconfig", MountPath: kubeconfigPath},
+		},
+	})
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "kubeconfig",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: kubeconfigPath},
+			},
+		},
+	}
+	return pod
+}
+
+// vipCIDR returns the prefix length of the VIP, 128 for an IPv6 address and
+// 32 otherwise.
+func vipCIDR(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "128"
+	}
+	return "32"
+}
+
+// keepalivedPod returns the keepalived static pod announcing the VIP with
+// VRRP. The configuration is written by the command of the container, since
+// a static pod can not mount a ConfigMap.
+func keepalivedPod(vip *capm3.ControlPlaneVIP, endpoint capm3.APIEndpoint,
+) *corev1.Pod {
+	image := vip.Image
+	if image == "" {
+		image = DefaultKeepalivedImage
+	}
+	config := fmt.Sprintf(`vrrp_instance VI_1 {
+    state BACKUP
+    interface %s
+    virtual_router_id %d
+    priority 100
+    advert_int 1
+    virtual_ipaddress {
+        %s
+    }
+}
+`, vip.Interface, vip.VirtualRouterID, endpoint.Host)
+
+	return staticPod("keepalived", corev1.Container{
+		Name:    "keepalived",
+		Image:   image,
+		Command: []string{"/bin/sh", "-c"},
+		Args: []string{"cat > /tmp/keepalived.conf <<EOF\n" + config +
+			"EOF\nexec keepalived --dont-fork --log-console --vrrp " +
+			"--use-file /tmp/keepalived.conf",
+		},
+	})
+}
+
+// staticPod returns a static pod in the host network of the node, running
+// the container with the capabilities needed to announce the VIP.
+func staticPod(name string, container corev1.Container) *corev1.Pod {
+	container.SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_ADMIN", "NET_BROADCAST", "NET_RAW"},
+		},
+	}
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+		},
+		Spec: corev1.PodSpec{
+			Containers:  []corev1.Container{container},
+			HostNetwork: true,
+		},
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Control plane VIP", func() {
+
+	clusterWithVIP := func(vip *capm3.ControlPlaneVIP, host string,
+	) *capm3.Metal3Cluster {
+		return newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+			&capm3.Metal3ClusterSpec{
+				ControlPlaneEndpoint: capm3.APIEndpoint{Host: host, Port: 6443},
+				ControlPlaneVIP:      vip,
+			}, nil,
+		)
+	}
+
+	decodeManifest := func(manifest string) *corev1.Pod {
+		decoded, err := base64.StdEncoding.DecodeString(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		pod := &corev1.Pod{}
+		Expect(yaml.Unmarshal(decoded, pod)).To(Succeed())
+		return pod
+	}
+
+	type testCaseRenderControlPlaneVIP struct {
+		VIP           *capm3.ControlPlaneVIP
+		ExpectError   bool
+		ExpectedName  string
+		ExpectedImage string
+		ExpectedArg   string
+	}
+
+	DescribeTable("Test renderControlPlaneVIPManifest",
+		func(tc testCaseRenderControlPlaneVIP) {
+			manifest, err := renderControlPlaneVIPManifest(
+				clusterWithVIP(tc.VIP, "192.168.111.249"),
+			)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			pod := decodeManifest(manifest)
+			Expect(pod.Name).To(Equal(tc.ExpectedName))
+			Expect(pod.Namespace).To(Equal("kube-system"))
+			Expect(pod.Spec.HostNetwork).To(BeTrue())
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+			Expect(pod.Spec.Containers[0].Image).To(Equal(tc.ExpectedImage))
+			Expect(pod.Spec.Containers[0].Args).To(HaveLen(1))
+			Expect(pod.Spec.Containers[0].Args[0]).To(
+				ContainSubstring(tc.ExpectedArg),
+			)
+		},
+		Entry("kube-vip", testCaseRenderControlPlaneVIP{
+			VIP: &capm3.ControlPlaneVIP{
+				Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+				Interface: "enp2s0",
+			},
+			ExpectedName:  "kube-vip",
+			ExpectedImage: DefaultKubeVIPImage,
+			ExpectedArg:   "manager",
+		}),
+		Entry("keepalived with an image", testCaseRenderControlPlaneVIP{
+			VIP: &capm3.ControlPlaneVIP{
+				Mode:            capm3.ControlPlaneVIPModeKeepalived,
+				Interface:       "enp2s0",
+				VirtualRouterID: 7,
+				Image:           "example.com/keepalived:v2",
+			},
+			ExpectedName:  "keepalived",
+			ExpectedImage: "example.com/keepalived:v2",
+			ExpectedArg: "    interface enp2s0\n    virtual_router_id 7\n" +
+				"    priority 100\n    advert_int 1\n    virtual_ipaddress {\n" +
+				"        192.168.111.249\n",
+		}),
+		Entry("Unknown mode", testCaseRenderControlPlaneVIP{
+			VIP: &capm3.ControlPlaneVIP{
+				Mode:      "haproxy",
+				Interface: "enp2s0",
+			},
+			ExpectError: true,
+		}),
+	)
+
+	It("Sets the VIP and the port of kube-vip", func() {
+		manifest, err := renderControlPlaneVIPManifest(clusterWithVIP(
+			&capm3.ControlPlaneVIP{
+				Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+				Interface: "enp2s0",
+			}, "192.168.111.249",
+		))
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := decodeManifest(manifest)
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "vip_address", Value: "192.168.111.249"},
+		))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "vip_interface", Value: "enp2s0"},
+		))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "port", Value: "6443"},
+		))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "vip_cidr", Value: "32"},
+		))
+	})
+
+	It("Sets the prefix length of an IPv6 VIP for kube-vip", func() {
+		manifest, err := renderControlPlaneVIPManifest(clusterWithVIP(
+			&capm3.ControlPlaneVIP{
+				Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+				Interface: "enp2s0",
+			}, "fd00:1::249",
+		))
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := decodeManifest(manifest)
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "vip_address", Value: "fd00:1::249"},
+		))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "vip_cidr", Value: "128"},
+		))
+	})
+
+	type testCaseGetControlPlaneVIPCluster struct {
+		ControlPlane    bool
+		Metal3Cluster   *capm3.Metal3Cluster
+		NoCluster       bool
+		ExpectRequeue   bool
+		ExpectedCluster bool
+	}
+
+	DescribeTable("Test getControlPlaneVIPCluster",
+		func(tc testCaseGetControlPlaneVIPCluster) {
+			cluster := newCluster(clusterName)
+			cluster.Spec.InfrastructureRef.Kind = "Metal3Cluster"
+			objects := []runtime.Object{}
+			if !tc.NoCluster {
+				objects = append(objects, cluster)
+			}
+			if tc.Metal3Cluster != nil {
+				objects = append(objects, tc.Metal3Cluster)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			dataMgr, err := NewDataManager(c, nil, &capm3.Metal3Data{},
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			machine := &capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine1",
+					Namespace: namespaceName,
+					Labels:    map[string]string{},
+				},
+				Spec: capi.MachineSpec{ClusterName: clusterName},
+			}
+			if tc.ControlPlane {
+				machine.Labels[capi.MachineControlPlaneLabelName] = ""
+			}
+
+			m3c, err := dataMgr.getControlPlaneVIPCluster(context.TODO(), machine)
+			if tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			if tc.ExpectedCluster {
+				Expect(m3c).NotTo(BeNil())
+				Expect(m3c.Name).To(Equal(metal3ClusterName))
+			} else {
+				Expect(m3c).To(BeNil())
+			}
+		},
+		Entry("Worker machine", testCaseGetControlPlaneVIPCluster{
+			Metal3Cluster: clusterWithVIP(&capm3.ControlPlaneVIP{
+				Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+				Interface: "enp2s0",
+			}, "192.168.111.249"),
+		}),
+		Entry("Control plane machine", testCaseGetControlPlaneVIPCluster{
+			ControlPlane: true,
+			Metal3Cluster: clusterWithVIP(&capm3.ControlPlaneVIP{
+				Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+				Interface: "enp2s0",
+			}, "192.168.111.249"),
+			ExpectedCluster: true,
+		}),
+		Entry("Control plane machine, no VIP", testCaseGetControlPlaneVIPCluster{
+			ControlPlane:  true,
+			Metal3Cluster: clusterWithVIP(nil, "192.168.111.249"),
+		}),
+		Entry("Control plane machine, endpoint not allocated",
+			testCaseGetControlPlaneVIPCluster{
+				ControlPlane: true,
+				Metal3Cluster: clusterWithVIP(&capm3.ControlPlaneVIP{
+					Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+					Interface: "enp2s0",
+				}, ""),
+				ExpectRequeue: true,
+			},
+		),
+		Entry("Control plane machine, Cluster not found",
+			testCaseGetControlPlaneVIPCluster{
+				ControlPlane:  true,
+				NoCluster:     true,
+				ExpectRequeue: true,
+			},
+		),
+		Entry("Control plane machine, Metal3Cluster not found",
+			testCaseGetControlPlaneVIPCluster{
+				ControlPlane:  true,
+				ExpectRequeue: true,
+			},
+		),
+	)
+
+	It("Renders the manifest in the metadata", func() {
+		m3c := clusterWithVIP(&capm3.ControlPlaneVIP{
+			Mode:      capm3.ControlPlaneVIPModeKubeVIP,
+			Interface: "enp2s0",
+		}, "192.168.111.249")
+		m3dt := &capm3.Metal3DataTemplate{
+			Spec: capm3.Metal3DataTemplateSpec{
+				MetaData: &capm3.MetaData{
+					Strings: []capm3.MetaDataString{
+						{Key: "abc", Value: "def"},
+					},
+				},
+			},
+		}
+
+		resultBytes, err := renderMetaData(&capm3.Metal3Data{}, m3dt,
+			&capm3.Metal3Machine{}, &capi.Machine{}, nil, nil, m3c,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		metadata := map[string]string{}
+		Expect(yaml.Unmarshal(resultBytes, &metadata)).To(Succeed())
+		Expect(metadata["abc"]).To(Equal("def"))
+		Expect(decodeManifest(metadata[ControlPlaneVIPManifestKey]).Name).To(
+			Equal("kube-vip"),
+		)
+	})
+})
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the events emitted on the Metal3Machines, BareMetalHosts,
+// Metal3Datas and Metal3Remediations.
+const (
+	// HostChosenReason is used when a host is chosen for a Metal3Machine.
+	HostChosenReason = "HostChosen"
+	// NoHostAvailableReason is used when no host can be chosen for a
+	// Metal3Machine.
+	NoHostAvailableReason = "NoHostAvailable"
+	// AssociatedReason is used when a Metal3Machine is associated with a host.
+	AssociatedReason = "Associated"
+	// ProvisioningStartedReason is used when the image is set on the host.
+	ProvisioningStartedReason = "ProvisioningStarted"
+	// DeprovisioningReason is used when the host is released or deprovisioned
+	// for an image upgrade.
+	DeprovisioningReason = "Deprovisioning"
+	// PausedReason is used when the host is paused.
+	PausedReason = "Paused"
+	// UnpausedReason is used when the host is unpaused.
+	UnpausedReason = "Unpaused"
+	// ProviderIDSetReason is used when the provider ID of a Metal3Machine is
+	// set.
+	ProviderIDSetReason = "ProviderIDSet"
+	// SecretRenderingFailedReason is used when the secrets of a Metal3Data
+	// could not be rendered.
+	SecretRenderingFailedReason = "SecretRenderingFailed"
+	// NodeDeletedReason is used when the node of a provisioned Metal3Machine
+	// is deleted from the target cluster.
+	NodeDeletedReason = "NodeDeleted"
+	// NodeDeletionRebootReason is used when the host is rebooted after the
+	// deletion of its node.
+	NodeDeletionRebootReason = "NodeDeletionReboot"
+	// NodeDeletionReplaceReason is used when the Machine is deleted after the
+	// deletion of its node.
+	NodeDeletionReplaceReason = "NodeDeletionReplace"
+	// NodeReuseFailedReason is used when the hosts released for reuse by the
+	// owner of a Metal3Machine can not be reused.
+	NodeReuseFailedReason = "NodeReuseFailed"
+	// NodeReuseWaitingReason is used when a Metal3Machine waits for a host
+	// released for reuse by its owner.
+	NodeReuseWaitingReason = "NodeReuseWaiting"
+	// RemediationRebootReason is used when the host of an unhealthy Machine
+	// is rebooted by a Metal3Remediation.
+	RemediationRebootReason = "RemediationReboot"
+	// RemediationMachineDeletedReason is used when an unhealthy Machine is
+	// deleted once the reboots of its Metal3Remediation are exhausted.
+	RemediationMachineDeletedReason = "RemediationMachineDeleted"
+)
+
+// Reasons for which a host is not a candidate for a Metal3Machine.
+const (
+	hostRejectedConsumed             = "consumed"
+	hostRejectedDeleting             = "deleting"
+	hostRejectedError                = "error"
+	hostRejectedProvisioningState    = "provisioningState"
+	hostRejectedPaused               = "paused"
+	hostRejectedUnhealthy            = "unhealthy"
+	hostRejectedLabelMismatch        = "labelMismatch"
+	hostRejectedFailureDomain        = "failureDomain"
+	hostRejectedHardwareRequirements = "hardwareRequirements"
+	hostRejectedAntiAffinity         = "antiAffinity"
+	hostRejectedNodeReuse            = "nodeReuse"
+)
+
+// recordEvent emits an event on the object, unless the recorder is nil.
+func recordEvent(recorder record.EventRecorder, object runtime.Object,
+	eventType, reason, messageFmt string, args ...interface{},
+) {
+	if recorder == nil || object == nil {
+		return
+	}
+	recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// rejectionSummary formats the number of rejected hosts per reason, sorted
+// by reason, for example "consumed: 2, paused: 1".
+func rejectionSummary(rejections map[string]int) string {
+	reasons := []string{}
+	for reason, count := range rejections {
+		if count > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s: %d", reason, count))
+		}
+	}
+	if len(reasons) == 0 {
+		return "none"
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ", ")
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Events", func() {
+
+	DescribeTable("Test rejectionSummary",
+		func(rejections map[string]int, expected string) {
+			Expect(rejectionSummary(rejections)).To(Equal(expected))
+		},
+		Entry("No rejection", map[string]int{}, "none"),
+		Entry("Rejections sorted by reason", map[string]int{
+			hostRejectedPaused:       1,
+			hostRejectedConsumed:     2,
+			hostRejectedAntiAffinity: 0,
+		}, "consumed: 2, paused: 1"),
+	)
+
+	It("Does not emit events without a recorder", func() {
+		recordEvent(nil, &capm3.Metal3Machine{}, corev1.EventTypeNormal,
+			ProviderIDSetReason, "Provider ID set to %s", "abc",
+		)
+	})
+
+	It("Emits an event when the provider ID changes", func() {
+		recorder := record.NewFakeRecorder(10)
+		m3machine := &capm3.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "m3m", Namespace: "myns"},
+		}
+		machineMgr, err := NewMachineManager(nil, recorder, nil, nil,
+			&capi.Machine{}, m3machine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		machineMgr.SetProviderID("metal3://abc")
+		machineMgr.SetProviderID("metal3://abc")
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(Equal(
+			"Normal ProviderIDSet Provider ID set to metal3://abc",
+		))
+	})
+
+	It("Emits an event with the rejected hosts when choosing a host", func() {
+		recorder := record.NewFakeRecorder(10)
+		readyHost := newBareMetalHost("readyHost", nil, bmh.StateReady, nil,
+			false, false,
+		)
+		readyHost.Status.Provisioning.State = bmh.StateReady
+		pausedHost := readyHost.DeepCopy()
+		pausedHost.Name = "pausedHost"
+		pausedHost.Annotations = map[string]string{bmh.PausedAnnotation: ""}
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), readyHost,
+			pausedHost,
+		)
+		m3machine := &capm3.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "m3m", Namespace: "myns"},
+		}
+		machineMgr, err := NewMachineManager(c, recorder, nil, nil,
+			&capi.Machine{}, m3machine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		host, _, err := machineMgr.chooseHost(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(host).NotTo(BeNil())
+		Expect(host.Name).To(Equal("readyHost"))
+		Expect(<-recorder.Events).To(Equal("Normal HostChosen Chose host " +
+			"readyHost out of 1 candidates, rejected hosts: paused: 1",
+		))
+	})
+
+	It("Reports the rejected hosts when no host is available", func() {
+		recorder := record.NewFakeRecorder(10)
+		pausedHost := newBareMetalHost("pausedHost", nil, bmh.StateReady, nil,
+			false, false,
+		)
+		pausedHost.Status.Provisioning.State = bmh.StateReady
+		pausedHost.Annotations = map[string]string{bmh.PausedAnnotation: ""}
+		consumedHost := pausedHost.DeepCopy()
+		consumedHost.Name = "consumedHost"
+		consumedHost.Annotations = nil
+		consumedHost.Spec.ConsumerRef = consumerRefSome()
+		inspectingHost := consumedHost.DeepCopy()
+		inspectingHost.Name = "inspectingHost"
+		inspectingHost.Spec.ConsumerRef = nil
+		inspectingHost.Status.Provisioning.State = bmh.StateInspecting
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), pausedHost,
+			consumedHost, inspectingHost,
+		)
+		m3machine := &capm3.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "m3m", Namespace: "myns"},
+		}
+		machineMgr, err := NewMachineManager(c, recorder, nil, nil,
+			&capi.Machine{}, m3machine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		host, _, err := machineMgr.chooseHost(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(host).To(BeNil())
+		Expect(m3machine.Status.HostSelection).To(Equal(
+			&capm3.HostSelectionStatus{
+				Hosts: 3,
+				Rejected: capm3.HostRejections{
+					Consumed:          1,
+					ProvisioningState: 1,
+					Paused:            1,
+				},
+			},
+		))
+		Expect(<-recorder.Events).To(Equal("Warning NoHostAvailable No " +
+			"available host out of 3, rejected hosts: consumed: 1, paused: 1, " +
+			"provisioningState: 1",
+		))
+	})
+})
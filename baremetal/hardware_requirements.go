@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+)
+
+// minRootDiskSize is the size under which ironic does not consider a disk
+// for the root device when no root device hints are given.
+const minRootDiskSize = 4 * bmh.GibiByte
+
+// checkHardwareRequirements returns an error describing the first requirement
+// the host does not fulfill, or nil if it fulfills all of them.
+func checkHardwareRequirements(host *bmh.BareMetalHost,
+	requirements *capm3.HardwareRequirements,
+) error {
+	if requirements == nil {
+		return nil
+	}
+	details := host.Status.HardwareDetails
+	if details == nil {
+		return errors.New("no hardware details available")
+	}
+
+	if requirements.CPUArchitecture != "" &&
+		details.CPU.Arch != requirements.CPUArchitecture {
+		return errors.Errorf("CPU architecture is %s, %s required",
+			details.CPU.Arch, requirements.CPUArchitecture,
+		)
+	}
+	if details.CPU.Count < requirements.MinCPUCount {
+		return errors.Errorf("%d CPUs, %d required",
+			details.CPU.Count, requirements.MinCPUCount,
+		)
+	}
+	if details.RAMMebibytes < requirements.MinRAMMebibytes {
+		return errors.Errorf("%d MiB of RAM, %d required",
+			details.RAMMebibytes, requirements.MinRAMMebibytes,
+		)
+	}
+
+	if requirements.MinRootDiskGibibytes > 0 {
+		rootDisk := findRootDisk(host)
+		if rootDisk == nil {
+			return errors.New("no root disk found")
+		}
+		minSize := bmh.Capacity(requirements.MinRootDiskGibibytes) * bmh.GibiByte
+		if rootDisk.SizeBytes < minSize {
+			return errors.Errorf("root disk %s is %d GiB, %d required",
+				rootDisk.Name, rootDisk.SizeBytes/bmh.GibiByte,
+				requirements.MinRootDiskGibibytes,
+			)
+		}
+	}
+
+	minNICCount := requirements.MinNICCount
+	if minNICCount == 0 && requirements.MinNICSpeedGbps > 0 {
+		minNICCount = 1
+	}
+	nicCount := 0
+	for _, nic := range details.NIC {
+		if nic.SpeedGbps >= requirements.MinNICSpeedGbps {
+			nicCount++
+		}
+	}
+	if nicCount < minNICCount {
+		return errors.Errorf("%d NICs of at least %d Gbps, %d required",
+			nicCount, requirements.MinNICSpeedGbps, minNICCount,
+		)
+	}
+
+	return nil
+}
+
+// findRootDisk returns the disk the image will be written to: the one named
+// in the root device hints if any, the smallest disk big enough otherwise.
+func findRootDisk(host *bmh.BareMetalHost) *bmh.Storage {
+	disks := host.Status.HardwareDetails.Storage
+	if host.Spec.RootDeviceHints != nil &&
+		host.Spec.RootDeviceHints.DeviceName != "" {
+		for i, disk := range disks {
+			if disk.Name == host.Spec.RootDeviceHints.DeviceName {
+				return &disks[i]
+			}
+		}
+		return nil
+	}
+
+	var rootDisk *bmh.Storage
+	for i, disk := range disks {
+		if disk.SizeBytes < minRootDiskSize {
+			continue
+		}
+		if rootDisk == nil || disk.SizeBytes < rootDisk.SizeBytes {
+			rootDisk = &disks[i]
+		}
+	}
+	return rootDisk
+}
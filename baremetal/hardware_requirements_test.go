@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Hardware requirements", func() {
+
+	inspectedHost := func() *bmh.BareMetalHost {
+		return &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "host",
+				Namespace: "myns",
+			},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					RAMMebibytes: 16384,
+					CPU: bmh.CPU{
+						Arch:  "x86_64",
+						Count: 8,
+					},
+					NIC: []bmh.NIC{
+						{Name: "eth0", SpeedGbps: 1},
+						{Name: "eth1", SpeedGbps: 10},
+						{Name: "eth2", SpeedGbps: 25},
+					},
+					Storage: []bmh.Storage{
+						{Name: "/dev/sda", SizeBytes: 1 * bmh.GibiByte},
+						{Name: "/dev/sdb", SizeBytes: 500 * bmh.GibiByte},
+						{Name: "/dev/sdc", SizeBytes: 100 * bmh.GibiByte},
+					},
+				},
+			},
+		}
+	}
+
+	type testCaseCheckHardwareRequirements struct {
+		Host         *bmh.BareMetalHost
+		Requirements *capm3.HardwareRequirements
+		ExpectError  bool
+	}
+
+	DescribeTable("Test checkHardwareRequirements",
+		func(tc testCaseCheckHardwareRequirements) {
+			host := inspectedHost()
+			if tc.Host != nil {
+				host = tc.Host
+			}
+			err := checkHardwareRequirements(host, tc.Requirements)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("No requirements", testCaseCheckHardwareRequirements{}),
+		Entry("No requirements, not inspected", testCaseCheckHardwareRequirements{
+			Host: &bmh.BareMetalHost{},
+		}),
+		Entry("Not inspected", testCaseCheckHardwareRequirements{
+			Host:         &bmh.BareMetalHost{},
+			Requirements: &capm3.HardwareRequirements{},
+			ExpectError:  true,
+		}),
+		Entry("All requirements met", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinCPUCount:          8,
+				MinRAMMebibytes:      16384,
+				MinRootDiskGibibytes: 100,
+				MinNICCount:          2,
+				MinNICSpeedGbps:      10,
+				CPUArchitecture:      "x86_64",
+			},
+		}),
+		Entry("Wrong CPU architecture", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				CPUArchitecture: "aarch64",
+			},
+			ExpectError: true,
+		}),
+		Entry("Not enough CPUs", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinCPUCount: 16,
+			},
+			ExpectError: true,
+		}),
+		Entry("Not enough RAM", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinRAMMebibytes: 32768,
+			},
+			ExpectError: true,
+		}),
+		Entry("Root disk too small", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinRootDiskGibibytes: 200,
+			},
+			ExpectError: true,
+		}),
+		Entry("Not enough NICs", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinNICCount: 4,
+			},
+			ExpectError: true,
+		}),
+		Entry("Not enough fast NICs", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinNICCount:     2,
+				MinNICSpeedGbps: 25,
+			},
+			ExpectError: true,
+		}),
+		Entry("No NIC fast enough", testCaseCheckHardwareRequirements{
+			Requirements: &capm3.HardwareRequirements{
+				MinNICSpeedGbps: 40,
+			},
+			ExpectError: true,
+		}),
+	)
+
+	type testCaseFindRootDisk struct {
+		RootDeviceHints  *bmh.RootDeviceHints
+		ExpectedDiskName string
+	}
+
+	DescribeTable("Test findRootDisk",
+		func(tc testCaseFindRootDisk) {
+			host := inspectedHost()
+			host.Spec.RootDeviceHints = tc.RootDeviceHints
+			disk := findRootDisk(host)
+			if tc.ExpectedDiskName == "" {
+				Expect(disk).To(BeNil())
+				return
+			}
+			Expect(disk).NotTo(BeNil())
+			Expect(disk.Name).To(Equal(tc.ExpectedDiskName))
+		},
+		Entry("Smallest disk big enough", testCaseFindRootDisk{
+			ExpectedDiskName: "/dev/sdc",
+		}),
+		Entry("Device name hint", testCaseFindRootDisk{
+			RootDeviceHints:  &bmh.RootDeviceHints{DeviceName: "/dev/sdb"},
+			ExpectedDiskName: "/dev/sdb",
+		}),
+		Entry("Device name hint not found", testCaseFindRootDisk{
+			RootDeviceHints: &bmh.RootDeviceHints{DeviceName: "/dev/sdz"},
+		}),
+	)
+})
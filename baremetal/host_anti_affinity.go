@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyAntiAffinity removes the candidates violating a required anti-affinity
+// term of the metal3 machine, then keeps the candidates with the lowest sum of
+// the weights of the violated preferred terms. hosts is the complete list of
+// BareMetalHosts in the namespace. Only the candidates removed by a required
+// term are counted in rejections.
+func (m *MachineManager) applyAntiAffinity(ctx context.Context,
+	candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost,
+	rejections map[string]int,
+) ([]*bmh.BareMetalHost, error) {
+	antiAffinity := m.Metal3Machine.Spec.AntiAffinity
+	if antiAffinity == nil || m.Machine == nil {
+		return candidates, nil
+	}
+
+	machineLabels, err := m.consumerMachineLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, term := range antiAffinity.Required {
+		domains := m.siblingDomains(term, hosts, machineLabels)
+		allowed := []*bmh.BareMetalHost{}
+		for _, candidate := range candidates {
+			if violatesAntiAffinity(candidate, term, domains) {
+				m.Log.Info("Host violates a required anti-affinity term",
+					"host", candidate.Name, "topologyKey", term.TopologyKey,
+				)
+				rejections[hostRejectedAntiAffinity]++
+				continue
+			}
+			allowed = append(allowed, candidate)
+		}
+		candidates = allowed
+	}
+
+	if len(antiAffinity.Preferred) == 0 || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	penalties := make([]int32, len(candidates))
+	for _, weightedTerm := range antiAffinity.Preferred {
+		domains := m.siblingDomains(weightedTerm.Term, hosts, machineLabels)
+		for i, candidate := range candidates {
+			if violatesAntiAffinity(candidate, weightedTerm.Term, domains) {
+				penalties[i] += weightedTerm.Weight
+			}
+		}
+	}
+	lowestPenalty := penalties[0]
+	for _, penalty := range penalties {
+		if penalty < lowestPenalty {
+			lowestPenalty = penalty
+		}
+	}
+	preferred := []*bmh.BareMetalHost{}
+	for i, candidate := range candidates {
+		if penalties[i] == lowestPenalty {
+			preferred = append(preferred, candidate)
+		}
+	}
+	return preferred, nil
+}
+
+// consumerMachineLabels returns the labels of the Machines of the namespace,
+// keyed by the name of their Metal3Machine.
+func (m *MachineManager) consumerMachineLabels(ctx context.Context,
+) (map[string]map[string]string, error) {
+	machines := capi.MachineList{}
+	opts := &client.ListOptions{
+		Namespace: m.Metal3Machine.Namespace,
+	}
+	if err := m.client.List(ctx, &machines, opts); err != nil {
+		return nil, err
+	}
+
+	machineLabels := map[string]map[string]string{}
+	for _, machine := range machines.Items {
+		infraRef := machine.Spec.InfrastructureRef
+		if infraRef.Kind != "Metal3Machine" || infraRef.Name == "" {
+			continue
+		}
+		machineLabels[infraRef.Name] = machine.Labels
+	}
+	return machineLabels, nil
+}
+
+// siblingDomains returns the values of the topology label of the hosts
+// consumed by the sibling Metal3Machines, as defined by the term.
+func (m *MachineManager) siblingDomains(term capm3.HostAntiAffinityTerm,
+	hosts []bmh.BareMetalHost, machineLabels map[string]map[string]string,
+) map[string]bool {
+	domains := map[string]bool{}
+	for _, host := range hosts {
+		consumer := host.Spec.ConsumerRef
+		if consumer == nil || consumer.Kind != "Metal3Machine" ||
+			consumer.Namespace != m.Metal3Machine.Namespace ||
+			consumer.Name == m.Metal3Machine.Name {
+			continue
+		}
+		siblingLabels, ok := machineLabels[consumer.Name]
+		if !ok || !sameLabelValues(term.MachineLabelKeys, m.Machine.Labels, siblingLabels) {
+			continue
+		}
+		if domain, ok := host.Labels[term.TopologyKey]; ok {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+// sameLabelValues returns whether both label sets have the same values for all
+// the given keys. A missing key never matches.
+func sameLabelValues(keys []string, a, b map[string]string) bool {
+	for _, key := range keys {
+		aValue, ok := a[key]
+		if !ok {
+			return false
+		}
+		if bValue, ok := b[key]; !ok || aValue != bValue {
+			return false
+		}
+	}
+	return true
+}
+
+// violatesAntiAffinity returns whether the host is in one of the topology
+// domains of the term already used by a sibling.
+func violatesAntiAffinity(host *bmh.BareMetalHost,
+	term capm3.HostAntiAffinityTerm, domains map[string]bool,
+) bool {
+	domain, ok := host.Labels[term.TopologyKey]
+	return ok && domains[domain]
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Host anti-affinity", func() {
+
+	const chassisKey = "topology.metal3.io/chassis"
+
+	machineWithLabels := func(name string, machineLabels map[string]string,
+	) *capi.Machine {
+		return &capi.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "myns",
+				Labels:    machineLabels,
+			},
+			Spec: capi.MachineSpec{
+				InfrastructureRef: corev1.ObjectReference{
+					Kind: "Metal3Machine",
+					Name: name,
+				},
+			},
+		}
+	}
+
+	hostInChassis := func(name, chassis, consumer string) bmh.BareMetalHost {
+		host := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "myns",
+				Labels:    map[string]string{chassisKey: chassis},
+			},
+		}
+		if consumer != "" {
+			host.Spec.ConsumerRef = &corev1.ObjectReference{
+				Kind:      "Metal3Machine",
+				Name:      consumer,
+				Namespace: "myns",
+			}
+		}
+		return host
+	}
+
+	controlPlaneLabels := map[string]string{
+		capi.ClusterLabelName:             "cluster1",
+		capi.MachineControlPlaneLabelName: "true",
+	}
+	workerLabels := map[string]string{
+		capi.ClusterLabelName: "cluster1",
+	}
+	controlPlaneTerm := capm3.HostAntiAffinityTerm{
+		TopologyKey: chassisKey,
+		MachineLabelKeys: []string{capi.ClusterLabelName,
+			capi.MachineControlPlaneLabelName,
+		},
+	}
+	clusterTerm := capm3.HostAntiAffinityTerm{
+		TopologyKey:      chassisKey,
+		MachineLabelKeys: []string{capi.ClusterLabelName},
+	}
+
+	// cp-0 runs in chassis c1, worker-0 in chassis c2
+	hosts := []bmh.BareMetalHost{
+		hostInChassis("host-c1-used", "c1", "cp-0"),
+		hostInChassis("host-c2-used", "c2", "worker-0"),
+		hostInChassis("host-c1", "c1", ""),
+		hostInChassis("host-c2", "c2", ""),
+		hostInChassis("host-c3", "c3", ""),
+		{ObjectMeta: metav1.ObjectMeta{Name: "host-nolabel", Namespace: "myns"}},
+	}
+	machines := []runtime.Object{
+		machineWithLabels("cp-0", controlPlaneLabels),
+		machineWithLabels("worker-0", workerLabels),
+	}
+
+	type testCaseApplyAntiAffinity struct {
+		AntiAffinity      *capm3.HostAntiAffinity
+		MachineLabels     map[string]string
+		ExpectedHostNames []string
+		ExpectedRejected  int
+	}
+
+	DescribeTable("Test applyAntiAffinity",
+		func(tc testCaseApplyAntiAffinity) {
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), machines...)
+			m3machine := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "myns"},
+				Spec:       capm3.Metal3MachineSpec{AntiAffinity: tc.AntiAffinity},
+			}
+			machineMgr, err := NewMachineManager(c, nil, nil, nil,
+				machineWithLabels("new", tc.MachineLabels), m3machine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			candidates := []*bmh.BareMetalHost{}
+			for i := range hosts {
+				if hosts[i].Spec.ConsumerRef == nil {
+					candidates = append(candidates, &hosts[i])
+				}
+			}
+			rejections := map[string]int{}
+			result, err := machineMgr.applyAntiAffinity(context.TODO(),
+				candidates, hosts, rejections,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			resultNames := []string{}
+			for _, host := range result {
+				resultNames = append(resultNames, host.Name)
+			}
+			Expect(resultNames).To(Equal(tc.ExpectedHostNames))
+			Expect(rejections[hostRejectedAntiAffinity]).To(Equal(tc.ExpectedRejected))
+		},
+		Entry("No anti-affinity", testCaseApplyAntiAffinity{
+			MachineLabels: controlPlaneLabels,
+			ExpectedHostNames: []string{"host-c1", "host-c2", "host-c3",
+				"host-nolabel",
+			},
+		}),
+		Entry("Required, control plane siblings only", testCaseApplyAntiAffinity{
+			AntiAffinity: &capm3.HostAntiAffinity{
+				Required: []capm3.HostAntiAffinityTerm{controlPlaneTerm},
+			},
+			MachineLabels: controlPlaneLabels,
+			ExpectedHostNames: []string{"host-c2", "host-c3",
+				"host-nolabel",
+			},
+			ExpectedRejected: 1,
+		}),
+		Entry("Required, all the machines of the cluster", testCaseApplyAntiAffinity{
+			AntiAffinity: &capm3.HostAntiAffinity{
+				Required: []capm3.HostAntiAffinityTerm{clusterTerm},
+			},
+			MachineLabels:     workerLabels,
+			ExpectedHostNames: []string{"host-c3", "host-nolabel"},
+			ExpectedRejected:  2,
+		}),
+		Entry("Required, machine missing the scoping label", testCaseApplyAntiAffinity{
+			AntiAffinity: &capm3.HostAntiAffinity{
+				Required: []capm3.HostAntiAffinityTerm{controlPlaneTerm},
+			},
+			MachineLabels: workerLabels,
+			ExpectedHostNames: []string{"host-c1", "host-c2", "host-c3",
+				"host-nolabel",
+			},
+		}),
+		Entry("Preferred, lowest weight kept", testCaseApplyAntiAffinity{
+			AntiAffinity: &capm3.HostAntiAffinity{
+				Preferred: []capm3.WeightedHostAntiAffinityTerm{
+					{Weight: 100, Term: controlPlaneTerm},
+					{Weight: 10, Term: clusterTerm},
+				},
+			},
+			MachineLabels:     controlPlaneLabels,
+			ExpectedHostNames: []string{"host-c3", "host-nolabel"},
+		}),
+		Entry("Preferred, no host violates the term", testCaseApplyAntiAffinity{
+			AntiAffinity: &capm3.HostAntiAffinity{
+				Required: []capm3.HostAntiAffinityTerm{clusterTerm},
+				Preferred: []capm3.WeightedHostAntiAffinityTerm{
+					{Weight: 50, Term: capm3.HostAntiAffinityTerm{
+						TopologyKey:      "unknown",
+						MachineLabelKeys: []string{capi.ClusterLabelName},
+					}},
+				},
+			},
+			MachineLabels:     controlPlaneLabels,
+			ExpectedHostNames: []string{"host-c3", "host-nolabel"},
+			ExpectedRejected:  2,
+		}),
+	)
+})
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	// Seed once for the random tie-break between equally scored hosts.
+	rand.Seed(time.Now().UnixNano())
+}
+
+// HostScorer ranks the BareMetalHosts that passed the filtering step of
+// chooseHost. The higher the score, the more suitable the host.
+type HostScorer interface {
+	// Score returns one score per candidate, in the same order. hosts is the
+	// complete list of BareMetalHosts in the namespace, consumed or not.
+	Score(candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost) []int
+}
+
+// NewHostScorer returns the HostScorer implementing the given scoring policy.
+// A nil scoring falls back to the random policy.
+func NewHostScorer(scoring *capm3.HostScoring) (HostScorer, error) {
+	if scoring == nil {
+		return randomScorer{}, nil
+	}
+	switch scoring.Policy {
+	case capm3.HostScoringRandom, "":
+		return randomScorer{}, nil
+	case capm3.HostScoringLeastCapableFit:
+		return capacityScorer{mostCapable: false}, nil
+	case capm3.HostScoringMostCapableFit:
+		return capacityScorer{mostCapable: true}, nil
+	case capm3.HostScoringPackByLabel, capm3.HostScoringSpreadByLabel:
+		if scoring.LabelKey == "" {
+			return nil, errors.Errorf("a label key is required for the %s policy",
+				scoring.Policy,
+			)
+		}
+		return labelScorer{
+			labelKey: scoring.LabelKey,
+			spread:   scoring.Policy == capm3.HostScoringSpreadByLabel,
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown host scoring policy %s", scoring.Policy)
+	}
+}
+
+// selectHost returns one of the candidates with the highest score, picked at
+// random if several share it. It returns nil if there are no candidates.
+func selectHost(candidates []*bmh.BareMetalHost, scores []int) *bmh.BareMetalHost {
+	if len(candidates) == 0 || len(candidates) != len(scores) {
+		return nil
+	}
+	best := []*bmh.BareMetalHost{}
+	bestScore := scores[0]
+	for i, candidate := range candidates {
+		switch {
+		case scores[i] > bestScore:
+			bestScore = scores[i]
+			best = []*bmh.BareMetalHost{candidate}
+		case scores[i] == bestScore:
+			best = append(best, candidate)
+		}
+	}
+	return best[rand.Intn(len(best))]
+}
+
+// randomScorer gives the same score to all hosts, so that selectHost picks
+// one at random.
+type randomScorer struct{}
+
+// Score implements HostScorer
+func (s randomScorer) Score(candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost) []int {
+	return make([]int, len(candidates))
+}
+
+// capacityScorer ranks the hosts by the resources found during inspection.
+// Hosts without hardware details are considered to have no resources.
+type capacityScorer struct {
+	mostCapable bool
+}
+
+// Score implements HostScorer
+func (s capacityScorer) Score(candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost) []int {
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareCapacity(candidates[order[i]], candidates[order[j]]) < 0
+	})
+
+	// Hosts with the same capacity get the same rank
+	scores := make([]int, len(candidates))
+	rank := 0
+	for i, index := range order {
+		if i > 0 && compareCapacity(candidates[order[i-1]], candidates[index]) != 0 {
+			rank++
+		}
+		if s.mostCapable {
+			scores[index] = rank
+		} else {
+			scores[index] = -rank
+		}
+	}
+	return scores
+}
+
+// compareCapacity compares the RAM, then the CPU count and then the total
+// storage size of two hosts. It returns a negative value if a has less
+// resources than b, a positive one if it has more and zero if they are equal.
+func compareCapacity(a, b *bmh.BareMetalHost) int {
+	aRAM, aCPU, aStorage := hostCapacity(a)
+	bRAM, bCPU, bStorage := hostCapacity(b)
+	switch {
+	case aRAM != bRAM:
+		return aRAM - bRAM
+	case aCPU != bCPU:
+		return aCPU - bCPU
+	case aStorage < bStorage:
+		return -1
+	case aStorage > bStorage:
+		return 1
+	}
+	return 0
+}
+
+// hostCapacity returns the RAM in MiB, the CPU count and the total storage
+// size in bytes of the host.
+func hostCapacity(host *bmh.BareMetalHost) (int, int, bmh.Capacity) {
+	if host.Status.HardwareDetails == nil {
+		return 0, 0, 0
+	}
+	var storage bmh.Capacity
+	for _, disk := range host.Status.HardwareDetails.Storage {
+		storage += disk.SizeBytes
+	}
+	return host.Status.HardwareDetails.RAMMebibytes,
+		host.Status.HardwareDetails.CPU.Count, storage
+}
+
+// labelScorer ranks the hosts by the number of consumed hosts sharing the
+// same value for a label. It prefers the largest group when packing and the
+// smallest one when spreading. Hosts without the label always come last.
+type labelScorer struct {
+	labelKey string
+	spread   bool
+}
+
+// Score implements HostScorer
+func (s labelScorer) Score(candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost) []int {
+	consumed := map[string]int{}
+	for _, host := range hosts {
+		if host.Spec.ConsumerRef == nil {
+			continue
+		}
+		if value, ok := host.Labels[s.labelKey]; ok {
+			consumed[value]++
+		}
+	}
+
+	scores := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		value, ok := candidate.Labels[s.labelKey]
+		switch {
+		case !ok:
+			scores[i] = -len(hosts) - 1
+		case s.spread:
+			scores[i] = -consumed[value]
+		default:
+			scores[i] = consumed[value]
+		}
+	}
+	return scores
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func scoringHost(name string, ramMebibytes int, cpus int,
+	storage bmh.Capacity, hostLabels map[string]string, consumed bool,
+) bmh.BareMetalHost {
+	host := bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "myns",
+			Labels:    hostLabels,
+		},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				RAMMebibytes: ramMebibytes,
+				CPU:          bmh.CPU{Count: cpus},
+				Storage:      []bmh.Storage{{SizeBytes: storage}},
+			},
+		},
+	}
+	if consumed {
+		host.Spec.ConsumerRef = &corev1.ObjectReference{
+			Name:      name + "-consumer",
+			Namespace: "myns",
+			Kind:      "Metal3Machine",
+		}
+	}
+	return host
+}
+
+var _ = Describe("Host scoring", func() {
+
+	type testCaseNewHostScorer struct {
+		Scoring        *capm3.HostScoring
+		ExpectedScorer HostScorer
+		ExpectError    bool
+	}
+
+	DescribeTable("Test NewHostScorer",
+		func(tc testCaseNewHostScorer) {
+			scorer, err := NewHostScorer(tc.Scoring)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scorer).To(Equal(tc.ExpectedScorer))
+		},
+		Entry("No scoring", testCaseNewHostScorer{
+			ExpectedScorer: randomScorer{},
+		}),
+		Entry("Random", testCaseNewHostScorer{
+			Scoring:        &capm3.HostScoring{Policy: capm3.HostScoringRandom},
+			ExpectedScorer: randomScorer{},
+		}),
+		Entry("LeastCapableFit", testCaseNewHostScorer{
+			Scoring:        &capm3.HostScoring{Policy: capm3.HostScoringLeastCapableFit},
+			ExpectedScorer: capacityScorer{mostCapable: false},
+		}),
+		Entry("MostCapableFit", testCaseNewHostScorer{
+			Scoring:        &capm3.HostScoring{Policy: capm3.HostScoringMostCapableFit},
+			ExpectedScorer: capacityScorer{mostCapable: true},
+		}),
+		Entry("PackByLabel", testCaseNewHostScorer{
+			Scoring: &capm3.HostScoring{
+				Policy:   capm3.HostScoringPackByLabel,
+				LabelKey: "rack",
+			},
+			ExpectedScorer: labelScorer{labelKey: "rack", spread: false},
+		}),
+		Entry("SpreadByLabel", testCaseNewHostScorer{
+			Scoring: &capm3.HostScoring{
+				Policy:   capm3.HostScoringSpreadByLabel,
+				LabelKey: "rack",
+			},
+			ExpectedScorer: labelScorer{labelKey: "rack", spread: true},
+		}),
+		Entry("SpreadByLabel without label key", testCaseNewHostScorer{
+			Scoring:     &capm3.HostScoring{Policy: capm3.HostScoringSpreadByLabel},
+			ExpectError: true,
+		}),
+		Entry("Unknown policy", testCaseNewHostScorer{
+			Scoring:     &capm3.HostScoring{Policy: "pancakes"},
+			ExpectError: true,
+		}),
+	)
+
+	small := scoringHost("small", 8192, 8, 100, map[string]string{"rack": "r1"}, false)
+	medium := scoringHost("medium", 16384, 8, 100, map[string]string{"rack": "r2"}, false)
+	mediumMoreCPU := scoringHost("mediumMoreCPU", 16384, 16, 100, map[string]string{"rack": "r2"}, false)
+	big := scoringHost("big", 65536, 32, 1000, nil, false)
+	bigTwin := scoringHost("bigTwin", 65536, 32, 1000, nil, false)
+	noDetails := bmh.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "noDetails"}}
+	consumedR1 := scoringHost("consumedR1", 8192, 8, 100, map[string]string{"rack": "r1"}, true)
+	consumedR2a := scoringHost("consumedR2a", 8192, 8, 100, map[string]string{"rack": "r2"}, true)
+	consumedR2b := scoringHost("consumedR2b", 8192, 8, 100, map[string]string{"rack": "r2"}, true)
+
+	type testCaseScore struct {
+		Scorer         HostScorer
+		Candidates     []*bmh.BareMetalHost
+		Hosts          []bmh.BareMetalHost
+		ExpectedScores []int
+	}
+
+	DescribeTable("Test Score",
+		func(tc testCaseScore) {
+			Expect(tc.Scorer.Score(tc.Candidates, tc.Hosts)).To(Equal(tc.ExpectedScores))
+		},
+		Entry("Random gives the same score to all", testCaseScore{
+			Scorer:         randomScorer{},
+			Candidates:     []*bmh.BareMetalHost{&big, &small, &medium},
+			ExpectedScores: []int{0, 0, 0},
+		}),
+		Entry("LeastCapableFit prefers the smallest host", testCaseScore{
+			Scorer:         capacityScorer{mostCapable: false},
+			Candidates:     []*bmh.BareMetalHost{&big, &small, &mediumMoreCPU, &medium},
+			ExpectedScores: []int{-3, 0, -2, -1},
+		}),
+		Entry("MostCapableFit prefers the biggest host", testCaseScore{
+			Scorer:         capacityScorer{mostCapable: true},
+			Candidates:     []*bmh.BareMetalHost{&big, &small, &mediumMoreCPU, &medium},
+			ExpectedScores: []int{3, 0, 2, 1},
+		}),
+		Entry("Same capacity gives the same score", testCaseScore{
+			Scorer:         capacityScorer{mostCapable: true},
+			Candidates:     []*bmh.BareMetalHost{&big, &bigTwin, &noDetails},
+			ExpectedScores: []int{1, 1, 0},
+		}),
+		Entry("PackByLabel prefers the most used label value", testCaseScore{
+			Scorer:     labelScorer{labelKey: "rack", spread: false},
+			Candidates: []*bmh.BareMetalHost{&small, &medium, &big},
+			Hosts: []bmh.BareMetalHost{small, medium, big, consumedR1,
+				consumedR2a, consumedR2b,
+			},
+			ExpectedScores: []int{1, 2, -7},
+		}),
+		Entry("SpreadByLabel prefers the least used label value", testCaseScore{
+			Scorer:     labelScorer{labelKey: "rack", spread: true},
+			Candidates: []*bmh.BareMetalHost{&small, &medium, &big},
+			Hosts: []bmh.BareMetalHost{small, medium, big, consumedR1,
+				consumedR2a, consumedR2b,
+			},
+			ExpectedScores: []int{-1, -2, -7},
+		}),
+	)
+
+	type testCaseSelectHost struct {
+		Candidates       []*bmh.BareMetalHost
+		Scores           []int
+		ExpectedHostName []string
+	}
+
+	DescribeTable("Test selectHost",
+		func(tc testCaseSelectHost) {
+			host := selectHost(tc.Candidates, tc.Scores)
+			if len(tc.ExpectedHostName) == 0 {
+				Expect(host).To(BeNil())
+				return
+			}
+			Expect(host).NotTo(BeNil())
+			Expect(tc.ExpectedHostName).To(ContainElement(host.Name))
+		},
+		Entry("No candidates", testCaseSelectHost{}),
+		Entry("Mismatching scores", testCaseSelectHost{
+			Candidates: []*bmh.BareMetalHost{&small},
+		}),
+		Entry("Highest score", testCaseSelectHost{
+			Candidates:       []*bmh.BareMetalHost{&small, &big, &medium},
+			Scores:           []int{-1, 5, 2},
+			ExpectedHostName: []string{"big"},
+		}),
+		Entry("Tie between highest scores", testCaseSelectHost{
+			Candidates:       []*bmh.BareMetalHost{&small, &big, &bigTwin},
+			Scores:           []int{-1, 5, 5},
+			ExpectedHostName: []string{"big", "bigTwin"},
+		}),
+	)
+})
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileImageUpgrade drives the in-place upgrade of the image of the host.
+// When the image of a provisioned metal3 machine allowing in-place upgrades
+// changes, the image of the host is removed so that the host is deprovisioned.
+// Once the host is deprovisioned, setHostSpec sets the new image and the
+// host is provisioned again. The progress is reported in the status of the
+// metal3 machine.
+func (m *MachineManager) reconcileImageUpgrade(host *bmh.BareMetalHost) {
+	upgrade := m.Metal3Machine.Status.ImageUpgrade
+	if upgrade != nil {
+		switch upgrade.State {
+		case capm3.ImageUpgradeDeprovisioning:
+			switch host.Status.Provisioning.State {
+			case bmh.StateReady, bmh.StateAvailable:
+				m.Log.Info("Host deprovisioned, provisioning the new image",
+					"host", host.Name,
+				)
+				upgrade.State = capm3.ImageUpgradeProvisioning
+				upgrade.Image = *m.Metal3Machine.Spec.Image.DeepCopy()
+			}
+			return
+		case capm3.ImageUpgradeProvisioning:
+			if host.Status.Provisioning.State == bmh.StateProvisioned &&
+				host.Status.Provisioning.Image.URL == upgrade.Image.URL {
+				m.Log.Info("Image upgrade completed", "host", host.Name)
+				now := metav1.Now()
+				upgrade.State = capm3.ImageUpgradeCompleted
+				upgrade.CompletionTime = &now
+			}
+			return
+		}
+	}
+
+	if !m.Metal3Machine.Spec.InPlaceUpgrade || host.Spec.Image == nil ||
+		imageMatches(host.Spec.Image, m.Metal3Machine.Spec.Image) {
+		return
+	}
+	m.Log.Info("Image changed, deprovisioning the host for an upgrade",
+		"host", host.Name, "image", m.Metal3Machine.Spec.Image.URL,
+	)
+	now := metav1.Now()
+	m.Metal3Machine.Status.ImageUpgrade = &capm3.ImageUpgradeStatus{
+		State:     capm3.ImageUpgradeDeprovisioning,
+		Image:     *m.Metal3Machine.Spec.Image.DeepCopy(),
+		StartTime: &now,
+	}
+	recordEvent(m.recorder, host, corev1.EventTypeNormal, DeprovisioningReason,
+		"Deprovisioning to upgrade to image %s", m.Metal3Machine.Spec.Image.URL,
+	)
+	recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+		DeprovisioningReason, "Deprovisioning BareMetalHost %s to upgrade to image %s",
+		host.Name, m.Metal3Machine.Spec.Image.URL,
+	)
+	host.Spec.Image = nil
+}
+
+// upgradeDeprovisioning returns whether the host is being deprovisioned for
+// an image upgrade, in which case its image must not be set.
+func (m *MachineManager) upgradeDeprovisioning() bool {
+	upgrade := m.Metal3Machine.Status.ImageUpgrade
+	return upgrade != nil && upgrade.State == capm3.ImageUpgradeDeprovisioning
+}
+
+// imageMatches returns whether the image of the host is the image of the
+// metal3 machine.
+func imageMatches(hostImage *bmh.Image, image capm3.Image) bool {
+	checksumType := ""
+	if image.ChecksumType != nil {
+		checksumType = *image.ChecksumType
+	}
+	if hostImage.URL != image.URL || hostImage.Checksum != image.Checksum ||
+		string(hostImage.ChecksumType) != checksumType {
+		return false
+	}
+	if hostImage.DiskFormat == nil || image.DiskFormat == nil {
+		return hostImage.DiskFormat == image.DiskFormat
+	}
+	return *hostImage.DiskFormat == *image.DiskFormat
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("In-place image upgrade", func() {
+
+	oldImage := capm3.Image{
+		URL:      "http://172.22.0.1/images/old.qcow2",
+		Checksum: "http://172.22.0.1/images/old.qcow2.md5sum",
+	}
+	newImage := capm3.Image{
+		URL:      "http://172.22.0.1/images/new.qcow2",
+		Checksum: "http://172.22.0.1/images/new.qcow2.md5sum",
+	}
+	bmhImage := func(image capm3.Image) *bmh.Image {
+		return &bmh.Image{URL: image.URL, Checksum: image.Checksum}
+	}
+
+	type testCaseImageUpgrade struct {
+		InPlaceUpgrade        bool
+		Upgrade               *capm3.ImageUpgradeStatus
+		HostState             bmh.ProvisioningState
+		HostImage             *bmh.Image
+		HostProvisionedURL    string
+		ExpectedUpgradeState  capm3.ImageUpgradeState
+		ExpectedHostImage     *bmh.Image
+		ExpectCompletionTime  bool
+		ExpectNoUpgradeStatus bool
+	}
+
+	DescribeTable("Test reconcileImageUpgrade and setHostSpec",
+		func(tc testCaseImageUpgrade) {
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm())
+			m3machine := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m3m", Namespace: "myns"},
+				Spec: capm3.Metal3MachineSpec{
+					Image:          newImage,
+					InPlaceUpgrade: tc.InPlaceUpgrade,
+				},
+				Status: capm3.Metal3MachineStatus{
+					UserData:     &corev1.SecretReference{Name: "userdata"},
+					ImageUpgrade: tc.Upgrade,
+				},
+			}
+			machine := &capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "myns"},
+			}
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "host", Namespace: "myns"},
+				Spec:       bmh.BareMetalHostSpec{Image: tc.HostImage},
+			}
+			host.Status.Provisioning.State = tc.HostState
+			host.Status.Provisioning.Image.URL = tc.HostProvisionedURL
+
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, machine, m3machine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			machineMgr.reconcileImageUpgrade(host)
+			Expect(machineMgr.setHostSpec(context.TODO(), host)).To(Succeed())
+
+			Expect(host.Spec.Image).To(Equal(tc.ExpectedHostImage))
+			upgrade := m3machine.Status.ImageUpgrade
+			if tc.ExpectNoUpgradeStatus {
+				Expect(upgrade).To(BeNil())
+				return
+			}
+			Expect(upgrade).NotTo(BeNil())
+			Expect(upgrade.State).To(Equal(tc.ExpectedUpgradeState))
+			Expect(upgrade.Image).To(Equal(newImage))
+			Expect(upgrade.CompletionTime != nil).To(Equal(tc.ExpectCompletionTime))
+		},
+		Entry("In-place upgrade not allowed", testCaseImageUpgrade{
+			HostState:             bmh.StateProvisioned,
+			HostImage:             bmhImage(oldImage),
+			HostProvisionedURL:    oldImage.URL,
+			ExpectedHostImage:     bmhImage(oldImage),
+			ExpectNoUpgradeStatus: true,
+		}),
+		Entry("Image unchanged", testCaseImageUpgrade{
+			InPlaceUpgrade:        true,
+			HostState:             bmh.StateProvisioned,
+			HostImage:             bmhImage(newImage),
+			HostProvisionedURL:    newImage.URL,
+			ExpectedHostImage:     bmhImage(newImage),
+			ExpectNoUpgradeStatus: true,
+		}),
+		Entry("Image changed, deprovisioning starts", testCaseImageUpgrade{
+			InPlaceUpgrade:       true,
+			HostState:            bmh.StateProvisioned,
+			HostImage:            bmhImage(oldImage),
+			HostProvisionedURL:   oldImage.URL,
+			ExpectedUpgradeState: capm3.ImageUpgradeDeprovisioning,
+		}),
+		Entry("Host still deprovisioning", testCaseImageUpgrade{
+			InPlaceUpgrade: true,
+			Upgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeDeprovisioning,
+				Image: newImage,
+			},
+			HostState:            bmh.StateDeprovisioning,
+			HostProvisionedURL:   oldImage.URL,
+			ExpectedUpgradeState: capm3.ImageUpgradeDeprovisioning,
+		}),
+		Entry("Host deprovisioned, new image set", testCaseImageUpgrade{
+			InPlaceUpgrade: true,
+			Upgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeDeprovisioning,
+				Image: newImage,
+			},
+			HostState:            bmh.StateReady,
+			ExpectedUpgradeState: capm3.ImageUpgradeProvisioning,
+			ExpectedHostImage:    bmhImage(newImage),
+		}),
+		Entry("Host provisioning the new image", testCaseImageUpgrade{
+			InPlaceUpgrade: true,
+			Upgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeProvisioning,
+				Image: newImage,
+			},
+			HostState:            bmh.StateProvisioning,
+			HostImage:            bmhImage(newImage),
+			ExpectedUpgradeState: capm3.ImageUpgradeProvisioning,
+			ExpectedHostImage:    bmhImage(newImage),
+		}),
+		Entry("Host provisioned with the new image", testCaseImageUpgrade{
+			InPlaceUpgrade: true,
+			Upgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeProvisioning,
+				Image: newImage,
+			},
+			HostState:            bmh.StateProvisioned,
+			HostImage:            bmhImage(newImage),
+			HostProvisionedURL:   newImage.URL,
+			ExpectedUpgradeState: capm3.ImageUpgradeCompleted,
+			ExpectedHostImage:    bmhImage(newImage),
+			ExpectCompletionTime: true,
+		}),
+	)
+})
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LoadBalancerBackendsSuffix is the suffix of the name of the ConfigMap
+	// listing the control plane backends of a Metal3Cluster.
+	LoadBalancerBackendsSuffix = "-lb-backends"
+
+	// LoadBalancerBackendsJSONKey is the key of the backends rendered in the
+	// JSON format.
+	LoadBalancerBackendsJSONKey = "backends.json"
+	// LoadBalancerBackendsHAProxyKey is the key of the backends rendered in
+	// the HAProxy format.
+	LoadBalancerBackendsHAProxyKey = "haproxy.cfg"
+)
+
+// loadBalancerBackend is a control plane machine behind the external load
+// balancer.
+type loadBalancerBackend struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+// backendsRenderer renders the backends into the data of the ConfigMap.
+type backendsRenderer func(backends []loadBalancerBackend) (map[string]string, error)
+
+// backendsRenderers are the renderers of the supported formats of the
+// backends.
+var backendsRenderers = map[capm3.LoadBalancerBackendsFormat]backendsRenderer{
+	capm3.LoadBalancerBackendsFormatJSON:    renderBackendsJSON,
+	capm3.LoadBalancerBackendsFormatHAProxy: renderBackendsHAProxy,
+}
+
+// SyncLoadBalancerBackends renders the addresses of the control plane
+// Metal3Machines in the ConfigMap of the external load balancer, if the
+// Metal3Cluster has one. The ConfigMap is only updated when the backends
+// change.
+func (s *ClusterManager) SyncLoadBalancerBackends(ctx context.Context) error {
+	config := s.Metal3Cluster.Spec.LoadBalancerBackends
+	if config == nil {
+		return nil
+	}
+	render, ok := backendsRenderers[config.Format]
+	if !ok {
+		return errors.Errorf("unknown load balancer backends format %s",
+			config.Format,
+		)
+	}
+
+	backends, err := s.listLoadBalancerBackends(ctx, config)
+	if err != nil {
+		return err
+	}
+	data, err := render(backends)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{
+		Name:      s.Metal3Cluster.Name + LoadBalancerBackendsSuffix,
+		Namespace: s.Metal3Cluster.Namespace,
+	}
+	if err := s.client.Get(ctx, key, configMap); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: capm3.GroupVersion.String(),
+						Kind:       "Metal3Cluster",
+						Name:       s.Metal3Cluster.Name,
+						UID:        s.Metal3Cluster.UID,
+						Controller: pointer.BoolPtr(true),
+					},
+				},
+				Labels: map[string]string{
+					capi.ClusterLabelName: s.Cluster.Name,
+				},
+			},
+			Data: data,
+		}
+		s.Log.Info("Creating the load balancer backends", "configmap", key.Name)
+		return s.client.Create(ctx, configMap)
+	}
+
+	if reflect.DeepEqual(configMap.Data, data) {
+		return nil
+	}
+	s.Log.Info("Updating the load balancer backends", "configmap", key.Name,
+		"backends", len(backends),
+	)
+	configMap.Data = data
+	return s.client.Update(ctx, configMap)
+}
+
+// listLoadBalancerBackends returns the control plane Metal3Machines of the
+// cluster having an address of the configured type, sorted by name. The
+// machines being deleted are left out.
+func (s *ClusterManager) listLoadBalancerBackends(ctx context.Context,
+	config *capm3.LoadBalancerBackends,
+) ([]loadBalancerBackend, error) {
+	addressType := config.AddressType
+	if addressType == "" {
+		addressType = capi.MachineInternalIP
+	}
+	port := config.Port
+	if port == 0 {
+		port = capm3.DefaultLoadBalancerBackendPort
+	}
+
+	machines := capi.MachineList{}
+	if err := s.client.List(ctx, &machines,
+		client.InNamespace(s.Metal3Cluster.Namespace),
+		client.MatchingLabels{capi.ClusterLabelName: s.Cluster.Name},
+		client.HasLabels{capi.MachineControlPlaneLabelName},
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list the control plane machines")
+	}
+
+	backends := []loadBalancerBackend{}
+	for _, machine := range machines.Items {
+		infraRef := machine.Spec.InfrastructureRef
+		if !machine.DeletionTimestamp.IsZero() || infraRef.Kind != "Metal3Machine" {
+			continue
+		}
+		m3m := &capm3.Metal3Machine{}
+		key := client.ObjectKey{Name: infraRef.Name, Namespace: machine.Namespace}
+		if err := s.client.Get(ctx, key, m3m); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !m3m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, address := range m3m.Status.Addresses {
+			if address.Type == addressType {
+				backends = append(backends, loadBalancerBackend{
+					Name:    m3m.Name,
+					Address: address.Address,
+					Port:    port,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Name < backends[j].Name
+	})
+	return backends, nil
+}
+
+// renderBackendsJSON renders the backends as a JSON list.
+func renderBackendsJSON(backends []loadBalancerBackend) (map[string]string, error) {
+	content, err := json.Marshal(backends)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{LoadBalancerBackendsJSONKey: string(content)}, nil
+}
+
+// renderBackendsHAProxy renders the backends as an HAProxy backend section.
+func renderBackendsHAProxy(backends []loadBalancerBackend) (map[string]string, error) {
+	content := bytes.NewBufferString("backend kube-apiserver\n" +
+		"    mode tcp\n" +
+		"    balance roundrobin\n" +
+		"    option tcp-check\n",
+	)
+	for _, backend := range backends {
+		fmt.Fprintf(content, "    server %s %s check\n", backend.Name,
+			net.JoinHostPort(backend.Address, strconv.Itoa(backend.Port)),
+		)
+	}
+	return map[string]string{LoadBalancerBackendsHAProxyKey: content.String()}, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Load balancer backends", func() {
+
+	// backendMachine returns a Machine and its Metal3Machine with an internal
+	// address.
+	backendMachine := func(name, address string, controlPlane bool,
+	) []runtime.Object {
+		labels := map[string]string{capi.ClusterLabelName: clusterName}
+		if controlPlane {
+			labels[capi.MachineControlPlaneLabelName] = ""
+		}
+		return []runtime.Object{
+			&capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespaceName,
+					Labels:    labels,
+				},
+				Spec: capi.MachineSpec{
+					ClusterName: clusterName,
+					InfrastructureRef: corev1.ObjectReference{
+						Kind: "Metal3Machine",
+						Name: name,
+					},
+				},
+			},
+			&capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespaceName,
+				},
+				Status: capm3.Metal3MachineStatus{
+					Addresses: capi.MachineAddresses{
+						{Type: capi.MachineHostName, Address: name},
+						{Type: capi.MachineInternalIP, Address: address},
+					},
+				},
+			},
+		}
+	}
+
+	type testCaseSyncLoadBalancerBackends struct {
+		Backends     *capm3.LoadBalancerBackends
+		Objects      []runtime.Object
+		ExpectedData map[string]string
+	}
+
+	DescribeTable("Test SyncLoadBalancerBackends",
+		func(tc testCaseSyncLoadBalancerBackends) {
+			spec := bmcSpec()
+			spec.LoadBalancerBackends = tc.Backends
+			bmCluster := newMetal3Cluster(metal3ClusterName, bmcOwnerRef, spec,
+				nil,
+			)
+			objects := append([]runtime.Object{bmCluster}, tc.Objects...)
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr := &ClusterManager{
+				client:        c,
+				Metal3Cluster: bmCluster,
+				Cluster:       newCluster(clusterName),
+				Log:           klogr.New(),
+			}
+
+			Expect(clusterMgr.SyncLoadBalancerBackends(context.TODO())).To(
+				Succeed(),
+			)
+			configMap := &corev1.ConfigMap{}
+			err := c.Get(context.TODO(), client.ObjectKey{
+				Name:      metal3ClusterName + LoadBalancerBackendsSuffix,
+				Namespace: namespaceName,
+			}, configMap)
+			if tc.ExpectedData == nil {
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.Data).To(Equal(tc.ExpectedData))
+			Expect(configMap.OwnerReferences).To(HaveLen(1))
+			Expect(configMap.OwnerReferences[0].Kind).To(Equal("Metal3Cluster"))
+		},
+		Entry("No load balancer backends", testCaseSyncLoadBalancerBackends{
+			Objects: backendMachine("cp-0", "192.168.111.21", true),
+		}),
+		Entry("No backend", testCaseSyncLoadBalancerBackends{
+			Backends: &capm3.LoadBalancerBackends{
+				Format: capm3.LoadBalancerBackendsFormatJSON,
+			},
+			ExpectedData: map[string]string{
+				LoadBalancerBackendsJSONKey: "[]",
+			},
+		}),
+		Entry("JSON", testCaseSyncLoadBalancerBackends{
+			Backends: &capm3.LoadBalancerBackends{
+				Format: capm3.LoadBalancerBackendsFormatJSON,
+			},
+			Objects: append(append(
+				backendMachine("cp-1", "192.168.111.22", true),
+				backendMachine("cp-0", "192.168.111.21", true)...),
+				backendMachine("worker-0", "192.168.111.23", false)...,
+			),
+			ExpectedData: map[string]string{
+				LoadBalancerBackendsJSONKey: `[{"name":"cp-0",` +
+					`"address":"192.168.111.21","port":6443},{"name":"cp-1",` +
+					`"address":"192.168.111.22","port":6443}]`,
+			},
+		}),
+		Entry("HAProxy with a port and an address type",
+			testCaseSyncLoadBalancerBackends{
+				Backends: &capm3.LoadBalancerBackends{
+					Format:      capm3.LoadBalancerBackendsFormatHAProxy,
+					AddressType: capi.MachineHostName,
+					Port:        8443,
+				},
+				Objects: backendMachine("cp-0", "192.168.111.21", true),
+				ExpectedData: map[string]string{
+					LoadBalancerBackendsHAProxyKey: "backend kube-apiserver\n" +
+						"    mode tcp\n" +
+						"    balance roundrobin\n" +
+						"    option tcp-check\n" +
+						"    server cp-0 cp-0:8443 check\n",
+				},
+			},
+		),
+		Entry("Updated backends", testCaseSyncLoadBalancerBackends{
+			Backends: &capm3.LoadBalancerBackends{
+				Format: capm3.LoadBalancerBackendsFormatJSON,
+			},
+			Objects: append(backendMachine("cp-0", "192.168.111.21", true),
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      metal3ClusterName + LoadBalancerBackendsSuffix,
+						Namespace: namespaceName,
+						OwnerReferences: []metav1.OwnerReference{
+							{Kind: "Metal3Cluster", Name: metal3ClusterName},
+						},
+					},
+					Data: map[string]string{
+						LoadBalancerBackendsJSONKey: "[]",
+					},
+				},
+			),
+			ExpectedData: map[string]string{
+				LoadBalancerBackendsJSONKey: `[{"name":"cp-0",` +
+					`"address":"192.168.111.21","port":6443}]`,
+			},
+		}),
+	)
+
+	It("Leaves out the machines being deleted", func() {
+		objects := backendMachine("cp-0", "192.168.111.21", true)
+		now := metav1.Now()
+		objects[0].(*capi.Machine).DeletionTimestamp = &now
+		objects = append(objects,
+			backendMachine("cp-1", "192.168.111.22", true)...,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+		clusterMgr := &ClusterManager{
+			client:        c,
+			Metal3Cluster: newMetal3Cluster(metal3ClusterName, nil, nil, nil),
+			Cluster:       newCluster(clusterName),
+			Log:           klogr.New(),
+		}
+
+		backends, err := clusterMgr.listLoadBalancerBackends(context.TODO(),
+			&capm3.LoadBalancerBackends{},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backends).To(Equal([]loadBalancerBackend{
+			{Name: "cp-1", Address: "192.168.111.22", Port: 6443},
+		}))
+	})
+})
@@ -19,6 +19,7 @@ package baremetal
 import (
 	"github.com/go-logr/logr"
 	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"k8s.io/client-go/tools/record"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -37,16 +38,21 @@ type ManagerFactoryInterface interface {
 	NewDataManager(*capm3.Metal3Data, logr.Logger) (
 		DataManagerInterface, error,
 	)
+	NewRemediationManager(*capm3.Metal3Remediation, *capm3.Metal3Machine,
+		*capi.Machine, logr.Logger,
+	) (RemediationManagerInterface, error)
 }
 
-// ManagerFactory only contains a client
+// ManagerFactory contains a client and an event recorder
 type ManagerFactory struct {
-	client client.Client
+	client   client.Client
+	recorder record.EventRecorder
 }
 
-// NewManagerFactory returns a new factory.
-func NewManagerFactory(client client.Client) ManagerFactory {
-	return ManagerFactory{client: client}
+// NewManagerFactory returns a new factory. The recorder may be nil, in which
+// case no events are emitted.
+func NewManagerFactory(client client.Client, recorder record.EventRecorder) ManagerFactory {
+	return ManagerFactory{client: client, recorder: recorder}
 }
 
 // NewClusterManager creates a new ClusterManager
@@ -59,7 +65,7 @@ func (f ManagerFactory) NewMachineManager(capiCluster *capi.Cluster,
 	capm3Cluster *capm3.Metal3Cluster,
 	capiMachine *capi.Machine, capm3Machine *capm3.Metal3Machine,
 	machineLog logr.Logger) (MachineManagerInterface, error) {
-	return NewMachineManager(f.client, capiCluster, capm3Cluster, capiMachine,
+	return NewMachineManager(f.client, f.recorder, capiCluster, capm3Cluster, capiMachine,
 		capm3Machine, machineLog)
 }
 
@@ -70,5 +76,13 @@ func (f ManagerFactory) NewDataTemplateManager(metadata *capm3.Metal3DataTemplat
 
 // NewDataManager creates a new DataManager
 func (f ManagerFactory) NewDataManager(metadata *capm3.Metal3Data, metadataLog logr.Logger) (DataManagerInterface, error) {
-	return NewDataManager(f.client, metadata, metadataLog)
+	return NewDataManager(f.client, f.recorder, metadata, metadataLog)
+}
+
+// NewRemediationManager creates a new RemediationManager
+func (f ManagerFactory) NewRemediationManager(remediation *capm3.Metal3Remediation,
+	capm3Machine *capm3.Metal3Machine, capiMachine *capi.Machine,
+	remediationLog logr.Logger) (RemediationManagerInterface, error) {
+	return NewRemediationManager(f.client, f.recorder, remediation, capm3Machine,
+		capiMachine, remediationLog)
 }
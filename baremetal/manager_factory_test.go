@@ -34,7 +34,7 @@ var _ = Describe("Manager factory testing", func() {
 
 	BeforeEach(func() {
 		managerClient = fakeclient.NewFakeClientWithScheme(setupScheme())
-		managerFactory = NewManagerFactory(managerClient)
+		managerFactory = NewManagerFactory(managerClient, nil)
 	})
 
 	It("returns a manager factory", func() {
@@ -69,4 +69,12 @@ var _ = Describe("Manager factory testing", func() {
 		)
 		Expect(err).NotTo(HaveOccurred())
 	})
+
+	It("returns a remediation manager", func() {
+		_, err := managerFactory.NewRemediationManager(
+			&capm3.Metal3Remediation{}, &capm3.Metal3Machine{}, &capi.Machine{},
+			clusterLog,
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
@@ -19,6 +19,7 @@ package baremetal
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -27,26 +28,36 @@ import (
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ClusterManagerInterface is an interface for a ClusterManager
 type ClusterManagerInterface interface {
 	Create(context.Context) error
-	Delete() error
+	Delete(context.Context) error
 	UpdateClusterStatus() error
 	SetFinalizer()
 	UnsetFinalizer()
 	CountDescendants(context.Context) (int, error)
+	SyncLoadBalancerBackends(context.Context) error
+	CheckControlPlaneEndpoint(context.Context) error
 }
 
 // ClusterManager is responsible for performing metal3 cluster reconciliation
 type ClusterManager struct {
 	client client.Client
+	// dial opens the connections probing the control plane endpoint
+	dial DialFunc
 
 	Cluster       *capi.Cluster
 	Metal3Cluster *capm3.Metal3Cluster
@@ -68,6 +79,7 @@ func NewClusterManager(client client.Client, cluster *capi.Cluster,
 
 	return &ClusterManager{
 		client:        client,
+		dial:          (&net.Dialer{}).DialContext,
 		Metal3Cluster: metal3Cluster,
 		Cluster:       cluster,
 		Log:           clusterLog,
@@ -100,12 +112,122 @@ func (s *ClusterManager) Create(ctx context.Context) error {
 	if err != nil {
 		// Should have been picked earlier. Do not requeue
 		s.setError("Invalid Metal3Cluster provided", capierrors.InvalidConfigurationClusterError)
+		conditions.MarkFalse(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition,
+			capm3.InvalidConfigurationReason, capi.ConditionSeverityError,
+			"%s", err.Error(),
+		)
 		return err
 	}
 
 	// clear an error if one was previously set
 	s.clearError()
 
+	return s.allocateControlPlaneEndpoint(ctx)
+}
+
+// allocateControlPlaneEndpoint sets the host of the control plane endpoint
+// from the ControlPlaneEndpointFromIPPool, if it is not set yet. It creates
+// the IPClaim of the Metal3Cluster, and asks for requeue until its IPAddress
+// is allocated.
+func (s *ClusterManager) allocateControlPlaneEndpoint(ctx context.Context) error {
+	poolName := s.Metal3Cluster.Spec.ControlPlaneEndpointFromIPPool
+	if poolName == nil || s.Metal3Cluster.Spec.ControlPlaneEndpoint.Host != "" {
+		return nil
+	}
+
+	claimName := controlPlaneEndpointClaimName(s.Metal3Cluster.Name, *poolName)
+	ipClaim, err := fetchM3IPClaim(ctx, s.client, s.Log, claimName,
+		s.Metal3Cluster.Namespace,
+	)
+	if err != nil {
+		if _, ok := err.(HasRequeueAfterError); !ok {
+			return err
+		}
+		// Create the claim
+		ipClaim = &ipamv1.IPClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claimName,
+				Namespace: s.Metal3Cluster.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: capm3.GroupVersion.String(),
+						Kind:       "Metal3Cluster",
+						Name:       s.Metal3Cluster.Name,
+						UID:        s.Metal3Cluster.UID,
+						Controller: pointer.BoolPtr(true),
+					},
+				},
+				Labels: map[string]string{
+					capi.ClusterLabelName: s.Cluster.Name,
+				},
+			},
+			Spec: ipamv1.IPClaimSpec{
+				Pool: corev1.ObjectReference{
+					Name:      *poolName,
+					Namespace: s.Metal3Cluster.Namespace,
+				},
+			},
+		}
+
+		err = createObject(s.client, ctx, ipClaim)
+		if err != nil {
+			if _, ok := err.(HasRequeueAfterError); !ok {
+				return err
+			}
+		}
+	}
+
+	if !s.ownsIPClaim(ipClaim) {
+		conditions.MarkFalse(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition,
+			capm3.ControlPlaneEndpointFailedReason, capi.ConditionSeverityError,
+			"IPClaim %s is not owned by the Metal3Cluster", claimName,
+		)
+		return errors.Errorf("IPClaim %s is not owned by Metal3Cluster %s",
+			claimName, s.Metal3Cluster.Name,
+		)
+	}
+
+	if ipClaim.Status.ErrorMessage != nil {
+		conditions.MarkFalse(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition,
+			capm3.ControlPlaneEndpointFailedReason, capi.ConditionSeverityError,
+			"IP Allocation for %v failed : %v", *poolName, *ipClaim.Status.ErrorMessage,
+		)
+		return errors.Errorf("IP Allocation for %v failed : %v", *poolName,
+			*ipClaim.Status.ErrorMessage,
+		)
+	}
+
+	waitingErr := &RequeueAfterError{RequeueAfter: requeueAfter,
+		Reason: "waiting for the control plane endpoint IP address",
+	}
+	// verify if allocation is there, if not requeue
+	if ipClaim.Status.Address == nil {
+		conditions.MarkFalse(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition,
+			capm3.WaitingForControlPlaneEndpointReason, capi.ConditionSeverityInfo,
+			"Waiting for an IP address from %s", *poolName,
+		)
+		return waitingErr
+	}
+
+	ipAddress := &ipamv1.IPAddress{}
+	addressNamespacedName := types.NamespacedName{
+		Name:      ipClaim.Status.Address.Name,
+		Namespace: s.Metal3Cluster.Namespace,
+	}
+	if err := s.client.Get(ctx, addressNamespacedName, ipAddress); err != nil {
+		if apierrors.IsNotFound(err) {
+			return waitingErr
+		}
+		return err
+	}
+	observeInterval(ipClaimWaitDuration, ipClaim.CreationTimestamp,
+		ipAddress.CreationTimestamp, s.Metal3Cluster.Namespace, *poolName,
+	)
+
+	s.Log.Info("Control plane endpoint allocated", "pool", *poolName,
+		"host", ipAddress.Spec.Address,
+	)
+	s.Metal3Cluster.Spec.ControlPlaneEndpoint.Host = string(ipAddress.Spec.Address)
 	return nil
 }
 
@@ -128,9 +250,59 @@ func (s *ClusterManager) ControlPlaneEndpoint() ([]capm3.APIEndpoint, error) {
 	}, nil
 }
 
-// Delete function, no-op for now
-func (s *ClusterManager) Delete() error {
-	return nil
+// Delete releases the IPClaim of the control plane endpoint, if it was
+// allocated from an IPPool, and sweeps the resources left behind by the
+// machines of the cluster. It asks for requeue until they are all deleted.
+func (s *ClusterManager) Delete(ctx context.Context) error {
+	if err := s.releaseControlPlaneEndpoint(ctx); err != nil {
+		return err
+	}
+	return s.sweepClusterResources(ctx)
+}
+
+// releaseControlPlaneEndpoint deletes the IPClaim of the control plane
+// endpoint, if it was allocated from an IPPool.
+func (s *ClusterManager) releaseControlPlaneEndpoint(ctx context.Context) error {
+	poolName := s.Metal3Cluster.Spec.ControlPlaneEndpointFromIPPool
+	if poolName == nil {
+		return nil
+	}
+
+	ipClaim, err := fetchM3IPClaim(ctx, s.client, s.Log,
+		controlPlaneEndpointClaimName(s.Metal3Cluster.Name, *poolName),
+		s.Metal3Cluster.Namespace,
+	)
+	if err != nil {
+		if _, ok := err.(HasRequeueAfterError); ok {
+			// The claim is already released
+			return nil
+		}
+		return err
+	}
+	if !s.ownsIPClaim(ipClaim) {
+		// The claim was not created for the Metal3Cluster, leave it
+		return nil
+	}
+
+	return deleteObject(s.client, ctx, ipClaim)
+}
+
+// controlPlaneEndpointClaimName returns the name of the IPClaim of the control
+// plane endpoint. The "cpe" infix avoids collisions with the IPClaims of the
+// Metal3Datas, named after the Metal3Data and the pool.
+func controlPlaneEndpointClaimName(metal3ClusterName, poolName string) string {
+	return metal3ClusterName + "-cpe-" + poolName
+}
+
+// ownsIPClaim returns true if the Metal3Cluster is an owner of the IPClaim.
+func (s *ClusterManager) ownsIPClaim(ipClaim *ipamv1.IPClaim) bool {
+	for _, ownerRef := range ipClaim.OwnerReferences {
+		if ownerRef.Kind == "Metal3Cluster" &&
+			ownerRef.Name == s.Metal3Cluster.Name {
+			return true
+		}
+	}
+	return false
 }
 
 // UpdateClusterStatus updates a metal3Cluster object's status.
@@ -142,11 +314,19 @@ func (s *ClusterManager) UpdateClusterStatus() error {
 	if err != nil {
 		s.Metal3Cluster.Status.Ready = false
 		s.setError("Invalid ControlPlaneEndpoint values", capierrors.InvalidConfigurationClusterError)
+		conditions.MarkFalse(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition,
+			capm3.ControlPlaneEndpointFailedReason, capi.ConditionSeverityError,
+			"Invalid ControlPlaneEndpoint values",
+		)
 		return err
 	}
 
+	// Publish the failure domains for Cluster API to spread the machines
+	s.Metal3Cluster.Status.FailureDomains = s.Metal3Cluster.Spec.FailureDomains.DeepCopy()
+
 	// Mark the metal3Cluster ready
 	s.Metal3Cluster.Status.Ready = true
+	conditions.MarkTrue(s.Metal3Cluster, capm3.BaremetalInfrastructureReadyCondition)
 	now := metav1.Now()
 	s.Metal3Cluster.Status.LastUpdated = &now
 	return nil
@@ -187,6 +367,13 @@ func (s *ClusterManager) CountDescendants(ctx context.Context) (int, error) {
 			"metal3Cluster still has descendants - need to requeue", "descendants",
 			nbDescendants,
 		)
+		if !s.Metal3Cluster.DeletionTimestamp.IsZero() {
+			conditions.MarkFalse(s.Metal3Cluster,
+				capm3.BaremetalInfrastructureReadyCondition,
+				capm3.DeletionBlockedReason, capi.ConditionSeverityInfo,
+				"Waiting for the deletion of %d Machines", nbDescendants,
+			)
+		}
 	}
 	return nbDescendants, nil
 }
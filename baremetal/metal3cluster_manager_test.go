@@ -25,13 +25,18 @@ import (
 
 	_ "github.com/go-logr/logr"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -60,6 +65,16 @@ type testCaseBMClusterManager struct {
 	ExpectSuccess bool
 }
 
+type testCaseControlPlaneEndpoint struct {
+	Host               string
+	IPClaim            *ipamv1.IPClaim
+	IPAddress          *ipamv1.IPAddress
+	ExpectError        bool
+	ExpectRequeue      bool
+	ExpectedHost       string
+	ExpectForeignClaim bool
+}
+
 type descendantsTestCase struct {
 	Machines            []*clusterv1.Machine
 	ExpectError         bool
@@ -183,7 +198,7 @@ var _ = Describe("Metal3Cluster manager", func() {
 		func(tc testCaseBMClusterManager) {
 			clusterMgr, err := newBMClusterSetup(tc)
 			Expect(err).NotTo(HaveOccurred())
-			err = clusterMgr.Delete()
+			err = clusterMgr.Delete(context.TODO())
 
 			if tc.ExpectSuccess {
 				Expect(err).NotTo(HaveOccurred())
@@ -307,6 +322,205 @@ var _ = Describe("Metal3Cluster manager", func() {
 		),
 	)
 
+	DescribeTable("Test allocateControlPlaneEndpoint",
+		func(tc testCaseControlPlaneEndpoint) {
+			spec := bmcSpec()
+			spec.ControlPlaneEndpoint.Host = tc.Host
+			spec.ControlPlaneEndpointFromIPPool = pointer.StringPtr("abc")
+			bmCluster := newMetal3Cluster(metal3ClusterName, bmcOwnerRef, spec,
+				nil,
+			)
+			objects := []runtime.Object{bmCluster}
+			if tc.IPClaim != nil {
+				objects = append(objects, tc.IPClaim)
+			}
+			if tc.IPAddress != nil {
+				objects = append(objects, tc.IPAddress)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr := &ClusterManager{
+				client:        c,
+				Metal3Cluster: bmCluster,
+				Cluster:       newCluster(clusterName),
+				Log:           klogr.New(),
+			}
+
+			err := clusterMgr.Create(context.TODO())
+			if tc.ExpectError || tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(Equal(tc.ExpectRequeue))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(bmCluster.Spec.ControlPlaneEndpoint.Host).To(
+				Equal(tc.ExpectedHost),
+			)
+			if tc.Host != "" {
+				return
+			}
+
+			ipClaim := &ipamv1.IPClaim{}
+			if tc.ExpectForeignClaim {
+				// The claim of another object is left untouched
+				Expect(clusterMgr.Delete(context.TODO())).To(Succeed())
+				err = c.Get(context.TODO(), types.NamespacedName{
+					Name:      metal3ClusterName + "-cpe-abc",
+					Namespace: namespaceName,
+				}, ipClaim)
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			err = c.Get(context.TODO(), types.NamespacedName{
+				Name:      metal3ClusterName + "-cpe-abc",
+				Namespace: namespaceName,
+			}, ipClaim)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ipClaim.Spec.Pool.Name).To(Equal("abc"))
+			Expect(ipClaim.OwnerReferences).To(HaveLen(1))
+			Expect(ipClaim.OwnerReferences[0].Name).To(Equal(metal3ClusterName))
+
+			Expect(clusterMgr.Delete(context.TODO())).To(Succeed())
+			err = c.Get(context.TODO(), types.NamespacedName{
+				Name:      metal3ClusterName + "-cpe-abc",
+				Namespace: namespaceName,
+			}, ipClaim)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(clusterMgr.Delete(context.TODO())).To(Succeed())
+		},
+		Entry("Host already set", testCaseControlPlaneEndpoint{
+			Host:         "192.168.111.249",
+			ExpectedHost: "192.168.111.249",
+		}),
+		Entry("IPClaim created", testCaseControlPlaneEndpoint{
+			ExpectRequeue: true,
+		}),
+		Entry("IPClaim owned by another object", testCaseControlPlaneEndpoint{
+			IPClaim: &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3ClusterName + "-cpe-abc",
+					Namespace: namespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Metal3Data", Name: metal3ClusterName + "-cpe"},
+					},
+				},
+				Spec: ipamv1.IPClaimSpec{
+					Pool: corev1.ObjectReference{Name: "abc"},
+				},
+				Status: ipamv1.IPClaimStatus{
+					Address: &corev1.ObjectReference{Name: "abc-192-168-111-10"},
+				},
+			},
+			ExpectError:        true,
+			ExpectForeignClaim: true,
+		}),
+		Entry("IPClaim failed", testCaseControlPlaneEndpoint{
+			IPClaim: &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3ClusterName + "-cpe-abc",
+					Namespace: namespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Metal3Cluster", Name: metal3ClusterName},
+					},
+				},
+				Spec: ipamv1.IPClaimSpec{
+					Pool: corev1.ObjectReference{Name: "abc"},
+				},
+				Status: ipamv1.IPClaimStatus{
+					ErrorMessage: pointer.StringPtr("pool exhausted"),
+				},
+			},
+			ExpectError: true,
+		}),
+		Entry("IPAddress not found", testCaseControlPlaneEndpoint{
+			IPClaim: &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3ClusterName + "-cpe-abc",
+					Namespace: namespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Metal3Cluster", Name: metal3ClusterName},
+					},
+				},
+				Spec: ipamv1.IPClaimSpec{
+					Pool: corev1.ObjectReference{Name: "abc"},
+				},
+				Status: ipamv1.IPClaimStatus{
+					Address: &corev1.ObjectReference{Name: "abc-192-168-111-10"},
+				},
+			},
+			ExpectRequeue: true,
+		}),
+		Entry("IPAddress allocated", testCaseControlPlaneEndpoint{
+			IPClaim: &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3ClusterName + "-cpe-abc",
+					Namespace: namespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Metal3Cluster", Name: metal3ClusterName},
+					},
+				},
+				Spec: ipamv1.IPClaimSpec{
+					Pool: corev1.ObjectReference{Name: "abc"},
+				},
+				Status: ipamv1.IPClaimStatus{
+					Address: &corev1.ObjectReference{Name: "abc-192-168-111-10"},
+				},
+			},
+			IPAddress: &ipamv1.IPAddress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "abc-192-168-111-10",
+					Namespace: namespaceName,
+				},
+				Spec: ipamv1.IPAddressSpec{
+					Address: "192.168.111.10",
+				},
+			},
+			ExpectedHost: "192.168.111.10",
+		}),
+	)
+
+	It("Publishes the failure domains in the BMCluster status", func() {
+		spec := bmcSpec()
+		spec.FailureDomainLabelKey = "topology.metal3.io/rack"
+		spec.FailureDomains = clusterv1.FailureDomains{
+			"rack-1": clusterv1.FailureDomainSpec{ControlPlane: true},
+			"rack-2": clusterv1.FailureDomainSpec{ControlPlane: false},
+		}
+		clusterMgr, err := newBMClusterSetup(testCaseBMClusterManager{
+			Cluster: newCluster(clusterName),
+			BMCluster: newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+				spec, nil,
+			),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus()).To(Succeed())
+		Expect(clusterMgr.Metal3Cluster.Status.FailureDomains).To(
+			Equal(spec.FailureDomains),
+		)
+	})
+
+	It("Sets the BaremetalInfrastructureReady condition", func() {
+		clusterMgr, err := newBMClusterSetup(testCaseBMClusterManager{
+			Cluster: newCluster(clusterName),
+			BMCluster: newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+				bmcSpecAPIEmpty(), nil,
+			),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.Create(context.TODO())).NotTo(Succeed())
+		Expect(conditions.GetReason(clusterMgr.Metal3Cluster,
+			infrav1.BaremetalInfrastructureReadyCondition,
+		)).To(Equal(infrav1.InvalidConfigurationReason))
+
+		clusterMgr.Metal3Cluster.Spec = *bmcSpec()
+		Expect(clusterMgr.UpdateClusterStatus()).To(Succeed())
+		Expect(conditions.IsTrue(clusterMgr.Metal3Cluster,
+			infrav1.BaremetalInfrastructureReadyCondition,
+		)).To(BeTrue())
+	})
+
 	var descendantsTestCases = []TableEntry{
 		Entry("No Cluster Descendants", descendantsTestCase{
 			Machines:            []*clusterv1.Machine{},
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// sweepClusterResources deletes the Metal3DataTemplates, the IPClaims and the
+// secrets created by CAPM3 that are labelled with the name of the cluster, and
+// removes the cluster label from the BMC secrets of the BareMetalHosts. The
+// objects with finalizers are only gone once their controller released them,
+// so it reports them in the BaremetalInfrastructureReady condition and asks
+// for requeue until they are deleted.
+func (s *ClusterManager) sweepClusterResources(ctx context.Context) error {
+	listOptions := []client.ListOption{
+		client.InNamespace(s.Metal3Cluster.Namespace),
+		client.MatchingLabels{capi.ClusterLabelName: s.Cluster.Name},
+	}
+	blocking := []string{}
+
+	m3dtList := capm3.Metal3DataTemplateList{}
+	if err := s.client.List(ctx, &m3dtList, listOptions...); err != nil {
+		return errors.Wrap(err, "failed to list the Metal3DataTemplates")
+	}
+	for i := range m3dtList.Items {
+		m3dt := &m3dtList.Items[i]
+		if err := s.sweepObject(ctx, m3dt, "Metal3DataTemplate", &blocking); err != nil {
+			return err
+		}
+	}
+
+	ipClaimList := ipamv1.IPClaimList{}
+	if err := s.client.List(ctx, &ipClaimList, listOptions...); err != nil {
+		return errors.Wrap(err, "failed to list the IPClaims")
+	}
+	for i := range ipClaimList.Items {
+		ipClaim := &ipClaimList.Items[i]
+		if err := s.sweepObject(ctx, ipClaim, "IPClaim", &blocking); err != nil {
+			return err
+		}
+	}
+
+	if err := s.sweepSecrets(ctx, listOptions, &blocking); err != nil {
+		return err
+	}
+
+	if len(blocking) > 0 {
+		s.Log.Info("Waiting for the deletion of the cluster resources",
+			"resources", blocking,
+		)
+		conditions.MarkFalse(s.Metal3Cluster,
+			capm3.BaremetalInfrastructureReadyCondition,
+			capm3.DeletionBlockedReason, capi.ConditionSeverityInfo,
+			"Waiting for the deletion of %s", strings.Join(blocking, ", "),
+		)
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the deletion of the cluster resources",
+		}
+	}
+	return nil
+}
+
+// sweepSecrets deletes the user data, metadata and network data secrets of
+// the cluster, and removes the cluster label from the BMC secrets. The
+// secrets of Cluster API, such as the kubeconfig, are left to it.
+func (s *ClusterManager) sweepSecrets(ctx context.Context,
+	listOptions []client.ListOption, blocking *[]string,
+) error {
+	secretList := corev1.SecretList{}
+	if err := s.client.List(ctx, &secretList, listOptions...); err != nil {
+		return errors.Wrap(err, "failed to list the secrets")
+	}
+	if len(secretList.Items) == 0 {
+		return nil
+	}
+
+	hostList := bmh.BareMetalHostList{}
+	if err := s.client.List(ctx, &hostList,
+		client.InNamespace(s.Metal3Cluster.Namespace),
+	); err != nil {
+		return errors.Wrap(err, "failed to list the BareMetalHosts")
+	}
+	bmcSecrets := map[string]bool{}
+	for _, host := range hostList.Items {
+		bmcSecrets[host.Spec.BMC.CredentialsName] = true
+	}
+
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		switch {
+		case secret.Type == metal3SecretType:
+			if err := s.sweepObject(ctx, secret, "Secret", blocking); err != nil {
+				return err
+			}
+		case bmcSecrets[secret.Name]:
+			s.Log.Info("Deleting cluster label from BMC credential",
+				"secret", secret.Name,
+			)
+			delete(secret.Labels, capi.ClusterLabelName)
+			if err := updateObject(s.client, ctx, secret); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sweepObject deletes the object if it is not being deleted yet, and adds it
+// to the blocking objects if it has finalizers.
+func (s *ClusterManager) sweepObject(ctx context.Context, obj runtime.Object,
+	kind string, blocking *[]string,
+) error {
+	objMeta, ok := obj.(metav1.Object)
+	if !ok {
+		return errors.Errorf("%s is not a metav1.Object", kind)
+	}
+	if objMeta.GetDeletionTimestamp().IsZero() {
+		s.Log.Info("Deleting the cluster resource", "kind", kind,
+			"name", objMeta.GetName(),
+		)
+		if err := deleteObject(s.client, ctx, obj); err != nil {
+			return err
+		}
+	}
+	if len(objMeta.GetFinalizers()) > 0 {
+		*blocking = append(*blocking, kind+" "+objMeta.GetName())
+	}
+	return nil
+}
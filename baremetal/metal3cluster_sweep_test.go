@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metal3Cluster deletion sweep", func() {
+
+	clusterObjectMeta := func(name, cluster string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespaceName,
+			Labels:    map[string]string{capi.ClusterLabelName: cluster},
+		}
+	}
+
+	sweepSetup := func(objects ...runtime.Object) (*ClusterManager, client.Client) {
+		now := metav1.Now()
+		bmCluster := newMetal3Cluster(metal3ClusterName, bmcOwnerRef, nil, nil)
+		bmCluster.DeletionTimestamp = &now
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+		return &ClusterManager{
+			client:        c,
+			Metal3Cluster: bmCluster,
+			Cluster:       newCluster(clusterName),
+			Log:           klogr.New(),
+		}, c
+	}
+
+	exists := func(c client.Client, name string, obj runtime.Object) bool {
+		err := c.Get(context.TODO(), client.ObjectKey{
+			Name:      name,
+			Namespace: namespaceName,
+		}, obj)
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	It("Sweeps the resources of the cluster", func() {
+		clusterMgr, c := sweepSetup(
+			&capm3.Metal3DataTemplate{
+				ObjectMeta: clusterObjectMeta("m3dt", clusterName),
+			},
+			&capm3.Metal3DataTemplate{
+				ObjectMeta: clusterObjectMeta("m3dt-other", "other"),
+			},
+			&ipamv1.IPClaim{
+				ObjectMeta: clusterObjectMeta("m3d-pool", clusterName),
+			},
+			&corev1.Secret{
+				ObjectMeta: clusterObjectMeta("m3m-user-data", clusterName),
+				Type:       metal3SecretType,
+			},
+			&corev1.Secret{
+				ObjectMeta: clusterObjectMeta(clusterName+"-kubeconfig",
+					clusterName,
+				),
+				Type: capi.ClusterSecretType,
+			},
+			&corev1.Secret{
+				ObjectMeta: clusterObjectMeta("bmc-secret", clusterName),
+			},
+			&bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "host",
+					Namespace: namespaceName,
+				},
+				Spec: bmh.BareMetalHostSpec{
+					BMC: bmh.BMCDetails{CredentialsName: "bmc-secret"},
+				},
+			},
+		)
+
+		Expect(clusterMgr.Delete(context.TODO())).To(Succeed())
+
+		Expect(exists(c, "m3dt", &capm3.Metal3DataTemplate{})).To(BeFalse())
+		Expect(exists(c, "m3dt-other", &capm3.Metal3DataTemplate{})).To(BeTrue())
+		Expect(exists(c, "m3d-pool", &ipamv1.IPClaim{})).To(BeFalse())
+		Expect(exists(c, "m3m-user-data", &corev1.Secret{})).To(BeFalse())
+		Expect(exists(c, clusterName+"-kubeconfig", &corev1.Secret{})).To(
+			BeTrue(),
+		)
+		bmcSecret := &corev1.Secret{}
+		Expect(exists(c, "bmc-secret", bmcSecret)).To(BeTrue())
+		Expect(bmcSecret.Labels).NotTo(HaveKey(capi.ClusterLabelName))
+	})
+
+	It("Reports the resources blocking the deletion", func() {
+		ipClaim := &ipamv1.IPClaim{
+			ObjectMeta: clusterObjectMeta("m3d-pool", clusterName),
+		}
+		ipClaim.Finalizers = []string{ipamv1.IPClaimFinalizer}
+		clusterMgr, _ := sweepSetup(ipClaim)
+
+		err := clusterMgr.Delete(context.TODO())
+		Expect(err).To(HaveOccurred())
+		_, ok := err.(HasRequeueAfterError)
+		Expect(ok).To(BeTrue())
+
+		condition := conditions.Get(clusterMgr.Metal3Cluster,
+			capm3.BaremetalInfrastructureReadyCondition,
+		)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(capm3.DeletionBlockedReason))
+		Expect(condition.Message).To(ContainSubstring("IPClaim m3d-pool"))
+	})
+
+	It("Reports the machines blocking the deletion", func() {
+		clusterMgr, _ := sweepSetup(newCluster(clusterName), &capi.Machine{
+			ObjectMeta: clusterObjectMeta("machine", clusterName),
+		})
+
+		Expect(clusterMgr.CountDescendants(context.TODO())).To(Equal(1))
+		Expect(conditions.GetReason(clusterMgr.Metal3Cluster,
+			capm3.BaremetalInfrastructureReadyCondition,
+		)).To(Equal(capm3.DeletionBlockedReason))
+	})
+})
@@ -34,9 +34,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
@@ -51,19 +53,21 @@ type DataManagerInterface interface {
 
 // DataManager is responsible for performing machine reconciliation
 type DataManager struct {
-	client client.Client
-	Data   *capm3.Metal3Data
-	Log    logr.Logger
+	client   client.Client
+	recorder record.EventRecorder
+	Data     *capm3.Metal3Data
+	Log      logr.Logger
 }
 
 // NewDataManager returns a new helper for managing a Metal3Data object
-func NewDataManager(client client.Client,
+func NewDataManager(client client.Client, recorder record.EventRecorder,
 	data *capm3.Metal3Data, dataLog logr.Logger) (*DataManager, error) {
 
 	return &DataManager{
-		client: client,
-		Data:   data,
-		Log:    dataLog,
+		client:   client,
+		recorder: recorder,
+		Data:     data,
+		Log:      dataLog,
 	}, nil
 }
 
@@ -98,11 +102,26 @@ func (m *DataManager) Reconcile(ctx context.Context) error {
 
 	if err := m.createSecrets(ctx); err != nil {
 		if _, ok := errors.Cause(err).(HasRequeueAfterError); ok {
+			conditions.MarkFalse(m.Data, capm3.SecretsReadyCondition,
+				capm3.WaitingForSecretsRenderingReason, capi.ConditionSeverityInfo,
+				"Waiting for the IP addresses, the Machine or the BareMetalHost",
+			)
 			return err
 		}
 		m.setError(ctx, errors.Cause(err).Error())
+		conditions.MarkFalse(m.Data, capm3.SecretsReadyCondition,
+			capm3.Metal3DataSecretsErrorReason, capi.ConditionSeverityError,
+			"%s", errors.Cause(err).Error(),
+		)
+		recordEvent(m.recorder, m.Data, corev1.EventTypeWarning,
+			SecretRenderingFailedReason, "Failed to render the secrets: %s",
+			errors.Cause(err).Error(),
+		)
 		return err
 	}
+	if m.Data.Status.Ready {
+		conditions.MarkTrue(m.Data, capm3.SecretsReadyCondition)
+	}
 
 	return nil
 }
@@ -210,7 +229,9 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 	}
 	if capiMachine == nil {
 		m.Log.Info("Waiting for Machine Controller to set OwnerRef on Metal3Machine")
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the Machine",
+		}
 	}
 	m.Log.Info("Fetched Machine")
 
@@ -220,7 +241,9 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 		return err
 	}
 	if bmh == nil {
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the BareMetalHost",
+		}
 	}
 	m.Log.Info("Fetched BMH")
 
@@ -237,8 +260,16 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 
 	// The MetaData secret must be created
 	if apierrors.IsNotFound(metaDataErr) {
+		// Fetch the Metal3Cluster if the control plane VIP must be rendered
+		m3c, err := m.getControlPlaneVIPCluster(ctx, capiMachine)
+		if err != nil {
+			return err
+		}
+
 		m.Log.Info("Creating Metadata secret")
-		metadata, err := renderMetaData(m.Data, m3dt, m3m, capiMachine, bmh, poolAddresses)
+		metadata, err := renderMetaData(m.Data, m3dt, m3m, capiMachine, bmh,
+			poolAddresses, m3c,
+		)
 		if err != nil {
 			return err
 		}
@@ -458,7 +489,9 @@ func (m *DataManager) getAddressesFromPool(ctx context.Context,
 		}
 	}
 	if requeue {
-		return addresses, &RequeueAfterError{RequeueAfter: requeueAfter}
+		return addresses, &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the IP addresses",
+		}
 	}
 	return addresses, nil
 }
@@ -617,7 +650,9 @@ func (m *DataManager) releaseAddressesFromPool(ctx context.Context,
 		}
 	}
 	if requeue {
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the IP address leases to be released",
+		}
 	}
 	return nil
 }
@@ -704,6 +739,26 @@ func (m *DataManager) getAddressFromPool(ctx context.Context, poolName string,
 		return addresses, false, err
 	}
 
+	// Observe the allocation of the address once, when it first appears. The
+	// metric is best effort: if the IPClaim can not be marked, the wait is not
+	// observed and the rendering goes on.
+	if _, ok := ipClaim.Annotations[ipClaimObservedAnnotation]; !ok {
+		claimPatch := client.MergeFrom(ipClaim.DeepCopy())
+		if ipClaim.Annotations == nil {
+			ipClaim.Annotations = map[string]string{}
+		}
+		ipClaim.Annotations[ipClaimObservedAnnotation] = ""
+		if err := m.client.Patch(ctx, ipClaim, claimPatch); err != nil {
+			m.Log.Info("Failed to mark the IPClaim allocation as observed",
+				"ipclaim", ipClaim.Name, "error", err.Error(),
+			)
+		} else {
+			observeInterval(ipClaimWaitDuration, ipClaim.CreationTimestamp,
+				ipAddress.CreationTimestamp, m.Data.Namespace, poolName,
+			)
+		}
+	}
+
 	gateway := ipamv1.IPAddressStr("")
 	if ipAddress.Spec.Gateway != nil {
 		gateway = *ipAddress.Spec.Gateway
@@ -1086,10 +1141,11 @@ func getLinkMacAddress(mac *capm3.NetworkLinkEthernetMac, bmh *bmo.BareMetalHost
 	return mac_address, err
 }
 
-// renderMetaData renders the MetaData items
+// renderMetaData renders the MetaData items. The static pod manifest of the
+// control plane VIP is rendered if the Metal3Cluster is given.
 func renderMetaData(m3d *capm3.Metal3Data, m3dt *capm3.Metal3DataTemplate,
 	m3m *capm3.Metal3Machine, machine *capi.Machine, bmh *bmo.BareMetalHost,
-	poolAddresses map[string]addressFromPool,
+	poolAddresses map[string]addressFromPool, m3c *capm3.Metal3Cluster,
 ) ([]byte, error) {
 	if m3dt.Spec.MetaData == nil {
 		return nil, nil
@@ -1187,6 +1243,15 @@ func renderMetaData(m3d *capm3.Metal3Data, m3dt *capm3.Metal3DataTemplate,
 		}
 	}
 
+	// Control plane VIP
+	if m3c != nil && m3c.Spec.ControlPlaneVIP != nil {
+		manifest, err := renderControlPlaneVIPManifest(m3c)
+		if err != nil {
+			return nil, err
+		}
+		metadata[ControlPlaneVIPManifestKey] = manifest
+	}
+
 	// Strings
 	for _, entry := range m3dt.Spec.MetaData.Strings {
 		metadata[entry.Key] = entry.Value
@@ -1224,7 +1289,9 @@ func (m *DataManager) getM3Machine(ctx context.Context, m3dt *capm3.Metal3DataTe
 
 	if err := m.client.Get(ctx, claimNamespacedName, capm3DataClaim); err != nil {
 		if apierrors.IsNotFound(err) {
-			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Metal3DataClaim not found",
+			}
 		}
 		return nil, err
 	}
@@ -1264,7 +1331,9 @@ func fetchM3IPClaim(ctx context.Context, cl client.Client, mLog logr.Logger,
 	if err := cl.Get(ctx, metal3ClaimName, metal3IPClaim); err != nil {
 		if apierrors.IsNotFound(err) {
 			mLog.Info("Address claim not found, requeuing")
-			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "IPClaim not found",
+			}
 		} else {
 			err := errors.Wrap(err, "Failed to get address claim")
 			return nil, err
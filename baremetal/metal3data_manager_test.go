@@ -65,7 +65,7 @@ var (
 var _ = Describe("Metal3Data manager", func() {
 	DescribeTable("Test Finalizers",
 		func(data *infrav1.Metal3Data) {
-			machineMgr, err := NewDataManager(nil, data,
+			machineMgr, err := NewDataManager(nil, nil, data,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -92,7 +92,7 @@ var _ = Describe("Metal3Data manager", func() {
 
 	It("Test error handling", func() {
 		data := &infrav1.Metal3Data{}
-		dataMgr, err := NewDataManager(nil, data,
+		dataMgr, err := NewDataManager(nil, nil, data,
 			klogr.New(),
 		)
 		Expect(err).NotTo(HaveOccurred())
@@ -122,7 +122,7 @@ var _ = Describe("Metal3Data manager", func() {
 				objects = append(objects, tc.m3m)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, tc.m3d,
+			dataMgr, err := NewDataManager(c, nil, tc.m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -203,7 +203,7 @@ var _ = Describe("Metal3Data manager", func() {
 				objects = append(objects, tc.networkdataSecret)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, tc.m3d,
+			dataMgr, err := NewDataManager(c, nil, tc.m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -598,7 +598,7 @@ var _ = Describe("Metal3Data manager", func() {
 				objects = append(objects, tc.m3dt)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, tc.m3d,
+			dataMgr, err := NewDataManager(c, nil, tc.m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -692,7 +692,7 @@ var _ = Describe("Metal3Data manager", func() {
 				Spec: tc.m3dtSpec,
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, m3d,
+			dataMgr, err := NewDataManager(c, nil, m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1027,7 +1027,7 @@ var _ = Describe("Metal3Data manager", func() {
 				Spec: tc.m3dtSpec,
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, m3d,
+			dataMgr, err := NewDataManager(c, nil, m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1165,6 +1165,7 @@ var _ = Describe("Metal3Data manager", func() {
 		expectedAddresses map[string]addressFromPool
 		expectDataError   bool
 		expectClaim       bool
+		expectObserved    bool
 	}
 
 	DescribeTable("Test GetAddressFromPool",
@@ -1177,7 +1178,7 @@ var _ = Describe("Metal3Data manager", func() {
 				objects = append(objects, tc.ipClaim)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, tc.m3d,
+			dataMgr, err := NewDataManager(c, nil, tc.m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1213,6 +1214,16 @@ var _ = Describe("Metal3Data manager", func() {
 					tc.m3d.TypeMeta, tc.m3d.ObjectMeta)
 				Expect(err).NotTo(HaveOccurred())
 			}
+			if tc.ipClaim != nil {
+				capm3IPClaim := &ipamv1.IPClaim{}
+				err = dataMgr.client.Get(context.TODO(), types.NamespacedName{
+					Name:      tc.ipClaim.Name,
+					Namespace: tc.ipClaim.Namespace,
+				}, capm3IPClaim)
+				Expect(err).NotTo(HaveOccurred())
+				_, observed := capm3IPClaim.Annotations[ipClaimObservedAnnotation]
+				Expect(observed).To(Equal(tc.expectObserved))
+			}
 		},
 		Entry("Already processed", testCaseGetAddressFromPool{
 			m3d: &infrav1.Metal3Data{
@@ -1316,7 +1327,8 @@ var _ = Describe("Metal3Data manager", func() {
 					Namespace: "myns",
 				},
 			},
-			poolName: "abc",
+			poolName:       "abc",
+			expectObserved: true,
 			expectedAddresses: map[string]addressFromPool{
 				"abc": {
 					address: ipamv1.IPAddressStr("192.168.0.10"),
@@ -1374,7 +1386,7 @@ var _ = Describe("Metal3Data manager", func() {
 				objects = append(objects, tc.ipClaim)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
-			dataMgr, err := NewDataManager(c, tc.m3d,
+			dataMgr, err := NewDataManager(c, nil, tc.m3d,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2406,6 +2418,7 @@ var _ = Describe("Metal3Data manager", func() {
 		machine          *capi.Machine
 		bmh              *bmo.BareMetalHost
 		poolAddresses    map[string]addressFromPool
+		m3c              *infrav1.Metal3Cluster
 		expectedMetaData map[string]string
 		expectError      bool
 	}
@@ -2413,7 +2426,7 @@ var _ = Describe("Metal3Data manager", func() {
 	DescribeTable("Test renderMetaData",
 		func(tc testCaseRenderMetaData) {
 			resultBytes, err := renderMetaData(tc.m3d, tc.m3dt, tc.m3m, tc.machine,
-				tc.bmh, tc.poolAddresses,
+				tc.bmh, tc.poolAddresses, tc.m3c,
 			)
 			if tc.expectError {
 				Expect(err).To(HaveOccurred())
@@ -2990,7 +3003,7 @@ var _ = Describe("Metal3Data manager", func() {
 				Expect(err).NotTo(HaveOccurred())
 			}
 
-			machineMgr, err := NewDataManager(c, tc.Data,
+			machineMgr, err := NewDataManager(c, nil, tc.Data,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -21,7 +21,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -41,10 +41,12 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -59,6 +61,24 @@ const (
 	bmRoleControlPlane  = "control-plane"
 	bmRoleNode          = "node"
 	pausedAnnotationKey = "metal3.io/capm3"
+	// nodeSyncedLabelsAnnotation is the key for an annotation on the Nodes of
+	// the workload cluster listing the labels copied from the BareMetalHost and
+	// the Metal3Machine.
+	nodeSyncedLabelsAnnotation = "metal3.io/synced-labels"
+	// nodeSyncedTaintsAnnotation is the key for an annotation on the Nodes of
+	// the workload cluster listing the taints set from the Metal3Machine.
+	nodeSyncedTaintsAnnotation = "metal3.io/synced-taints"
+	// nodeSyncedAnnotationsAnnotation is the key for an annotation on the
+	// Nodes of the workload cluster listing the annotations copied from the
+	// BareMetalHost and the Metal3Machine.
+	nodeSyncedAnnotationsAnnotation = "metal3.io/synced-annotations"
+	// nodeDeletionRebootAnnotation is the key for an annotation on the
+	// Metal3Machine recording that its host was rebooted after the deletion
+	// of its Node.
+	nodeDeletionRebootAnnotation = "metal3.io/node-deletion-reboot"
+	// nodeSyncedAnnotation is the key for an annotation on the Metal3Machine
+	// recording that labels, annotations or taints were set on its Node.
+	nodeSyncedAnnotation = "metal3.io/node-synced"
 )
 
 // MachineManagerInterface is an interface for a ClusterManager
@@ -73,7 +93,10 @@ type MachineManagerInterface interface {
 	Update(context.Context) error
 	HasAnnotation() bool
 	GetProviderIDAndBMHID() (string, *string)
+	BuildProviderID(string) string
 	SetNodeProviderID(context.Context, string, string, ClientGetter) error
+	SyncNode(context.Context, ClientGetter) error
+	CheckNode(context.Context, ClientGetter) error
 	SetProviderID(string)
 	SetPauseAnnotation(context.Context) error
 	RemovePauseAnnotation(context.Context) error
@@ -84,7 +107,8 @@ type MachineManagerInterface interface {
 
 // MachineManager is responsible for performing machine reconciliation
 type MachineManager struct {
-	client client.Client
+	client   client.Client
+	recorder record.EventRecorder
 
 	Cluster       *capi.Cluster
 	Metal3Cluster *capm3.Metal3Cluster
@@ -94,13 +118,14 @@ type MachineManager struct {
 }
 
 // NewMachineManager returns a new helper for managing a machine
-func NewMachineManager(client client.Client,
+func NewMachineManager(client client.Client, recorder record.EventRecorder,
 	cluster *capi.Cluster, metal3Cluster *capm3.Metal3Cluster,
 	machine *capi.Machine, metal3machine *capm3.Metal3Machine,
 	machineLog logr.Logger) (*MachineManager, error) {
 
 	return &MachineManager{
-		client: client,
+		client:   client,
+		recorder: recorder,
 
 		Cluster:       cluster,
 		Metal3Cluster: metal3Cluster,
@@ -148,7 +173,7 @@ func (m *MachineManager) isControlPlane() bool {
 
 // role returns the machine role from the labels.
 func (m *MachineManager) role() string {
-	if util.IsControlPlaneMachine(m.Machine) {
+	if m.Machine != nil && util.IsControlPlaneMachine(m.Machine) {
 		return bmRoleControlPlane
 	}
 	return bmRoleNode
@@ -176,6 +201,9 @@ func (m *MachineManager) RemovePauseAnnotation(ctx context.Context) error {
 			if m.Cluster.Name == host.Labels[capi.ClusterLabelName] && annotations[bmh.PausedAnnotation] == pausedAnnotationKey {
 				// Removing BMH Paused Annotation Since Owner Cluster is not paused
 				delete(host.Annotations, bmh.PausedAnnotation)
+				recordEvent(m.recorder, host, corev1.EventTypeNormal,
+					UnpausedReason, "Unpaused, cluster %s is not paused", m.Cluster.Name,
+				)
 			} else if m.Cluster.Name == host.Labels[capi.ClusterLabelName] && annotations[bmh.PausedAnnotation] != pausedAnnotationKey {
 				m.Log.Info("BMH is paused by user. Not removing Pause Annotation")
 				return nil
@@ -221,6 +249,9 @@ func (m *MachineManager) SetPauseAnnotation(ctx context.Context) error {
 		return errors.Wrap(err, "failed to marshall status annotation")
 	}
 	host.Annotations[bmh.StatusAnnotation] = string(newAnnotation)
+	recordEvent(m.recorder, host, corev1.EventTypeNormal, PausedReason,
+		"Paused by Metal3Machine %s", m.Metal3Machine.Name,
+	)
 	return helper.Patch(ctx, host)
 }
 
@@ -236,7 +267,9 @@ func (m *MachineManager) GetBaremetalHostID(ctx context.Context) (*string, error
 	}
 	if host == nil {
 		m.Log.Info("BaremetalHost not associated, requeuing")
-		return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+		return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the BareMetalHost",
+		}
 	}
 	if host.Status.Provisioning.State == bmh.StateProvisioned {
 		return pointer.StringPtr(string(host.ObjectMeta.UID)), nil
@@ -260,46 +293,77 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	err := config.IsValid()
 	if err != nil {
 		// Should have been picked earlier. Do not requeue
-		m.SetError(err.Error(), capierrors.InvalidConfigurationMachineError)
+		m.setConditionError(capm3.AssociateBMHCondition,
+			capm3.AssociateBMHFailedReason, err.Error(),
+			capierrors.InvalidConfigurationMachineError,
+		)
 		return nil
 	}
 
 	// clear an error if one was previously set
 	m.clearError()
+	m.Metal3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseAssociating)
 
 	// look for associated BMH
 	host, helper, err := m.getHost(ctx)
 	if err != nil {
-		m.SetError("Failed to get the BaremetalHost for the Metal3Machine",
+		m.setConditionError(capm3.AssociateBMHCondition,
+			capm3.AssociateBMHFailedReason, "Failed to get the BaremetalHost for the Metal3Machine",
 			capierrors.CreateMachineError,
 		)
 		return err
 	}
 
 	// no BMH found, trying to choose from available ones
+	chosen := false
 	if host == nil {
 		host, helper, err = m.chooseHost(ctx)
 		if err != nil {
-			m.SetError("Failed to pick a BaremetalHost for the Metal3Machine",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to pick a BaremetalHost for the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 			return err
 		}
 		if host == nil {
 			m.Log.Info("No available host found. Requeuing.")
-			return &RequeueAfterError{RequeueAfter: requeueAfter}
+			conditions.MarkFalse(m.Metal3Machine, capm3.AssociateBMHCondition,
+				capm3.NoHostAvailableReason, capi.ConditionSeverityWarning,
+				"No available host found",
+			)
+			return &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "no available BareMetalHost",
+			}
 		}
 		m.Log.Info("Associating machine with host", "host", host.Name)
+		delete(host.Labels, capm3.NodeReuseLabelName)
+		err = m.claimHost(ctx, host)
+		if err != nil {
+			if _, ok := err.(HasRequeueAfterError); !ok {
+				m.setConditionError(capm3.AssociateBMHCondition,
+					capm3.AssociateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
+					capierrors.CreateMachineError,
+				)
+			}
+			return err
+		}
+		helper, err = patch.NewHelper(host, m.client)
+		if err != nil {
+			return errors.Wrap(err, "failed to init patch helper")
+		}
+		chosen = true
 	} else {
 		m.Log.Info("Machine already associated with host", "host", host.Name)
 	}
+	m.Metal3Machine.Status.HostSelection = nil
 
 	// A machine bootstrap not ready case is caught in the controller
 	// ReconcileNormal function
 	err = m.getUserData(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to set the UserData for the Metal3Machine",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to set the UserData for the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
@@ -309,7 +373,8 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	err = m.setHostLabel(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to set the Cluster label in the BareMetalHost",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to set the Cluster label in the BareMetalHost",
 				capierrors.CreateMachineError,
 			)
 		}
@@ -319,7 +384,8 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	err = m.setHostConsumerRef(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to associate the BaremetalHost to the Metal3Machine",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
@@ -331,7 +397,8 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	if m.Metal3Machine.Spec.DataTemplate == nil {
 		if err = m.setHostSpec(ctx, host); err != nil {
 			if _, ok := err.(HasRequeueAfterError); !ok {
-				m.SetError("Failed to associate the BaremetalHost to the Metal3Machine",
+				m.setConditionError(capm3.AssociateBMHCondition,
+					capm3.AssociateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
 					capierrors.CreateMachineError,
 				)
 			}
@@ -342,7 +409,8 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	err = m.setBMCSecretLabel(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to associate the BaremetalHost to the Metal3Machine",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
@@ -364,13 +432,23 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	err = m.ensureAnnotation(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to annotate the Metal3Machine",
+			m.setConditionError(capm3.AssociateBMHCondition,
+				capm3.AssociateBMHFailedReason, "Failed to annotate the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
 		return err
 	}
 
+	conditions.MarkTrue(m.Metal3Machine, capm3.AssociateBMHCondition)
+	if chosen {
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+			AssociatedReason, "Associated with BareMetalHost %s", host.Name,
+		)
+		recordEvent(m.recorder, host, corev1.EventTypeNormal, AssociatedReason,
+			"Associated with Metal3Machine %s", m.Metal3Machine.Name,
+		)
+	}
 	m.Log.Info("Finished associating machine")
 	return nil
 }
@@ -461,6 +539,7 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 
 	// clear an error if one was previously set
 	m.clearError()
+	m.Metal3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseDeprovisioning)
 
 	host, helper, err := m.getHost(ctx)
 	if err != nil {
@@ -500,6 +579,12 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 			}
 		}
 
+		// Label the host before deprovisioning it, so that the next machine of
+		// the same owner waits for it if node reuse is required
+		if err := m.setNodeReuseLabel(ctx, host); err != nil {
+			return err
+		}
+
 		bmhUpdated := false
 
 		if host.Spec.Image != nil {
@@ -530,6 +615,13 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 				return err
 			}
 
+			recordEvent(m.recorder, host, corev1.EventTypeNormal,
+				DeprovisioningReason, "Released by Metal3Machine %s",
+				m.Metal3Machine.Name,
+			)
+			recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+				DeprovisioningReason, "Deprovisioning BareMetalHost %s", host.Name,
+			)
 			m.Log.Info("Deprovisioning BaremetalHost, requeuing")
 			return &RequeueAfterError{}
 		}
@@ -549,7 +641,9 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 		}
 		if waiting {
 			m.Log.Info("Deprovisioning BaremetalHost, requeuing")
-			return &RequeueAfterError{RequeueAfter: requeueAfter}
+			return &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "waiting for the BareMetalHost to be deprovisioned",
+			}
 		}
 
 		host.Spec.ConsumerRef = nil
@@ -596,6 +690,10 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 		if err := patchIfFound(ctx, helper, host); err != nil {
 			return err
 		}
+
+		observeDuration(machineDeprovisioningDuration,
+			m.Metal3Machine.DeletionTimestamp, m.role(),
+		)
 	}
 
 	m.Log.Info("finished deleting metal3 machine")
@@ -615,6 +713,10 @@ func (m *MachineManager) Update(ctx context.Context) error {
 		return err
 	}
 	if host == nil {
+		conditions.MarkFalse(m.Metal3Machine, capm3.HostProvisionedCondition,
+			capm3.MissingBMHReason, capi.ConditionSeverityError,
+			"host not found for machine %s", m.Machine.Name,
+		)
 		return fmt.Errorf("host not found for machine %s", m.Machine.Name)
 	}
 
@@ -631,18 +733,23 @@ func (m *MachineManager) Update(ctx context.Context) error {
 	err = m.setHostConsumerRef(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to associate the BaremetalHost to the Metal3Machine",
+			m.setConditionError(capm3.HostProvisionedCondition,
+				capm3.UpdateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
 		return err
 	}
 
+	// deprovision the host if its image is upgraded in place
+	m.reconcileImageUpgrade(host)
+
 	// ensure that the BMH specs are correctly set
 	err = m.setHostSpec(ctx, host)
 	if err != nil {
 		if _, ok := err.(HasRequeueAfterError); !ok {
-			m.SetError("Failed to associate the BaremetalHost to the Metal3Machine",
+			m.setConditionError(capm3.HostProvisionedCondition,
+				capm3.UpdateBMHFailedReason, "Failed to associate the BaremetalHost to the Metal3Machine",
 				capierrors.CreateMachineError,
 			)
 		}
@@ -663,6 +770,8 @@ func (m *MachineManager) Update(ctx context.Context) error {
 		return err
 	}
 
+	m.setHostProvisionedCondition(host)
+
 	m.Log.Info("Finished updating machine")
 	return nil
 }
@@ -728,7 +837,9 @@ func getHost(ctx context.Context, m3Machine *capm3.Metal3Machine, cl client.Clie
 
 // chooseHost iterates through known hosts and returns one that can be
 // associated with the metal3 machine. It searches all hosts in case one already has an
-// association with this metal3 machine.
+// association with this metal3 machine. Otherwise, the available hosts are
+// filtered and the one with the best score, according to the HostScoring of
+// the metal3 machine, is chosen.
 func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, *patch.Helper, error) {
 
 	// get list of BMH
@@ -743,6 +854,64 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, *p
 		return nil, nil, err
 	}
 
+	for i, host := range hosts.Items {
+		if host.Spec.ConsumerRef != nil && consumerRefMatches(host.Spec.ConsumerRef, m.Metal3Machine) {
+			m.Log.Info("Found host with existing ConsumerRef", "host", host.Name)
+			helper, err := patch.NewHelper(&hosts.Items[i], m.client)
+			return &hosts.Items[i], helper, err
+		}
+	}
+
+	labelSelector, err := m.hostLabelSelector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	availableHosts, rejections := m.filterHosts(hosts.Items, labelSelector)
+	availableHosts, err = m.applyAntiAffinity(ctx, availableHosts, hosts.Items,
+		rejections,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	availableHosts, err = m.applyNodeReuse(ctx, availableHosts, hosts.Items,
+		rejections,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.Log.Info(fmt.Sprintf("%d hosts available while choosing host for Metal3 machine", len(availableHosts)))
+	if len(availableHosts) == 0 {
+		m.Metal3Machine.Status.HostSelection = hostSelectionStatus(
+			len(hosts.Items), rejections,
+		)
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeWarning,
+			NoHostAvailableReason, "No available host out of %d, rejected hosts: %s",
+			len(hosts.Items), rejectionSummary(rejections),
+		)
+		return nil, nil, nil
+	}
+
+	scorer, err := NewHostScorer(m.Metal3Machine.Spec.HostScoring)
+	if err != nil {
+		m.Log.Error(err, "Failed to create the host scorer, not choosing host")
+		return nil, nil, err
+	}
+	chosenHost := selectHost(availableHosts,
+		scorer.Score(availableHosts, hosts.Items),
+	)
+	recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+		HostChosenReason, "Chose host %s out of %d candidates, rejected hosts: %s",
+		chosenHost.Name, len(availableHosts), rejectionSummary(rejections),
+	)
+
+	helper, err := patch.NewHelper(chosenHost, m.client)
+	return chosenHost, helper, err
+}
+
+// hostLabelSelector builds the label selector matching the HostSelector of the
+// metal3 machine.
+func (m *MachineManager) hostLabelSelector() (labels.Selector, error) {
 	// Using the label selector on ListOptions above doesn't seem to work.
 	// I think it's because we have a local cache of all BareMetalHosts.
 	labelSelector := labels.NewSelector()
@@ -755,7 +924,7 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, *p
 		r, err := labels.NewRequirement(labelKey, selection.Equals, []string{labelVal})
 		if err != nil {
 			m.Log.Error(err, "Failed to create MatchLabel requirement, not choosing host")
-			return nil, nil, err
+			return nil, err
 		}
 		reqs = append(reqs, *r)
 	}
@@ -768,32 +937,39 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, *p
 		r, err := labels.NewRequirement(req.Key, lowercaseOperator, req.Values)
 		if err != nil {
 			m.Log.Error(err, "Failed to create MatchExpression requirement, not choosing host")
-			return nil, nil, err
+			return nil, err
 		}
 		reqs = append(reqs, *r)
 	}
-	labelSelector = labelSelector.Add(reqs...)
+	return labelSelector.Add(reqs...), nil
+}
 
+// filterHosts returns the hosts that are free to be consumed by the metal3
+// machine and that match the label selector built from its HostSelector,
+// along with the number of hosts rejected for each reason.
+func (m *MachineManager) filterHosts(hosts []bmh.BareMetalHost,
+	labelSelector labels.Selector,
+) ([]*bmh.BareMetalHost, map[string]int) {
 	availableHosts := []*bmh.BareMetalHost{}
+	rejections := map[string]int{}
 
-	for i, host := range hosts.Items {
-		if host.Spec.ConsumerRef != nil && consumerRefMatches(host.Spec.ConsumerRef, m.Metal3Machine) {
-			m.Log.Info("Found host with existing ConsumerRef", "host", host.Name)
-			helper, err := patch.NewHelper(&hosts.Items[i], m.client)
-			return &hosts.Items[i], helper, err
-		}
+	for i, host := range hosts {
 		if host.Spec.ConsumerRef != nil {
+			rejections[hostRejectedConsumed]++
 			continue
 		}
 		if host.GetDeletionTimestamp() != nil {
+			rejections[hostRejectedDeleting]++
 			continue
 		}
 		if host.Status.ErrorMessage != "" {
+			rejections[hostRejectedError]++
 			continue
 		}
 		switch host.Status.Provisioning.State {
 		case bmh.StateReady, bmh.StateAvailable:
 		default:
+			rejections[hostRejectedProvisioningState]++
 			continue
 		}
 
@@ -801,31 +977,81 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, *p
 		annotations := host.GetAnnotations()
 		if annotations != nil {
 			if _, ok := annotations[bmh.PausedAnnotation]; ok {
+				rejections[hostRejectedPaused]++
 				continue
 			}
 			if _, ok := annotations[capm3.UnhealthyAnnotation]; ok {
+				rejections[hostRejectedUnhealthy]++
 				continue
 			}
 		}
 
-		if labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
-			m.Log.Info("Host matched hostSelector for Metal3Machine", "host", host.Name)
-			availableHosts = append(availableHosts, &hosts.Items[i])
-		} else {
+		if !labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
 			m.Log.Info("Host did not match hostSelector for Metal3Machine", "host", host.Name)
+			rejections[hostRejectedLabelMismatch]++
+			continue
 		}
+		m.Log.Info("Host matched hostSelector for Metal3Machine", "host", host.Name)
+
+		if !m.hostInFailureDomain(&hosts[i]) {
+			m.Log.Info("Host is not in the failure domain of the Machine",
+				"host", host.Name,
+			)
+			rejections[hostRejectedFailureDomain]++
+			continue
+		}
+
+		err := checkHardwareRequirements(&hosts[i],
+			m.Metal3Machine.Spec.HardwareRequirements,
+		)
+		if err != nil {
+			m.Log.Info("Host did not match hardwareRequirements for Metal3Machine",
+				"host", host.Name, "reason", err.Error(),
+			)
+			rejections[hostRejectedHardwareRequirements]++
+			continue
+		}
+		availableHosts = append(availableHosts, &hosts[i])
 	}
-	m.Log.Info(fmt.Sprintf("%d hosts available while choosing host for Metal3 machine", len(availableHosts)))
-	if len(availableHosts) == 0 {
-		return nil, nil, nil
-	}
+	return availableHosts, rejections
+}
 
-	// choose a host at random from available hosts
-	rand.Seed(time.Now().Unix())
-	chosenHost := availableHosts[rand.Intn(len(availableHosts))]
+// hostSelectionStatus builds the status reporting the number of hosts rejected
+// for each reason.
+func hostSelectionStatus(hosts int, rejections map[string]int,
+) *capm3.HostSelectionStatus {
+	return &capm3.HostSelectionStatus{
+		Hosts: hosts,
+		Rejected: capm3.HostRejections{
+			Consumed:             rejections[hostRejectedConsumed],
+			Deleting:             rejections[hostRejectedDeleting],
+			Error:                rejections[hostRejectedError],
+			ProvisioningState:    rejections[hostRejectedProvisioningState],
+			Paused:               rejections[hostRejectedPaused],
+			Unhealthy:            rejections[hostRejectedUnhealthy],
+			LabelMismatch:        rejections[hostRejectedLabelMismatch],
+			FailureDomain:        rejections[hostRejectedFailureDomain],
+			HardwareRequirements: rejections[hostRejectedHardwareRequirements],
+			AntiAffinity:         rejections[hostRejectedAntiAffinity],
+			NodeReuse:            rejections[hostRejectedNodeReuse],
+		},
+	}
+}
 
-	helper, err := patch.NewHelper(chosenHost, m.client)
-	return chosenHost, helper, err
+// hostInFailureDomain returns whether the host belongs to the failure domain
+// of the machine, based on the failure domain label key of the metal3 cluster.
+// All hosts match if the machine has no failure domain or if the metal3
+// cluster does not define a label key.
+func (m *MachineManager) hostInFailureDomain(host *bmh.BareMetalHost) bool {
+	if m.Machine == nil || m.Machine.Spec.FailureDomain == nil ||
+		*m.Machine.Spec.FailureDomain == "" {
+		return true
+	}
+	if m.Metal3Cluster == nil || m.Metal3Cluster.Spec.FailureDomainLabelKey == "" {
+		return true
+	}
+	value, ok := host.Labels[m.Metal3Cluster.Spec.FailureDomainLabelKey]
+	return ok && value == *m.Machine.Spec.FailureDomain
 }
 
 // consumerRefMatches returns a boolean based on whether the consumer
@@ -900,11 +1126,14 @@ func (m *MachineManager) setHostSpec(ctx context.Context, host *bmh.BareMetalHos
 	// We only want to update the image setting if the host does not
 	// already have an image.
 	//
-	// A host with an existing image is already provisioned and
-	// upgrades are not supported at this time. To re-provision a
-	// host, we must fully deprovision it and then provision it again.
-	// Not provisioning while we do not have the UserData
-	if host.Spec.Image == nil && m.Metal3Machine.Status.UserData != nil {
+	// A host with an existing image is already provisioned. To
+	// re-provision a host, we must fully deprovision it and then provision
+	// it again, which reconcileImageUpgrade does if the metal3 machine
+	// allows in-place upgrades.
+	// Not provisioning while we do not have the UserData, nor while the host
+	// is deprovisioned for an in-place upgrade
+	if host.Spec.Image == nil && m.Metal3Machine.Status.UserData != nil &&
+		!m.upgradeDeprovisioning() {
 		checksumType := ""
 		if m.Metal3Machine.Spec.Image.ChecksumType != nil {
 			checksumType = *m.Metal3Machine.Spec.Image.ChecksumType
@@ -933,6 +1162,14 @@ func (m *MachineManager) setHostSpec(ctx context.Context, host *bmh.BareMetalHos
 		if host.Spec.NetworkData != nil && host.Spec.NetworkData.Namespace == "" {
 			host.Spec.NetworkData.Namespace = m.Machine.Namespace
 		}
+		recordEvent(m.recorder, host, corev1.EventTypeNormal,
+			ProvisioningStartedReason, "Provisioning image %s for Metal3Machine %s",
+			host.Spec.Image.URL, m.Metal3Machine.Name,
+		)
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+			ProvisioningStartedReason, "Provisioning BareMetalHost %s with image %s",
+			host.Name, host.Spec.Image.URL,
+		)
 	}
 
 	host.Spec.Online = true
@@ -961,6 +1198,24 @@ func (m *MachineManager) setHostConsumerRef(ctx context.Context, host *bmh.BareM
 	return nil
 }
 
+// claimHost sets the consumerRef of the chosen host with an update. The update
+// fails with a conflict if the host was modified since it was listed, for
+// example because another Metal3Machine claimed it, so that the host is not
+// associated twice when several Metal3Machines are reconciled concurrently.
+func (m *MachineManager) claimHost(ctx context.Context, host *bmh.BareMetalHost) error {
+	err := m.setHostConsumerRef(ctx, host)
+	if err != nil {
+		return err
+	}
+	err = m.client.Update(ctx, host)
+	if apierrors.IsConflict(err) {
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "the chosen BareMetalHost was modified",
+		}
+	}
+	return err
+}
+
 // ensureAnnotation makes sure the machine has an annotation that references the
 // host and uses the API to update the machine if necessary.
 func (m *MachineManager) ensureAnnotation(ctx context.Context, host *bmh.BareMetalHost) error {
@@ -1004,6 +1259,17 @@ func (m *MachineManager) SetError(message string, reason capierrors.MachineStatu
 	m.Metal3Machine.Status.FailureReason = &reason
 }
 
+// setConditionError sets the ErrorMessage and ErrorReason fields on the
+// machine and marks the condition false with the same message.
+func (m *MachineManager) setConditionError(conditionType capi.ConditionType,
+	conditionReason, message string, reason capierrors.MachineStatusError,
+) {
+	m.SetError(message, reason)
+	conditions.MarkFalse(m.Metal3Machine, conditionType, conditionReason,
+		capi.ConditionSeverityError, "%s", message,
+	)
+}
+
 // clearError removes the ErrorMessage from the machine's Status if set. Returns
 // nil if ErrorMessage was already nil. Returns a RequeueAfterError if the
 // machine was updated.
@@ -1029,10 +1295,53 @@ func (m *MachineManager) updateMachineStatus(ctx context.Context, host *bmh.Bare
 	now := metav1.Now()
 	m.Metal3Machine.Status.LastUpdated = &now
 	m.Metal3Machine.Status.Addresses = addrs
+	m.Metal3Machine.Status.SetTypedPhase(m.hostPhase(host))
 
 	return nil
 }
 
+// hostPhase returns the phase of the machine derived from the provisioning
+// state of the host and from the in-place image upgrade, if any.
+func (m *MachineManager) hostPhase(host *bmh.BareMetalHost) capm3.Metal3MachinePhase {
+	if host.HasError() {
+		return capm3.Metal3MachinePhaseFailed
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateRegistrationError, bmh.StateProvisioningError,
+		bmh.StatePowerManagementError:
+		return capm3.Metal3MachinePhaseFailed
+	case bmh.StateDeprovisioning:
+		return capm3.Metal3MachinePhaseDeprovisioning
+	case bmh.StateProvisioned, bmh.StateExternallyProvisioned:
+		upgrade := m.Metal3Machine.Status.ImageUpgrade
+		if upgrade != nil && upgrade.State == capm3.ImageUpgradeDeprovisioning {
+			return capm3.Metal3MachinePhaseDeprovisioning
+		}
+		if upgrade != nil && upgrade.State == capm3.ImageUpgradeProvisioning {
+			return capm3.Metal3MachinePhaseProvisioning
+		}
+		return capm3.Metal3MachinePhaseProvisioned
+	default:
+		return capm3.Metal3MachinePhaseProvisioning
+	}
+}
+
+// setHostProvisionedCondition reports the provisioning state of the host in
+// the HostProvisioned condition of the machine.
+func (m *MachineManager) setHostProvisionedCondition(host *bmh.BareMetalHost) {
+	upgrade := m.Metal3Machine.Status.ImageUpgrade
+	if host.Status.Provisioning.State == bmh.StateProvisioned &&
+		(upgrade == nil || upgrade.State == capm3.ImageUpgradeCompleted) {
+		conditions.MarkTrue(m.Metal3Machine, capm3.HostProvisionedCondition)
+		return
+	}
+	conditions.MarkFalse(m.Metal3Machine, capm3.HostProvisionedCondition,
+		capm3.WaitingForHostProvisioningReason, capi.ConditionSeverityInfo,
+		"BareMetalHost %s is in %s state", host.Name,
+		host.Status.Provisioning.State,
+	)
+}
+
 // NodeAddresses returns a slice of corev1.NodeAddress objects for a
 // given Metal3 machine.
 func (m *MachineManager) nodeAddresses(host *bmh.BareMetalHost) []capi.MachineAddress {
@@ -1065,12 +1374,35 @@ func (m *MachineManager) nodeAddresses(host *bmh.BareMetalHost) []capi.MachineAd
 	return addrs
 }
 
+// GetProviderIDAndBMHID returns the providerID of the Metal3Machine and the
+// BareMetalHost UID it contains. The UID is nil if the providerID is not set
+// or does not contain it.
 func (m *MachineManager) GetProviderIDAndBMHID() (string, *string) {
 	providerID := m.Metal3Machine.Spec.ProviderID
 	if providerID == nil {
 		return "", nil
 	}
-	return *providerID, pointer.StringPtr(parseProviderID(*providerID))
+	parts, err := parseProviderID(*providerID)
+	if err != nil || parts.HostUID == "" {
+		return *providerID, nil
+	}
+	return *providerID, pointer.StringPtr(parts.HostUID)
+}
+
+// BuildProviderID returns the providerID of the Metal3Machine for the
+// BareMetalHost, in the providerIDFormat of the Metal3Cluster.
+func (m *MachineManager) BuildProviderID(bmhID string) string {
+	if m.Metal3Cluster != nil &&
+		m.Metal3Cluster.Spec.ProviderIDFormat == capm3.ProviderIDFormatName {
+		hostKey := m.Metal3Machine.Annotations[HostAnnotation]
+		hostNamespace, hostName, err := cache.SplitMetaNamespaceKey(hostKey)
+		if err == nil && hostNamespace != "" && hostName != "" {
+			return fmt.Sprintf("%s%s/%s/%s", providerIDPrefix, hostNamespace,
+				hostName, m.Metal3Machine.Name,
+			)
+		}
+	}
+	return providerIDPrefix + bmhID
 }
 
 // ClientGetter prototype
@@ -1078,11 +1410,15 @@ type ClientGetter func(ctx context.Context, c client.Client, cluster *capi.Clust
 
 // SetNodeProviderID sets the metal3 provider ID on the kubernetes node
 func (m *MachineManager) SetNodeProviderID(ctx context.Context, bmhID, providerID string, clientFactory ClientGetter) error {
-	if !m.Metal3Cluster.Spec.NoCloudProvider {
+	if !m.setsNodeProviderID() {
 		return nil
 	}
 	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
 	if err != nil {
+		conditions.MarkFalse(m.Metal3Machine, capm3.KubernetesNodeReadyCondition,
+			capm3.SettingProviderIDOnNodeFailedReason, capi.ConditionSeverityWarning,
+			"Error creating a remote client: %v", err,
+		)
 		return errors.Wrap(err, "Error creating a remote client")
 	}
 
@@ -1091,31 +1427,518 @@ func (m *MachineManager) SetNodeProviderID(ctx context.Context, bmhID, providerI
 	})
 	if err != nil {
 		m.Log.Info(fmt.Sprintf("error while accessing cluster: %v", err))
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		conditions.MarkFalse(m.Metal3Machine, capm3.KubernetesNodeReadyCondition,
+			capm3.SettingProviderIDOnNodeFailedReason, capi.ConditionSeverityWarning,
+			"error while accessing cluster: %v", err,
+		)
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the target cluster",
+		}
 	}
 	if len(nodes.Items) == 0 {
-		// The node could either be still running cloud-init or have been
-		// deleted manually. TODO: handle a manual deletion case
+		// The node is still running cloud-init. The manual deletion of the
+		// node of a provisioned machine is handled by CheckNode.
+		// The Metal3Machine is also reconciled when the node joins if the
+		// controller watches the nodes of the cluster.
 		m.Log.Info("Target node is not found, requeuing")
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		conditions.MarkFalse(m.Metal3Machine, capm3.KubernetesNodeReadyCondition,
+			capm3.WaitingForNodeReason, capi.ConditionSeverityInfo,
+			"Waiting for the node with the metal3.io/uuid=%v label", bmhID,
+		)
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the Node",
+		}
 	}
 	for _, node := range nodes.Items {
 		if node.Spec.ProviderID == providerID {
 			continue
 		}
+		// The providerID set by the cloud provider is kept
+		if !m.Metal3Cluster.Spec.NoCloudProvider && node.Spec.ProviderID != "" {
+			continue
+		}
 		node.Spec.ProviderID = providerID
 		_, err = corev1Remote.Nodes().Update(ctx, &node, metav1.UpdateOptions{})
 		if err != nil {
+			conditions.MarkFalse(m.Metal3Machine, capm3.KubernetesNodeReadyCondition,
+				capm3.SettingProviderIDOnNodeFailedReason, capi.ConditionSeverityError,
+				"unable to update the target node: %v", err,
+			)
 			return errors.Wrap(err, "unable to update the target node")
 		}
 	}
 	m.Log.Info("ProviderID set on target node")
+	conditions.MarkTrue(m.Metal3Machine, capm3.KubernetesNodeReadyCondition)
+
+	return nil
+}
+
+// setsNodeProviderID returns true if the providerID is set on the nodes, either
+// without cloud provider or, with one, on the nodes that have none if
+// setProviderIDOnNodes is true on the Metal3Cluster.
+func (m *MachineManager) setsNodeProviderID() bool {
+	return m.Metal3Cluster != nil && (m.Metal3Cluster.Spec.NoCloudProvider ||
+		m.Metal3Cluster.Spec.SetProviderIDOnNodes)
+}
+
+// CheckNode checks that the kubernetes node of a provisioned Metal3Machine
+// still exists, when deploying without cloud provider. A node registered again
+// without providerID, for example after a reboot, gets it back. If the node is
+// gone, the KubernetesNodeReady condition is set to false and, after the grace
+// period of the nodeDeletion policy of the Metal3Cluster, its action is taken.
+// With a cloud provider and setProviderIDOnNodes, the existing node without
+// providerID gets it, and a missing node is left to the cloud provider.
+func (m *MachineManager) CheckNode(ctx context.Context, clientFactory ClientGetter) error {
+	if !m.setsNodeProviderID() || m.Metal3Machine.Spec.ProviderID == nil {
+		return nil
+	}
+	providerID := *m.Metal3Machine.Spec.ProviderID
+	host, err := getHost(ctx, m.Metal3Machine, m.client, m.Log)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return nil
+	}
+
+	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
+	if err != nil {
+		m.Log.Info(fmt.Sprintf("error creating a remote client: %v", err))
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the target cluster",
+		}
+	}
+	nodes, err := corev1Remote.Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: "metal3.io/uuid",
+	})
+	if err != nil {
+		m.Log.Info(fmt.Sprintf("error while accessing cluster: %v", err))
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the target cluster",
+		}
+	}
+
+	node := findNode(nodes.Items, host, providerID)
+	if node == nil {
+		if !m.Metal3Cluster.Spec.NoCloudProvider {
+			return nil
+		}
+		return m.handleMissingNode(ctx, host, providerID)
+	}
+
+	if node.Spec.ProviderID == "" {
+		node.Spec.ProviderID = providerID
+		_, err = corev1Remote.Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		if err != nil {
+			m.Log.Info(fmt.Sprintf("unable to update the target node: %v", err))
+			return &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "failed to set the providerID on the registered node",
+			}
+		}
+		m.Log.Info("ProviderID set on the registered target node", "node", node.Name)
+	}
+	delete(m.Metal3Machine.Annotations, nodeDeletionRebootAnnotation)
+	conditions.MarkTrue(m.Metal3Machine, capm3.KubernetesNodeReadyCondition)
+	return nil
+}
+
+// handleMissingNode reports the deletion of the node of a provisioned
+// Metal3Machine, and takes the action of the nodeDeletion policy of the
+// Metal3Cluster once the node has been missing for its grace period.
+func (m *MachineManager) handleMissingNode(ctx context.Context,
+	host *bmh.BareMetalHost, providerID string,
+) error {
+	if conditions.GetReason(m.Metal3Machine,
+		capm3.KubernetesNodeReadyCondition,
+	) != capm3.NodeNotFoundReason {
+		m.Log.Info("Target node was deleted", "providerID", providerID)
+		conditions.MarkFalse(m.Metal3Machine, capm3.KubernetesNodeReadyCondition,
+			capm3.NodeNotFoundReason, capi.ConditionSeverityWarning,
+			"The node with the providerID %s was deleted", providerID,
+		)
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeWarning,
+			NodeDeletedReason, "Node with the providerID %s was deleted",
+			providerID,
+		)
+	}
+
+	policy := m.Metal3Cluster.Spec.NodeDeletion
+	if policy == nil || policy.Action == "" ||
+		policy.Action == capm3.NodeDeletionActionNone {
+		return nil
+	}
+	gracePeriod := capm3.DefaultNodeDeletionGracePeriod
+	if policy.GracePeriod != nil {
+		gracePeriod = policy.GracePeriod.Duration
+	}
+	condition := conditions.Get(m.Metal3Machine, capm3.KubernetesNodeReadyCondition)
+	remaining := gracePeriod - time.Since(condition.LastTransitionTime.Time)
+	if remaining > 0 {
+		return &RequeueAfterError{RequeueAfter: remaining,
+			Reason: "waiting for the deleted node",
+		}
+	}
+
+	switch policy.Action {
+	case capm3.NodeDeletionActionReboot:
+		if _, ok := m.Metal3Machine.Annotations[nodeDeletionRebootAnnotation]; ok {
+			return nil
+		}
+		helper, err := patch.NewHelper(host, m.client)
+		if err != nil {
+			return errors.Wrap(err, "failed to init patch helper")
+		}
+		m.Log.Info("Rebooting the host of the deleted node", "host", host.Name)
+		if host.Annotations == nil {
+			host.Annotations = map[string]string{}
+		}
+		host.Annotations[capm3.RebootAnnotation] = ""
+		if err := helper.Patch(ctx, host); err != nil {
+			return errors.Wrap(err, "failed to set the reboot annotation on the host")
+		}
+		if m.Metal3Machine.Annotations == nil {
+			m.Metal3Machine.Annotations = map[string]string{}
+		}
+		m.Metal3Machine.Annotations[nodeDeletionRebootAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+			NodeDeletionRebootReason, "Rebooting host %s after the deletion of its node",
+			host.Name,
+		)
+	case capm3.NodeDeletionActionReplace:
+		if m.Machine == nil || !m.Machine.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		m.Log.Info("Deleting the Machine of the deleted node", "machine", m.Machine.Name)
+		if err := m.client.Delete(ctx, m.Machine); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to delete the Machine")
+		}
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+			NodeDeletionReplaceReason, "Deleting Machine %s after the deletion of its node",
+			m.Machine.Name,
+		)
+	}
+	return nil
+}
 
+// findNode returns the node with the providerID, or else the node labelled
+// with the UID of the host that has no metal3 providerID. The UID in the
+// providerID is the UID of the host before it was moved, if it was.
+func findNode(nodes []corev1.Node, host *bmh.BareMetalHost, providerID string,
+) *corev1.Node {
+	hostUIDs := map[string]bool{string(host.UID): true}
+	if parts, err := parseProviderID(providerID); err == nil && parts.HostUID != "" {
+		hostUIDs[parts.HostUID] = true
+	}
+	var node *corev1.Node
+	for i := range nodes {
+		if nodes[i].Spec.ProviderID == providerID {
+			return &nodes[i]
+		}
+		if !strings.HasPrefix(nodes[i].Spec.ProviderID, providerIDPrefix) &&
+			hostUIDs[nodes[i].Labels["metal3.io/uuid"]] {
+			node = &nodes[i]
+		}
+	}
+	return node
+}
+
+// SyncNode keeps the labels, annotations and taints of the kubernetes node in
+// sync once the providerID of the Metal3Machine is set and the Machine
+// references its node. The labels and annotations of the BareMetalHost and of
+// the Metal3Machine matching the nodeLabelPrefixes and nodeAnnotationPrefixes
+// of the Metal3Cluster are copied along with the nodeLabels of the
+// Metal3Machine, and its nodeTaints are set. The labels, annotations and taints
+// previously set that are gone are removed. The workload cluster is not
+// accessed if nothing is to be synced and nothing was synced before.
+func (m *MachineManager) SyncNode(ctx context.Context, clientFactory ClientGetter) error {
+	if m.Metal3Machine.Spec.ProviderID == nil || m.Machine == nil ||
+		m.Machine.Status.NodeRef == nil {
+		return nil
+	}
+	providerID := *m.Metal3Machine.Spec.ProviderID
+	host, err := getHost(ctx, m.Metal3Machine, m.client, m.Log)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return nil
+	}
+	syncedLabels := m.nodeLabels(host)
+	syncedAnnotations := m.nodeAnnotations(host)
+	syncing := len(syncedLabels) > 0 || len(syncedAnnotations) > 0 ||
+		len(m.Metal3Machine.Spec.NodeTaints) > 0
+	if _, synced := m.Metal3Machine.Annotations[nodeSyncedAnnotation]; !syncing && !synced {
+		return nil
+	}
+
+	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
+	if err != nil {
+		m.Log.Info(fmt.Sprintf("error creating a remote client: %v", err))
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the target cluster",
+		}
+	}
+	node, err := corev1Remote.Nodes().Get(ctx, m.Machine.Status.NodeRef.Name,
+		metav1.GetOptions{},
+	)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		m.Log.Info(fmt.Sprintf("error while accessing cluster: %v", err))
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the target cluster",
+		}
+	}
+	// The node referenced by the Machine must still be the node of the host
+	if findNode([]corev1.Node{*node}, host, providerID) == nil {
+		return nil
+	}
+	labelsChanged := setNodeLabels(node, syncedLabels)
+	annotationsChanged := setNodeAnnotations(node, syncedAnnotations)
+	taintsChanged := setNodeTaints(node, m.Metal3Machine.Spec.NodeTaints)
+	if labelsChanged || annotationsChanged || taintsChanged {
+		_, err = corev1Remote.Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		if err != nil {
+			m.Log.Info(fmt.Sprintf("unable to update the target node: %v", err))
+			return &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "failed to update the node labels, annotations and taints",
+			}
+		}
+		m.Log.Info("Labels, annotations and taints synced on target node",
+			"node", node.Name,
+		)
+	}
+
+	if !syncing {
+		delete(m.Metal3Machine.Annotations, nodeSyncedAnnotation)
+		return nil
+	}
+	if m.Metal3Machine.Annotations == nil {
+		m.Metal3Machine.Annotations = map[string]string{}
+	}
+	m.Metal3Machine.Annotations[nodeSyncedAnnotation] = ""
 	return nil
 }
 
+// nodeLabels returns the labels of the BareMetalHost and of the Metal3Machine
+// with one of the nodeLabelPrefixes of the Metal3Cluster, and the nodeLabels
+// of the Metal3Machine.
+func (m *MachineManager) nodeLabels(host *bmh.BareMetalHost) map[string]string {
+	syncedLabels := map[string]string{}
+	if m.Metal3Cluster != nil {
+		syncedLabels = prefixedValues(m.Metal3Cluster.Spec.NodeLabelPrefixes,
+			host.Labels, m.Metal3Machine.Labels,
+		)
+	}
+	for key, value := range m.Metal3Machine.Spec.NodeLabels {
+		syncedLabels[key] = value
+	}
+	return syncedLabels
+}
+
+// nodeAnnotations returns the annotations of the BareMetalHost and of the
+// Metal3Machine with one of the nodeAnnotationPrefixes of the Metal3Cluster.
+func (m *MachineManager) nodeAnnotations(host *bmh.BareMetalHost) map[string]string {
+	if m.Metal3Cluster == nil {
+		return map[string]string{}
+	}
+	syncedAnnotations := prefixedValues(
+		m.Metal3Cluster.Spec.NodeAnnotationPrefixes,
+		host.Annotations, m.Metal3Machine.Annotations,
+	)
+	// The annotations listing the synced keys are owned by SyncNode
+	for _, annotation := range []string{nodeSyncedLabelsAnnotation,
+		nodeSyncedAnnotationsAnnotation, nodeSyncedTaintsAnnotation,
+	} {
+		delete(syncedAnnotations, annotation)
+	}
+	return syncedAnnotations
+}
+
+// prefixedValues returns the entries of the sources with a key starting with
+// one of the prefixes, the last sources taking precedence.
+func prefixedValues(prefixes []string, sources ...map[string]string,
+) map[string]string {
+	values := map[string]string{}
+	for _, source := range sources {
+		for key, value := range source {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(key, prefix) {
+					values[key] = value
+					break
+				}
+			}
+		}
+	}
+	return values
+}
+
+// setNodeLabels sets the synced labels on the node, removes the labels listed
+// in its synced labels annotation that are not synced anymore, and updates the
+// annotation. It returns true if the node was modified.
+func setNodeLabels(node *corev1.Node, syncedLabels map[string]string) bool {
+	return setNodeValues(node, &node.Labels, nodeSyncedLabelsAnnotation,
+		syncedLabels,
+	)
+}
+
+// setNodeAnnotations sets the synced annotations on the node, removes the
+// annotations listed in its synced annotations annotation that are not synced
+// anymore, and updates the annotation. It returns true if the node was
+// modified.
+func setNodeAnnotations(node *corev1.Node, syncedAnnotations map[string]string) bool {
+	return setNodeValues(node, &node.Annotations,
+		nodeSyncedAnnotationsAnnotation, syncedAnnotations,
+	)
+}
+
+// setNodeValues sets the synced values in the labels or the annotations of the
+// node, removes the keys listed in the annotation that are not synced anymore,
+// and updates the annotation. It returns true if the node was modified.
+func setNodeValues(node *corev1.Node, values *map[string]string,
+	annotation string, synced map[string]string,
+) bool {
+	changed := false
+	for _, key := range syncedKeys(node, annotation) {
+		if _, ok := synced[key]; ok {
+			continue
+		}
+		if _, ok := (*values)[key]; ok {
+			delete(*values, key)
+			changed = true
+		}
+	}
+
+	keys := []string{}
+	for key, value := range synced {
+		keys = append(keys, key)
+		if current, ok := (*values)[key]; ok && current == value {
+			continue
+		}
+		if *values == nil {
+			*values = map[string]string{}
+		}
+		(*values)[key] = value
+		changed = true
+	}
+
+	if setSyncedKeys(node, annotation, keys) {
+		changed = true
+	}
+	return changed
+}
+
+// setNodeTaints sets the taints on the node, removes the taints listed in its
+// synced taints annotation that are not set anymore, and updates the
+// annotation. The taints are identified by their key and effect. It returns
+// true if the node was modified.
+func setNodeTaints(node *corev1.Node, taints []corev1.Taint) bool {
+	changed := false
+	wanted := map[string]corev1.Taint{}
+	keys := []string{}
+	for _, taint := range taints {
+		key := taintKey(taint)
+		wanted[key] = taint
+		keys = append(keys, key)
+	}
+	previous := map[string]bool{}
+	for _, key := range syncedKeys(node, nodeSyncedTaintsAnnotation) {
+		previous[key] = true
+	}
+
+	nodeTaints := []corev1.Taint{}
+	for _, taint := range node.Spec.Taints {
+		key := taintKey(taint)
+		wantedTaint, ok := wanted[key]
+		switch {
+		case ok:
+			if taint.Value != wantedTaint.Value {
+				taint.Value = wantedTaint.Value
+				changed = true
+			}
+			delete(wanted, key)
+		case previous[key]:
+			changed = true
+			continue
+		}
+		nodeTaints = append(nodeTaints, taint)
+	}
+	for _, taint := range taints {
+		if _, ok := wanted[taintKey(taint)]; ok {
+			nodeTaints = append(nodeTaints, taint)
+			changed = true
+		}
+	}
+	if changed {
+		node.Spec.Taints = nodeTaints
+	}
+
+	if setSyncedKeys(node, nodeSyncedTaintsAnnotation, keys) {
+		changed = true
+	}
+	return changed
+}
+
+// taintKey identifies a taint by its key and effect.
+func taintKey(taint corev1.Taint) string {
+	return taint.Key + ":" + string(taint.Effect)
+}
+
+// NodeHasSyncedValues returns true if the node has labels, annotations or
+// taints set by SyncNode, which are then to be kept in sync.
+func NodeHasSyncedValues(node *corev1.Node) bool {
+	for _, annotation := range []string{nodeSyncedLabelsAnnotation,
+		nodeSyncedAnnotationsAnnotation, nodeSyncedTaintsAnnotation,
+	} {
+		if node.Annotations[annotation] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// syncedKeys returns the keys listed in the annotation of the node.
+func syncedKeys(node *corev1.Node, annotation string) []string {
+	synced := node.Annotations[annotation]
+	if synced == "" {
+		return nil
+	}
+	return strings.Split(synced, ",")
+}
+
+// setSyncedKeys lists the keys in the annotation of the node, removing the
+// annotation if there is none. It returns true if the node was modified.
+func setSyncedKeys(node *corev1.Node, annotation string, keys []string) bool {
+	sort.Strings(keys)
+	synced := strings.Join(keys, ",")
+	if synced == node.Annotations[annotation] {
+		return false
+	}
+	if synced == "" {
+		delete(node.Annotations, annotation)
+		return true
+	}
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[annotation] = synced
+	return true
+}
+
 // SetProviderID sets the metal3 provider ID on the metal3machine
 func (m *MachineManager) SetProviderID(providerID string) {
+	if !m.Metal3Machine.Status.Ready {
+		observeDuration(machineProvisioningDuration,
+			&m.Metal3Machine.CreationTimestamp, m.role(),
+		)
+	}
+	if m.Metal3Machine.Spec.ProviderID == nil ||
+		*m.Metal3Machine.Spec.ProviderID != providerID {
+		recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+			ProviderIDSetReason, "Provider ID set to %s", providerID,
+		)
+	}
 	m.Metal3Machine.Spec.ProviderID = &providerID
 	m.Metal3Machine.Status.Ready = true
 }
@@ -1297,9 +2120,18 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 			m.Metal3Machine.Name, m.Metal3Machine.Namespace,
 		)
 		if err != nil {
+			conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+				capm3.AssociateM3MetaDataFailedReason, capi.ConditionSeverityError,
+				"%s", err.Error(),
+			)
 			return err
 		}
 		if metal3DataClaim == nil {
+			conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+				capm3.WaitingForMetal3DataReason, capi.ConditionSeverityInfo,
+				"Waiting for the Metal3DataClaim to be created",
+			)
+			m.Metal3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseWaitingForData)
 			return &RequeueAfterError{}
 		}
 
@@ -1307,7 +2139,15 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 			metal3DataClaim.Status.RenderedData.Name != "" {
 			m.Metal3Machine.Status.RenderedData = metal3DataClaim.Status.RenderedData
 		} else {
-			return &RequeueAfterError{RequeueAfter: requeueAfter}
+			conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+				capm3.WaitingForMetal3DataReason, capi.ConditionSeverityInfo,
+				"Waiting for the Metal3DataClaim %s to be rendered",
+				metal3DataClaim.Name,
+			)
+			m.Metal3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseWaitingForData)
+			return &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "waiting for the Metal3DataClaim",
+			}
 		}
 	}
 
@@ -1316,16 +2156,31 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 		m.Metal3Machine.Status.RenderedData.Name, m.Metal3Machine.Namespace,
 	)
 	if err != nil {
+		conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+			capm3.AssociateM3MetaDataFailedReason, capi.ConditionSeverityError,
+			"%s", err.Error(),
+		)
 		return err
 	}
 	if metal3Data == nil {
+		conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+			capm3.AssociateM3MetaDataFailedReason, capi.ConditionSeverityError,
+			"Unexpected nil rendered data",
+		)
 		return errors.New("Unexpected nil rendered data")
 	}
 
 	// If it is not ready yet, wait.
 	if !metal3Data.Status.Ready {
 		// Secret generation not ready
-		return &RequeueAfterError{RequeueAfter: requeueAfter}
+		conditions.MarkFalse(m.Metal3Machine, capm3.Metal3DataReadyCondition,
+			capm3.WaitingForMetal3DataReason, capi.ConditionSeverityInfo,
+			"Waiting for the Metal3Data %s to be ready", metal3Data.Name,
+		)
+		m.Metal3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseWaitingForData)
+		return &RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting for the Metal3Data",
+		}
 	}
 
 	// Get the secrets if given in Metal3Data and not already set
@@ -1349,6 +2204,7 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 		}
 	}
 
+	conditions.MarkTrue(m.Metal3Machine, capm3.Metal3DataReadyCondition)
 	return nil
 }
 
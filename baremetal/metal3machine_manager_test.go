@@ -36,12 +36,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientfake "k8s.io/client-go/kubernetes/fake"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -287,7 +289,7 @@ func bmhStatus() *bmh.BareMetalHostStatus {
 var _ = Describe("Metal3Machine manager", func() {
 	DescribeTable("Test Finalizers",
 		func(bmMachine capm3.Metal3Machine) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &bmMachine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &bmMachine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -314,7 +316,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test SetProviderID",
 		func(bmMachine capm3.Metal3Machine) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &bmMachine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &bmMachine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -342,7 +344,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test IsProvisioned",
 		func(tc testCaseProvisioned) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &tc.M3Machine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -391,7 +393,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test BootstrapReady",
 		func(tc testCaseBootstrapReady) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, &tc.Machine, nil,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &tc.Machine, nil,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -416,7 +418,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test setting and clearing errors",
 		func(bmMachine capm3.Metal3Machine) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &bmMachine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &bmMachine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -542,7 +544,7 @@ var _ = Describe("Metal3Machine manager", func() {
 		DescribeTable("Test ChooseHost",
 			func(tc testCaseChooseHost) {
 				c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), tc.Hosts...)
-				machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 					tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -637,6 +639,196 @@ var _ = Describe("Metal3Machine manager", func() {
 		)
 	})
 
+	Describe("Test filterHosts", func() {
+		readyHost := func(name string, hostLabels map[string]string,
+		) bmh.BareMetalHost {
+			host := *newBareMetalHost(name, nil, bmh.StateReady, nil, false, false)
+			host.Labels = hostLabels
+			host.Status.Provisioning.State = bmh.StateReady
+			return host
+		}
+
+		availableHost := readyHost("availableHost", map[string]string{"key1": "value1"})
+		availableHost.Status.Provisioning.State = bmh.StateAvailable
+		readyHostNoLabel := readyHost("readyHostNoLabel", nil)
+		consumedHost := readyHost("consumedHost", map[string]string{"key1": "value1"})
+		consumedHost.Spec.ConsumerRef = consumerRefSome()
+		deletingHost := readyHost("deletingHost", map[string]string{"key1": "value1"})
+		deletingHost.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		errorHost := readyHost("errorHost", map[string]string{"key1": "value1"})
+		errorHost.Status.ErrorMessage = "Boom"
+		provisionedHost := readyHost("provisionedHost", map[string]string{"key1": "value1"})
+		provisionedHost.Status.Provisioning.State = bmh.StateProvisioned
+		pausedHost := readyHost("pausedHost", map[string]string{"key1": "value1"})
+		pausedHost.Annotations = map[string]string{bmh.PausedAnnotation: ""}
+		unhealthyHost := readyHost("unhealthyHost", map[string]string{"key1": "value1"})
+		unhealthyHost.Annotations = map[string]string{capm3.UnhealthyAnnotation: ""}
+		bigRAMHost := readyHost("bigRAMHost", nil)
+		bigRAMHost.Status.HardwareDetails = &bmh.HardwareDetails{RAMMebibytes: 65536}
+
+		type testCaseFilterHosts struct {
+			Hosts                []bmh.BareMetalHost
+			MatchLabels          map[string]string
+			MatchExpressions     []capm3.HostSelectorRequirement
+			HardwareRequirements *capm3.HardwareRequirements
+			ExpectedHostNames    []string
+			ExpectedRejections   map[string]int
+			ExpectError          bool
+		}
+
+		DescribeTable("Test filterHosts",
+			func(tc testCaseFilterHosts) {
+				m3mconfig, _ := newConfig("", tc.MatchLabels, tc.MatchExpressions)
+				m3mconfig.Spec.HardwareRequirements = tc.HardwareRequirements
+				machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil,
+					m3mconfig, klogr.New(),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				labelSelector, err := machineMgr.hostLabelSelector()
+				if tc.ExpectError {
+					Expect(err).To(HaveOccurred())
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				result, rejections := machineMgr.filterHosts(tc.Hosts,
+					labelSelector,
+				)
+				resultNames := []string{}
+				for _, host := range result {
+					resultNames = append(resultNames, host.Name)
+				}
+				Expect(resultNames).To(Equal(tc.ExpectedHostNames))
+				Expect(rejections).To(Equal(tc.ExpectedRejections))
+			},
+			Entry("Only keep available hosts", testCaseFilterHosts{
+				Hosts: []bmh.BareMetalHost{availableHost, consumedHost,
+					deletingHost, errorHost, provisionedHost, pausedHost,
+					unhealthyHost, readyHostNoLabel,
+				},
+				ExpectedHostNames: []string{"availableHost", "readyHostNoLabel"},
+				ExpectedRejections: map[string]int{
+					hostRejectedConsumed:          1,
+					hostRejectedDeleting:          1,
+					hostRejectedError:             1,
+					hostRejectedProvisioningState: 1,
+					hostRejectedPaused:            1,
+					hostRejectedUnhealthy:         1,
+				},
+			}),
+			Entry("Only keep hosts matching the labels", testCaseFilterHosts{
+				Hosts:             []bmh.BareMetalHost{availableHost, readyHostNoLabel},
+				MatchLabels:       map[string]string{"key1": "value1"},
+				ExpectedHostNames: []string{"availableHost"},
+				ExpectedRejections: map[string]int{
+					hostRejectedLabelMismatch: 1,
+				},
+			}),
+			Entry("Only keep hosts matching the hardware requirements", testCaseFilterHosts{
+				Hosts: []bmh.BareMetalHost{availableHost, bigRAMHost},
+				HardwareRequirements: &capm3.HardwareRequirements{
+					MinRAMMebibytes: 32768,
+				},
+				ExpectedHostNames: []string{"bigRAMHost"},
+				ExpectedRejections: map[string]int{
+					hostRejectedHardwareRequirements: 1,
+				},
+			}),
+			Entry("Invalid match expression", testCaseFilterHosts{
+				Hosts: []bmh.BareMetalHost{availableHost, readyHostNoLabel},
+				MatchExpressions: []capm3.HostSelectorRequirement{
+					{
+						Key:      "key1",
+						Operator: "pancakes",
+						Values:   []string{"value1"},
+					},
+				},
+				ExpectError: true,
+			}),
+		)
+
+		It("Chooses the host according to the scoring policy", func() {
+			smallHost := readyHost("smallHost", nil)
+			smallHost.Status.HardwareDetails = &bmh.HardwareDetails{RAMMebibytes: 1024}
+			bigHost := readyHost("bigHost", nil)
+			bigHost.Status.HardwareDetails = &bmh.HardwareDetails{RAMMebibytes: 4096}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), &smallHost, &bigHost)
+
+			m3mconfig, _ := newConfig("", nil, nil)
+			for policy, expectedHostName := range map[capm3.HostScoringPolicy]string{
+				capm3.HostScoringLeastCapableFit: "smallHost",
+				capm3.HostScoringMostCapableFit:  "bigHost",
+			} {
+				m3mconfig.Spec.HostScoring = &capm3.HostScoring{Policy: policy}
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, nil,
+					m3mconfig, klogr.New(),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				result, _, err := machineMgr.chooseHost(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(BeNil())
+				Expect(result.Name).To(Equal(expectedHostName))
+			}
+		})
+	})
+
+	type testCaseHostInFailureDomain struct {
+		FailureDomain         *string
+		FailureDomainLabelKey string
+		HostLabels            map[string]string
+		ExpectedResult        bool
+	}
+
+	DescribeTable("Test hostInFailureDomain",
+		func(tc testCaseHostInFailureDomain) {
+			machine := &capi.Machine{
+				Spec: capi.MachineSpec{FailureDomain: tc.FailureDomain},
+			}
+			metal3Cluster := &capm3.Metal3Cluster{
+				Spec: capm3.Metal3ClusterSpec{
+					FailureDomainLabelKey: tc.FailureDomainLabelKey,
+				},
+			}
+			machineMgr, err := NewMachineManager(nil, nil, nil, metal3Cluster,
+				machine, nil, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.HostLabels},
+			}
+			Expect(machineMgr.hostInFailureDomain(host)).To(Equal(tc.ExpectedResult))
+		},
+		Entry("No failure domain on the machine", testCaseHostInFailureDomain{
+			FailureDomainLabelKey: "rack",
+			HostLabels:            map[string]string{"rack": "r2"},
+			ExpectedResult:        true,
+		}),
+		Entry("No failure domain label key", testCaseHostInFailureDomain{
+			FailureDomain:  pointer.StringPtr("r1"),
+			HostLabels:     map[string]string{"rack": "r2"},
+			ExpectedResult: true,
+		}),
+		Entry("Host in the failure domain", testCaseHostInFailureDomain{
+			FailureDomain:         pointer.StringPtr("r1"),
+			FailureDomainLabelKey: "rack",
+			HostLabels:            map[string]string{"rack": "r1"},
+			ExpectedResult:        true,
+		}),
+		Entry("Host in another failure domain", testCaseHostInFailureDomain{
+			FailureDomain:         pointer.StringPtr("r1"),
+			FailureDomainLabelKey: "rack",
+			HostLabels:            map[string]string{"rack": "r2"},
+			ExpectedResult:        false,
+		}),
+		Entry("Host without failure domain", testCaseHostInFailureDomain{
+			FailureDomain:         pointer.StringPtr("r1"),
+			FailureDomainLabelKey: "rack",
+			ExpectedResult:        false,
+		}),
+	)
+
 	type testCaseSetPauseAnnotation struct {
 		M3Machine           *capm3.Metal3Machine
 		Host                *bmh.BareMetalHost
@@ -648,7 +840,7 @@ var _ = Describe("Metal3Machine manager", func() {
 	DescribeTable("Test Set BMH Pause Annotation",
 		func(tc testCaseSetPauseAnnotation) {
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), tc.Host, tc.M3Machine)
-			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.M3Machine, klogr.New())
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, nil, tc.M3Machine, klogr.New())
 			Expect(err).NotTo(HaveOccurred())
 
 			err = machineMgr.SetPauseAnnotation(context.TODO())
@@ -751,7 +943,7 @@ var _ = Describe("Metal3Machine manager", func() {
 	DescribeTable("Test Remove BMH Pause Annotation",
 		func(tc testCaseRemovePauseAnnotation) {
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), tc.Host, tc.M3Machine, tc.Cluster)
-			machineMgr, err := NewMachineManager(c, tc.Cluster, nil, nil, tc.M3Machine, klogr.New())
+			machineMgr, err := NewMachineManager(c, nil, tc.Cluster, nil, nil, tc.M3Machine, klogr.New())
 			Expect(err).NotTo(HaveOccurred())
 
 			err = machineMgr.RemovePauseAnnotation(context.TODO())
@@ -849,7 +1041,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			)
 			machine := newMachine("machine1", "", infrastructureRef)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, machine, m3mconfig,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, machine, m3mconfig,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -940,7 +1132,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			)
 			machine := newMachine("machine1", "", infrastructureRef)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, machine, m3mconfig,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, machine, m3mconfig,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -997,6 +1189,45 @@ var _ = Describe("Metal3Machine manager", func() {
 		),
 	)
 
+	DescribeTable("Test claimHost",
+		func(resourceVersion string, expectRequeue bool) {
+			host := newBareMetalHost("host2", nil, bmh.StateNone, nil, false,
+				false,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			m3mconfig, infrastructureRef := newConfig("",
+				map[string]string{}, []capm3.HostSelectorRequirement{},
+			)
+			machine := newMachine("machine1", "", infrastructureRef)
+
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, machine, m3mconfig,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			chosenHost := host.DeepCopy()
+			chosenHost.ResourceVersion = resourceVersion
+			err = machineMgr.claimHost(context.TODO(), chosenHost)
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name:      host.Name,
+				Namespace: host.Namespace,
+			}, &savedHost)).To(Succeed())
+			if expectRequeue {
+				_, ok := errors.Cause(err).(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+				Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(savedHost.Spec.ConsumerRef).NotTo(BeNil())
+			Expect(savedHost.Spec.ConsumerRef.Name).To(Equal(m3mconfig.Name))
+			Expect(chosenHost.ResourceVersion).To(Equal(savedHost.ResourceVersion))
+		},
+		Entry("Host unchanged since it was listed", "", false),
+		Entry("Host modified since it was listed", "5", true),
+	)
+
 	Describe("Test Exists function", func() {
 		host := bmh.BareMetalHost{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1014,7 +1245,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 		DescribeTable("Test Exists function",
 			func(tc testCaseExists) {
-				machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 					tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -1066,7 +1297,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 		DescribeTable("Test GetHost",
 			func(tc testCaseGetHost) {
-				machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 					tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -1116,7 +1347,7 @@ var _ = Describe("Metal3Machine manager", func() {
 	DescribeTable("Test Get and Set Provider ID",
 		func(tc testCaseGetSetProviderID) {
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), tc.Host)
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1211,7 +1442,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 		DescribeTable("Test small functions",
 			func(tc testCaseSmallFunctions) {
-				machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 					tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -1270,7 +1501,7 @@ var _ = Describe("Metal3Machine manager", func() {
 		func(tc testCaseEnsureAnnotation) {
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), tc.M3Machine)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, &tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, &tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1363,7 +1594,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -1498,8 +1729,10 @@ var _ = Describe("Metal3Machine manager", func() {
 				m3mObjectMetaWithValidAnnotations(),
 			),
 			ExpectedConsumerRef: consumerRef(),
-			ExpectedResult:      &RequeueAfterError{RequeueAfter: time.Second * 30},
-			Secret:              newSecret(),
+			ExpectedResult: &RequeueAfterError{RequeueAfter: time.Second * 30,
+				Reason: "waiting for the BareMetalHost to be deprovisioned",
+			},
+			Secret: newSecret(),
 		}),
 		Entry("Externally provisioned host should be powered down", testCaseDelete{
 			Host: newBareMetalHost("myhost", bmhSpecNoImg(),
@@ -1510,8 +1743,10 @@ var _ = Describe("Metal3Machine manager", func() {
 				m3mObjectMetaWithValidAnnotations(),
 			),
 			ExpectedConsumerRef: consumerRef(),
-			ExpectedResult:      &RequeueAfterError{RequeueAfter: time.Second * 30},
-			Secret:              newSecret(),
+			ExpectedResult: &RequeueAfterError{RequeueAfter: time.Second * 30,
+				Reason: "waiting for the BareMetalHost to be deprovisioned",
+			},
+			Secret: newSecret(),
 		}),
 		Entry("Consumer ref should be removed from externally provisioned host",
 			testCaseDelete{
@@ -1686,7 +1921,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			func(tc testCaseUpdateMachineStatus) {
 				c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), &tc.M3Machine)
 
-				machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 					&tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -1904,7 +2139,7 @@ var _ = Describe("Metal3Machine manager", func() {
 				var nodeAddresses []capi.MachineAddress
 
 				c := fakeclient.NewFakeClientWithScheme(setupSchemeMm())
-				machineMgr, err := NewMachineManager(c, nil, nil, &tc.Machine,
+				machineMgr, err := NewMachineManager(c, nil, nil, nil, &tc.Machine,
 					&tc.M3Machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
@@ -1977,13 +2212,17 @@ var _ = Describe("Metal3Machine manager", func() {
 				},
 			}
 
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &m3m, klogr.New())
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &m3m, klogr.New())
 			Expect(err).NotTo(HaveOccurred())
 
 			providerID, bmhID := machineMgr.GetProviderIDAndBMHID()
 
 			if tc.providerID != nil {
 				Expect(providerID).To(Equal(*tc.providerID))
+				if tc.expectedBMHID == "" {
+					Expect(bmhID).To(BeNil())
+					return
+				}
 				Expect(bmhID).NotTo(BeNil())
 				Expect(*bmhID).To(Equal(tc.expectedBMHID))
 			} else {
@@ -1996,6 +2235,59 @@ var _ = Describe("Metal3Machine manager", func() {
 			providerID:    pointer.StringPtr("metal3://abcd"),
 			expectedBMHID: "abcd",
 		}),
+		Entry("Provider ID set in the name format", testCaseGetProviderIDAndBMHID{
+			providerID: pointer.StringPtr("metal3://myns/host1/m3m1"),
+		}),
+	)
+
+	type testCaseBuildProviderID struct {
+		format             capm3.ProviderIDFormat
+		hostAnnotation     string
+		expectedProviderID string
+	}
+
+	DescribeTable("Test BuildProviderID",
+		func(tc testCaseBuildProviderID) {
+			m3m := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "m3m1",
+					Annotations: map[string]string{},
+				},
+			}
+			if tc.hostAnnotation != "" {
+				m3m.Annotations[HostAnnotation] = tc.hostAnnotation
+			}
+			m3c := &capm3.Metal3Cluster{
+				Spec: capm3.Metal3ClusterSpec{ProviderIDFormat: tc.format},
+			}
+
+			machineMgr, err := NewMachineManager(nil, nil, nil, m3c, nil, m3m,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.BuildProviderID("abcd")).To(
+				Equal(tc.expectedProviderID),
+			)
+		},
+		Entry("Default format", testCaseBuildProviderID{
+			hostAnnotation:     "myns/host1",
+			expectedProviderID: "metal3://abcd",
+		}),
+		Entry("UID format", testCaseBuildProviderID{
+			format:             capm3.ProviderIDFormatUID,
+			hostAnnotation:     "myns/host1",
+			expectedProviderID: "metal3://abcd",
+		}),
+		Entry("Name format", testCaseBuildProviderID{
+			format:             capm3.ProviderIDFormatName,
+			hostAnnotation:     "myns/host1",
+			expectedProviderID: "metal3://myns/host1/m3m1",
+		}),
+		Entry("Name format, no host annotation", testCaseBuildProviderID{
+			format:             capm3.ProviderIDFormatName,
+			expectedProviderID: "metal3://abcd",
+		}),
 	)
 
 	Describe("Test SetNodeProviderID", func() {
@@ -2010,10 +2302,14 @@ var _ = Describe("Metal3Machine manager", func() {
 		}
 
 		type testCaseSetNodePoviderID struct {
-			Node               v1.Node
-			HostID             string
-			ExpectedError      bool
-			ExpectedProviderID string
+			Node                 v1.Node
+			HostID               string
+			CloudProvider        bool
+			SetProviderIDOnNodes bool
+			ExpectedError        bool
+			ExpectedProviderID   string
+			ExpectedReason       string
+			ExpectNoCondition    bool
 		}
 
 		DescribeTable("Test SetNodeProviderID",
@@ -2026,18 +2322,36 @@ var _ = Describe("Metal3Machine manager", func() {
 					return corev1Client, nil
 				}
 
-				machineMgr, err := NewMachineManager(c, newCluster(clusterName),
+				m3machine := &capm3.Metal3Machine{}
+				machineMgr, err := NewMachineManager(c, nil, newCluster(clusterName),
 					newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
-						&capm3.Metal3ClusterSpec{NoCloudProvider: true}, nil,
+						&capm3.Metal3ClusterSpec{
+							NoCloudProvider:      !tc.CloudProvider,
+							SetProviderIDOnNodes: tc.SetProviderIDOnNodes,
+						}, nil,
 					),
-					&capi.Machine{}, &capm3.Metal3Machine{}, klogr.New(),
+					&capi.Machine{}, m3machine, klogr.New(),
 				)
 				Expect(err).NotTo(HaveOccurred())
 
 				err = machineMgr.SetNodeProviderID(context.TODO(), tc.HostID,
-					tc.ExpectedProviderID, mockCapiClientGetter,
+					"metal3://abcd", mockCapiClientGetter,
 				)
 
+				if tc.ExpectNoCondition {
+					Expect(conditions.Get(m3machine,
+						capm3.KubernetesNodeReadyCondition,
+					)).To(BeNil())
+				} else if tc.ExpectedReason == "" {
+					Expect(conditions.IsTrue(m3machine,
+						capm3.KubernetesNodeReadyCondition,
+					)).To(BeTrue())
+				} else {
+					Expect(conditions.GetReason(m3machine,
+						capm3.KubernetesNodeReadyCondition,
+					)).To(Equal(tc.ExpectedReason))
+				}
+
 				if tc.ExpectedError {
 					Expect(err).To(HaveOccurred())
 					return
@@ -2058,6 +2372,7 @@ var _ = Describe("Metal3Machine manager", func() {
 				HostID:             "abcd",
 				ExpectedError:      true,
 				ExpectedProviderID: "metal3://abcd",
+				ExpectedReason:     capm3.WaitingForNodeReason,
 			}),
 			Entry("Set target ProviderID, matching node", testCaseSetNodePoviderID{
 				Node: v1.Node{
@@ -2086,9 +2401,742 @@ var _ = Describe("Metal3Machine manager", func() {
 				ExpectedError:      false,
 				ExpectedProviderID: "metal3://abcd",
 			}),
+			Entry("Cloud provider, providerID not set", testCaseSetNodePoviderID{
+				Node: v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"metal3.io/uuid": "abcd",
+						},
+					},
+				},
+				HostID:            "abcd",
+				CloudProvider:     true,
+				ExpectNoCondition: true,
+			}),
+			Entry("Cloud provider, providerID set on the node without one", testCaseSetNodePoviderID{
+				Node: v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"metal3.io/uuid": "abcd",
+						},
+					},
+				},
+				HostID:               "abcd",
+				CloudProvider:        true,
+				SetProviderIDOnNodes: true,
+				ExpectedProviderID:   "metal3://abcd",
+			}),
+			Entry("Cloud provider, providerID of the cloud provider kept", testCaseSetNodePoviderID{
+				Node: v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"metal3.io/uuid": "abcd",
+						},
+					},
+					Spec: v1.NodeSpec{
+						ProviderID: "external://node1",
+					},
+				},
+				HostID:               "abcd",
+				CloudProvider:        true,
+				SetProviderIDOnNodes: true,
+				ExpectedProviderID:   "external://node1",
+			}),
 		)
 	})
 
+	type testCaseCheckNode struct {
+		Node                   *v1.Node
+		NoCloudProvider        bool
+		SetProviderIDOnNodes   bool
+		NodeDeletion           *capm3.NodeDeletionPolicy
+		MissingSince           time.Duration
+		ExpectNodeReady        bool
+		Rebooted               bool
+		ExpectRequeue          bool
+		ExpectedReason         string
+		ExpectedNodeProviderID string
+		ExpectHostReboot       bool
+		ExpectMachineDeleted   bool
+		ExpectRebootAnnotation bool
+	}
+
+	DescribeTable("Test CheckNode",
+		func(tc testCaseCheckNode) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myhost",
+					Namespace: namespaceName,
+					UID:       "abcd",
+				},
+			}
+			machine := &capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mymachine",
+					Namespace: namespaceName,
+				},
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host, machine)
+			nodes := []runtime.Object{}
+			if tc.Node != nil {
+				nodes = append(nodes, tc.Node)
+			}
+			corev1Client := clientfake.NewSimpleClientset(nodes...).CoreV1()
+			mockCapiClientGetter := func(ctx context.Context, c client.Client, cluster *capi.Cluster) (
+				clientcorev1.CoreV1Interface, error,
+			) {
+				return corev1Client, nil
+			}
+
+			m3machine := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						HostAnnotation: namespaceName + "/myhost",
+					},
+				},
+				Spec: capm3.Metal3MachineSpec{
+					ProviderID: pointer.StringPtr("metal3://abcd"),
+				},
+			}
+			if tc.Rebooted {
+				m3machine.Annotations[nodeDeletionRebootAnnotation] = "2020-10-01T10:00:00Z"
+			}
+			if tc.MissingSince != 0 {
+				conditions.Set(m3machine, &capi.Condition{
+					Type:               capm3.KubernetesNodeReadyCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           capi.ConditionSeverityWarning,
+					Reason:             capm3.NodeNotFoundReason,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-tc.MissingSince)),
+				})
+			}
+			machineMgr, err := NewMachineManager(c, nil, newCluster(clusterName),
+				newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+					&capm3.Metal3ClusterSpec{
+						NoCloudProvider:      tc.NoCloudProvider,
+						SetProviderIDOnNodes: tc.SetProviderIDOnNodes,
+						NodeDeletion:         tc.NodeDeletion,
+					}, nil,
+				),
+				machine, m3machine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.CheckNode(context.TODO(), mockCapiClientGetter)
+			if tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				_, ok := errors.Cause(err).(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			if tc.ExpectNodeReady {
+				Expect(conditions.IsTrue(m3machine,
+					capm3.KubernetesNodeReadyCondition,
+				)).To(BeTrue())
+			} else if tc.ExpectedReason == "" {
+				Expect(conditions.Get(m3machine,
+					capm3.KubernetesNodeReadyCondition,
+				)).To(BeNil())
+			} else {
+				Expect(conditions.GetReason(m3machine,
+					capm3.KubernetesNodeReadyCondition,
+				)).To(Equal(tc.ExpectedReason))
+			}
+			if tc.ExpectedNodeProviderID != "" {
+				node, err := corev1Client.Nodes().Get(context.TODO(), tc.Node.Name,
+					metav1.GetOptions{},
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.Spec.ProviderID).To(Equal(tc.ExpectedNodeProviderID))
+			}
+
+			savedHost := &bmh.BareMetalHost{}
+			err = c.Get(context.TODO(), client.ObjectKey{Name: "myhost",
+				Namespace: namespaceName,
+			}, savedHost)
+			Expect(err).NotTo(HaveOccurred())
+			_, rebooted := savedHost.Annotations[capm3.RebootAnnotation]
+			Expect(rebooted).To(Equal(tc.ExpectHostReboot))
+			_, ok := m3machine.Annotations[nodeDeletionRebootAnnotation]
+			Expect(ok).To(Equal(tc.ExpectRebootAnnotation))
+
+			err = c.Get(context.TODO(), client.ObjectKey{Name: "mymachine",
+				Namespace: namespaceName,
+			}, &capi.Machine{})
+			Expect(apierrors.IsNotFound(err)).To(Equal(tc.ExpectMachineDeleted))
+		},
+		Entry("With a cloud provider", testCaseCheckNode{}),
+		Entry("With a cloud provider, providerID set on the node", testCaseCheckNode{
+			Node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+			},
+			SetProviderIDOnNodes:   true,
+			ExpectNodeReady:        true,
+			ExpectedNodeProviderID: "metal3://abcd",
+		}),
+		Entry("With a cloud provider, node deleted", testCaseCheckNode{
+			SetProviderIDOnNodes: true,
+			NodeDeletion: &capm3.NodeDeletionPolicy{
+				Action: capm3.NodeDeletionActionReboot,
+			},
+		}),
+		Entry("Node found", testCaseCheckNode{
+			Node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+				Spec: v1.NodeSpec{ProviderID: "metal3://abcd"},
+			},
+			NoCloudProvider:        true,
+			Rebooted:               true,
+			ExpectNodeReady:        true,
+			ExpectedNodeProviderID: "metal3://abcd",
+		}),
+		Entry("Node registered again", testCaseCheckNode{
+			Node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+			},
+			NoCloudProvider:        true,
+			MissingSince:           time.Hour,
+			ExpectNodeReady:        true,
+			ExpectedNodeProviderID: "metal3://abcd",
+		}),
+		Entry("Node deleted, no policy", testCaseCheckNode{
+			NoCloudProvider: true,
+			ExpectedReason:  capm3.NodeNotFoundReason,
+		}),
+		Entry("Node deleted, within the grace period", testCaseCheckNode{
+			NoCloudProvider: true,
+			NodeDeletion: &capm3.NodeDeletionPolicy{
+				Action: capm3.NodeDeletionActionReboot,
+			},
+			MissingSince:   time.Minute,
+			ExpectRequeue:  true,
+			ExpectedReason: capm3.NodeNotFoundReason,
+		}),
+		Entry("Node deleted, reboot", testCaseCheckNode{
+			NoCloudProvider: true,
+			NodeDeletion: &capm3.NodeDeletionPolicy{
+				Action:      capm3.NodeDeletionActionReboot,
+				GracePeriod: &metav1.Duration{Duration: time.Minute},
+			},
+			MissingSince:           time.Hour,
+			ExpectedReason:         capm3.NodeNotFoundReason,
+			ExpectHostReboot:       true,
+			ExpectRebootAnnotation: true,
+		}),
+		Entry("Node deleted, already rebooted", testCaseCheckNode{
+			NoCloudProvider: true,
+			NodeDeletion: &capm3.NodeDeletionPolicy{
+				Action: capm3.NodeDeletionActionReboot,
+			},
+			MissingSince:           time.Hour,
+			Rebooted:               true,
+			ExpectedReason:         capm3.NodeNotFoundReason,
+			ExpectRebootAnnotation: true,
+		}),
+		Entry("Node deleted, replace", testCaseCheckNode{
+			NoCloudProvider: true,
+			NodeDeletion: &capm3.NodeDeletionPolicy{
+				Action: capm3.NodeDeletionActionReplace,
+			},
+			MissingSince:         time.Hour,
+			ExpectedReason:       capm3.NodeNotFoundReason,
+			ExpectMachineDeleted: true,
+		}),
+	)
+
+	type testCaseSyncNode struct {
+		Node                v1.Node
+		HostUID             string
+		ProviderID          string
+		HostLabels          map[string]string
+		HostAnnotations     map[string]string
+		MachineLabels       map[string]string
+		Prefixes            []string
+		AnnotationPrefixes  []string
+		NodeLabels          map[string]string
+		NodeTaints          []v1.Taint
+		ExpectedLabels      map[string]string
+		ExpectedTaints      []v1.Taint
+		ExpectedAnnotations map[string]string
+		Synced              bool
+		NoNodeRef           bool
+		ExpectSynced        bool
+		ExpectNoAccess      bool
+	}
+
+	DescribeTable("Test SyncNode",
+		func(tc testCaseSyncNode) {
+			hostUID := tc.HostUID
+			if hostUID == "" {
+				hostUID = "abcd"
+			}
+			providerID := tc.ProviderID
+			if providerID == "" {
+				providerID = "metal3://abcd"
+			}
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "myhost",
+					Namespace:   namespaceName,
+					UID:         types.UID(hostUID),
+					Labels:      tc.HostLabels,
+					Annotations: tc.HostAnnotations,
+				},
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			corev1Client := clientfake.NewSimpleClientset(&tc.Node).CoreV1()
+			accessed := false
+			mockCapiClientGetter := func(ctx context.Context, c client.Client, cluster *capi.Cluster) (
+				clientcorev1.CoreV1Interface, error,
+			) {
+				accessed = true
+				return corev1Client, nil
+			}
+
+			m3machineAnnotations := map[string]string{
+				HostAnnotation: namespaceName + "/myhost",
+			}
+			if tc.Synced {
+				m3machineAnnotations[nodeSyncedAnnotation] = ""
+			}
+			m3machine := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      tc.MachineLabels,
+					Annotations: m3machineAnnotations,
+				},
+				Spec: capm3.Metal3MachineSpec{
+					ProviderID: &providerID,
+					NodeLabels: tc.NodeLabels,
+					NodeTaints: tc.NodeTaints,
+				},
+			}
+			machine := &capi.Machine{}
+			if !tc.NoNodeRef {
+				machine.Status.NodeRef = &v1.ObjectReference{
+					Kind: "Node", Name: tc.Node.Name,
+				}
+			}
+			machineMgr, err := NewMachineManager(c, nil, newCluster(clusterName),
+				newMetal3Cluster(metal3ClusterName, bmcOwnerRef,
+					&capm3.Metal3ClusterSpec{
+						NodeLabelPrefixes:      tc.Prefixes,
+						NodeAnnotationPrefixes: tc.AnnotationPrefixes,
+					}, nil,
+				),
+				machine, m3machine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.SyncNode(context.TODO(), mockCapiClientGetter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessed).To(Equal(!tc.ExpectNoAccess))
+			_, synced := m3machine.Annotations[nodeSyncedAnnotation]
+			Expect(synced).To(Equal(tc.ExpectSynced))
+
+			node, err := corev1Client.Nodes().Get(context.TODO(), tc.Node.Name,
+				metav1.GetOptions{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.Labels).To(Equal(tc.ExpectedLabels))
+			Expect(node.Spec.Taints).To(Equal(tc.ExpectedTaints))
+			Expect(node.Annotations).To(Equal(tc.ExpectedAnnotations))
+		},
+		Entry("No prefixes", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+			},
+			HostLabels:     map[string]string{"topology.metal3.io/rack": "r1"},
+			ExpectedLabels: map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectNoAccess: true,
+		}),
+		Entry("Labels copied, machine labels take precedence", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+			},
+			HostLabels: map[string]string{
+				"topology.metal3.io/rack":    "r1",
+				"topology.metal3.io/chassis": "c1",
+				"other":                      "value",
+			},
+			MachineLabels: map[string]string{"topology.metal3.io/chassis": "c2"},
+			Prefixes:      []string{"topology.metal3.io/"},
+			ExpectedLabels: map[string]string{
+				"metal3.io/uuid":             "abcd",
+				"topology.metal3.io/rack":    "r1",
+				"topology.metal3.io/chassis": "c2",
+			},
+			ExpectedAnnotations: map[string]string{
+				nodeSyncedLabelsAnnotation: "topology.metal3.io/chassis,topology.metal3.io/rack",
+			},
+			ExpectSynced: true,
+		}),
+		Entry("Removed labels deleted", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node1",
+					Labels: map[string]string{
+						"metal3.io/uuid":             "abcd",
+						"topology.metal3.io/rack":    "r1",
+						"topology.metal3.io/chassis": "c1",
+						"topology.metal3.io/zone":    "z1",
+					},
+					Annotations: map[string]string{
+						nodeSyncedLabelsAnnotation: "topology.metal3.io/chassis,topology.metal3.io/rack",
+					},
+				},
+			},
+			HostLabels: map[string]string{"topology.metal3.io/rack": "r2"},
+			Prefixes:   []string{"topology.metal3.io/"},
+			ExpectedLabels: map[string]string{
+				"metal3.io/uuid":          "abcd",
+				"topology.metal3.io/rack": "r2",
+				"topology.metal3.io/zone": "z1",
+			},
+			ExpectedAnnotations: map[string]string{
+				nodeSyncedLabelsAnnotation: "topology.metal3.io/rack",
+			},
+			Synced:       true,
+			ExpectSynced: true,
+		}),
+		Entry("Node labels and taints set", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+				Spec: v1.NodeSpec{
+					Taints: []v1.Taint{
+						{Key: "other", Effect: v1.TaintEffectNoExecute},
+					},
+				},
+			},
+			HostLabels: map[string]string{"topology.metal3.io/rack": "r1"},
+			Prefixes:   []string{"topology.metal3.io/"},
+			NodeLabels: map[string]string{
+				"topology.metal3.io/rack": "r2",
+				"node-class":              "storage",
+			},
+			NodeTaints: []v1.Taint{
+				{Key: "node-class", Value: "storage", Effect: v1.TaintEffectNoSchedule},
+			},
+			ExpectedLabels: map[string]string{
+				"metal3.io/uuid":          "abcd",
+				"topology.metal3.io/rack": "r2",
+				"node-class":              "storage",
+			},
+			ExpectedTaints: []v1.Taint{
+				{Key: "other", Effect: v1.TaintEffectNoExecute},
+				{Key: "node-class", Value: "storage", Effect: v1.TaintEffectNoSchedule},
+			},
+			ExpectedAnnotations: map[string]string{
+				nodeSyncedLabelsAnnotation: "node-class,topology.metal3.io/rack",
+				nodeSyncedTaintsAnnotation: "node-class:NoSchedule",
+			},
+			ExpectSynced: true,
+		}),
+		Entry("Drifted taints reconciled", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+					Annotations: map[string]string{
+						nodeSyncedTaintsAnnotation: "gpu:NoSchedule,node-class:NoSchedule",
+					},
+				},
+				Spec: v1.NodeSpec{
+					Taints: []v1.Taint{
+						{Key: "gpu", Effect: v1.TaintEffectNoSchedule},
+						{Key: "node-class", Value: "edited", Effect: v1.TaintEffectNoSchedule},
+					},
+				},
+			},
+			NodeTaints: []v1.Taint{
+				{Key: "node-class", Value: "storage", Effect: v1.TaintEffectNoSchedule},
+			},
+			ExpectedLabels: map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectedTaints: []v1.Taint{
+				{Key: "node-class", Value: "storage", Effect: v1.TaintEffectNoSchedule},
+			},
+			ExpectedAnnotations: map[string]string{
+				nodeSyncedTaintsAnnotation: "node-class:NoSchedule",
+			},
+			Synced:       true,
+			ExpectSynced: true,
+		}),
+		Entry("Last taint removed", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+					Annotations: map[string]string{
+						nodeSyncedTaintsAnnotation: "node-class:NoSchedule",
+					},
+				},
+				Spec: v1.NodeSpec{
+					Taints: []v1.Taint{
+						{Key: "other", Effect: v1.TaintEffectNoExecute},
+						{Key: "node-class", Value: "storage", Effect: v1.TaintEffectNoSchedule},
+					},
+				},
+			},
+			ExpectedLabels: map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectedTaints: []v1.Taint{
+				{Key: "other", Effect: v1.TaintEffectNoExecute},
+			},
+			ExpectedAnnotations: map[string]string{},
+			Synced:              true,
+		}),
+		Entry("Last label removed", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node1",
+					Labels: map[string]string{
+						"metal3.io/uuid": "abcd",
+						"node-class":     "storage",
+					},
+					Annotations: map[string]string{
+						nodeSyncedLabelsAnnotation: "node-class",
+					},
+				},
+			},
+			ExpectedLabels:      map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectedAnnotations: map[string]string{},
+			Synced:              true,
+		}),
+		Entry("Prefixes cleared", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node1",
+					Labels: map[string]string{
+						"metal3.io/uuid":          "abcd",
+						"topology.metal3.io/rack": "r1",
+					},
+					Annotations: map[string]string{
+						"hardware.metal3.io/class":      "storage",
+						nodeSyncedLabelsAnnotation:      "topology.metal3.io/rack",
+						nodeSyncedAnnotationsAnnotation: "hardware.metal3.io/class",
+					},
+				},
+			},
+			HostLabels:          map[string]string{"topology.metal3.io/rack": "r1"},
+			HostAnnotations:     map[string]string{"hardware.metal3.io/class": "storage"},
+			ExpectedLabels:      map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectedAnnotations: map[string]string{},
+			Synced:              true,
+		}),
+		Entry("Annotations copied", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+					Annotations: map[string]string{
+						"hardware.metal3.io/vendor":     "old",
+						nodeSyncedAnnotationsAnnotation: "hardware.metal3.io/vendor",
+					},
+				},
+			},
+			HostAnnotations: map[string]string{
+				"hardware.metal3.io/class": "storage",
+				"other":                    "value",
+			},
+			AnnotationPrefixes: []string{"hardware.metal3.io/"},
+			ExpectedLabels:     map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectedAnnotations: map[string]string{
+				"hardware.metal3.io/class":      "storage",
+				nodeSyncedAnnotationsAnnotation: "hardware.metal3.io/class",
+			},
+			ExpectSynced: true,
+		}),
+		Entry("Node found by providerID after a move", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "old-uid"},
+				},
+				Spec: v1.NodeSpec{ProviderID: "metal3://old-uid"},
+			},
+			ProviderID: "metal3://old-uid",
+			NodeLabels: map[string]string{"node-class": "storage"},
+			ExpectedLabels: map[string]string{
+				"metal3.io/uuid": "old-uid",
+				"node-class":     "storage",
+			},
+			ExpectedAnnotations: map[string]string{
+				nodeSyncedLabelsAnnotation: "node-class",
+			},
+			ExpectSynced: true,
+		}),
+		Entry("Node of another host left untouched", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+				Spec: v1.NodeSpec{ProviderID: "metal3://other"},
+			},
+			NodeLabels:     map[string]string{"node-class": "storage"},
+			ExpectedLabels: map[string]string{"metal3.io/uuid": "abcd"},
+		}),
+		Entry("No node reference", testCaseSyncNode{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abcd"},
+				},
+			},
+			NodeLabels:     map[string]string{"node-class": "storage"},
+			NoNodeRef:      true,
+			ExpectedLabels: map[string]string{"metal3.io/uuid": "abcd"},
+			ExpectNoAccess: true,
+		}),
+	)
+
+	type testCaseHostProvisionedCondition struct {
+		HostState        bmh.ProvisioningState
+		ImageUpgrade     *capm3.ImageUpgradeStatus
+		ExpectedStatus   corev1.ConditionStatus
+		ExpectedSeverity capi.ConditionSeverity
+	}
+
+	DescribeTable("Test setHostProvisionedCondition",
+		func(tc testCaseHostProvisionedCondition) {
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm())
+			m3machine := &capm3.Metal3Machine{
+				Status: capm3.Metal3MachineStatus{ImageUpgrade: tc.ImageUpgrade},
+			}
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, &capi.Machine{},
+				m3machine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "myhost"},
+			}
+			host.Status.Provisioning.State = tc.HostState
+			machineMgr.setHostProvisionedCondition(host)
+
+			condition := conditions.Get(m3machine, capm3.HostProvisionedCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(tc.ExpectedStatus))
+			Expect(condition.Severity).To(Equal(tc.ExpectedSeverity))
+			if tc.ExpectedStatus == corev1.ConditionFalse {
+				Expect(condition.Reason).To(
+					Equal(capm3.WaitingForHostProvisioningReason),
+				)
+			}
+		},
+		Entry("Host provisioning", testCaseHostProvisionedCondition{
+			HostState:        bmh.StateProvisioning,
+			ExpectedStatus:   corev1.ConditionFalse,
+			ExpectedSeverity: capi.ConditionSeverityInfo,
+		}),
+		Entry("Host provisioned", testCaseHostProvisionedCondition{
+			HostState:      bmh.StateProvisioned,
+			ExpectedStatus: corev1.ConditionTrue,
+		}),
+		Entry("Host provisioned, image upgrade started",
+			testCaseHostProvisionedCondition{
+				HostState: bmh.StateProvisioned,
+				ImageUpgrade: &capm3.ImageUpgradeStatus{
+					State: capm3.ImageUpgradeDeprovisioning,
+				},
+				ExpectedStatus:   corev1.ConditionFalse,
+				ExpectedSeverity: capi.ConditionSeverityInfo,
+			},
+		),
+		Entry("Host provisioned, image upgrade completed",
+			testCaseHostProvisionedCondition{
+				HostState: bmh.StateProvisioned,
+				ImageUpgrade: &capm3.ImageUpgradeStatus{
+					State: capm3.ImageUpgradeCompleted,
+				},
+				ExpectedStatus: corev1.ConditionTrue,
+			},
+		),
+	)
+
+	type testCaseHostPhase struct {
+		HostState     bmh.ProvisioningState
+		HostError     string
+		ImageUpgrade  *capm3.ImageUpgradeStatus
+		ExpectedPhase capm3.Metal3MachinePhase
+	}
+
+	DescribeTable("Test hostPhase",
+		func(tc testCaseHostPhase) {
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm())
+			m3machine := &capm3.Metal3Machine{
+				Status: capm3.Metal3MachineStatus{ImageUpgrade: tc.ImageUpgrade},
+			}
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, &capi.Machine{},
+				m3machine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			host := &bmh.BareMetalHost{}
+			host.Status.Provisioning.State = tc.HostState
+			host.Status.ErrorMessage = tc.HostError
+
+			Expect(machineMgr.hostPhase(host)).To(Equal(tc.ExpectedPhase))
+		},
+		Entry("Host ready", testCaseHostPhase{
+			HostState:     bmh.StateReady,
+			ExpectedPhase: capm3.Metal3MachinePhaseProvisioning,
+		}),
+		Entry("Host provisioning", testCaseHostPhase{
+			HostState:     bmh.StateProvisioning,
+			ExpectedPhase: capm3.Metal3MachinePhaseProvisioning,
+		}),
+		Entry("Host provisioned", testCaseHostPhase{
+			HostState:     bmh.StateProvisioned,
+			ExpectedPhase: capm3.Metal3MachinePhaseProvisioned,
+		}),
+		Entry("Host provisioned, deprovisioning for an upgrade", testCaseHostPhase{
+			HostState: bmh.StateProvisioned,
+			ImageUpgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeDeprovisioning,
+			},
+			ExpectedPhase: capm3.Metal3MachinePhaseDeprovisioning,
+		}),
+		Entry("Host provisioned, upgrade completed", testCaseHostPhase{
+			HostState: bmh.StateProvisioned,
+			ImageUpgrade: &capm3.ImageUpgradeStatus{
+				State: capm3.ImageUpgradeCompleted,
+			},
+			ExpectedPhase: capm3.Metal3MachinePhaseProvisioned,
+		}),
+		Entry("Host deprovisioning", testCaseHostPhase{
+			HostState:     bmh.StateDeprovisioning,
+			ExpectedPhase: capm3.Metal3MachinePhaseDeprovisioning,
+		}),
+		Entry("Host provisioning error", testCaseHostPhase{
+			HostState:     bmh.StateProvisioningError,
+			ExpectedPhase: capm3.Metal3MachinePhaseFailed,
+		}),
+		Entry("Host with an error message", testCaseHostPhase{
+			HostState:     bmh.StateProvisioning,
+			HostError:     "Image provisioning failed",
+			ExpectedPhase: capm3.Metal3MachinePhaseFailed,
+		}),
+	)
+
 	type testCaseGetUserData struct {
 		Machine     *capi.Machine
 		M3Machine   *capm3.Metal3Machine
@@ -2108,7 +3156,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2327,7 +3375,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2507,7 +3555,7 @@ var _ = Describe("Metal3Machine manager", func() {
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
 
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine,
 				tc.M3Machine, klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2550,7 +3598,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test FindOwnerRef",
 		func(tc testCaseFindOwnerRef) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &tc.M3Machine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2666,7 +3714,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test DeleteOwnerRef",
 		func(tc testCaseOwnerRef) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &tc.M3Machine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2740,7 +3788,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 	DescribeTable("Test SetOwnerRef",
 		func(tc testCaseOwnerRef) {
-			machineMgr, err := NewMachineManager(nil, nil, nil, nil, &tc.M3Machine,
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, nil, &tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2821,7 +3869,7 @@ var _ = Describe("Metal3Machine manager", func() {
 				objects = append(objects, tc.DataClaim)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine, tc.M3Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine, tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -2934,7 +3982,7 @@ var _ = Describe("Metal3Machine manager", func() {
 				objects = append(objects, tc.Data)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine, tc.M3Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine, tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -3119,7 +4167,7 @@ var _ = Describe("Metal3Machine manager", func() {
 				objects = append(objects, tc.DataClaim)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
-			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine, tc.M3Machine,
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, tc.Machine, tc.M3Machine,
 				klogr.New(),
 			)
 			Expect(err).NotTo(HaveOccurred())
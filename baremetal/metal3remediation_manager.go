@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemediationManagerInterface is an interface for a RemediationManager
+type RemediationManagerInterface interface {
+	Reconcile(ctx context.Context) error
+}
+
+// RemediationManager is responsible for performing the remediation of an
+// unhealthy Machine
+type RemediationManager struct {
+	client        client.Client
+	recorder      record.EventRecorder
+	Remediation   *capm3.Metal3Remediation
+	Metal3Machine *capm3.Metal3Machine
+	Machine       *capi.Machine
+	Log           logr.Logger
+}
+
+// NewRemediationManager returns a new helper for managing a Metal3Remediation
+// object
+func NewRemediationManager(client client.Client, recorder record.EventRecorder,
+	remediation *capm3.Metal3Remediation, metal3Machine *capm3.Metal3Machine,
+	machine *capi.Machine, remediationLog logr.Logger) (*RemediationManager, error) {
+
+	return &RemediationManager{
+		client:        client,
+		recorder:      recorder,
+		Remediation:   remediation,
+		Metal3Machine: metal3Machine,
+		Machine:       machine,
+		Log:           remediationLog,
+	}, nil
+}
+
+// Reconcile reboots the BareMetalHost of the Machine, then waits for the
+// timeout of the strategy. The MachineHealthCheck deletes the
+// Metal3Remediation once the node is healthy again, so a remediation that
+// outlives the timeout reboots the host again, until the retry limit is
+// reached. The host is then marked unhealthy, so that it is not claimed
+// again, and the Machine is deleted to be replaced.
+func (m *RemediationManager) Reconcile(ctx context.Context) error {
+	strategy := m.Remediation.Spec.Strategy
+	if strategy == nil || strategy.Type != capm3.RebootRemediationStrategy {
+		m.Log.Info("Unsupported remediation strategy, nothing to do")
+		return nil
+	}
+
+	host, helper, err := m.getHost(ctx)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		m.Log.Info("Metal3Machine has no host to remediate")
+		return nil
+	}
+
+	switch m.Remediation.Status.Phase {
+	case capm3.PhaseWaiting:
+		lastRemediated := m.Remediation.Status.LastRemediated
+		if lastRemediated != nil {
+			remaining := m.timeout() - time.Since(lastRemediated.Time)
+			if remaining > 0 {
+				return &RequeueAfterError{RequeueAfter: remaining}
+			}
+		}
+		m.Log.Info("Node still unhealthy after the reboot", "host", host.Name)
+		m.Remediation.Status.Phase = capm3.PhaseRunning
+		fallthrough
+	case "", capm3.PhaseRunning:
+		if m.Remediation.Status.RetryCount < m.retryLimit() {
+			return m.rebootHost(ctx, host, helper)
+		}
+		m.Remediation.Status.Phase = capm3.PhaseDeleting
+		fallthrough
+	case capm3.PhaseDeleting:
+		return m.deleteMachine(ctx, host, helper)
+	}
+	return nil
+}
+
+// retryLimit returns the maximum number of reboots.
+func (m *RemediationManager) retryLimit() int {
+	if m.Remediation.Spec.Strategy.RetryLimit == nil {
+		return capm3.DefaultRemediationRetryLimit
+	}
+	return int(*m.Remediation.Spec.Strategy.RetryLimit)
+}
+
+// timeout returns the time to wait for the node after a reboot.
+func (m *RemediationManager) timeout() time.Duration {
+	if m.Remediation.Spec.Strategy.Timeout == nil {
+		return capm3.DefaultRemediationTimeout
+	}
+	return m.Remediation.Spec.Strategy.Timeout.Duration
+}
+
+// rebootHost sets the reboot annotation on the host and records the retry.
+func (m *RemediationManager) rebootHost(ctx context.Context,
+	host *bmh.BareMetalHost, helper *patch.Helper,
+) error {
+	m.Log.Info("Rebooting the host", "host", host.Name,
+		"retry", m.Remediation.Status.RetryCount+1,
+	)
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	host.Annotations[capm3.RebootAnnotation] = ""
+	if err := helper.Patch(ctx, host); err != nil {
+		return errors.Wrap(err, "failed to set the reboot annotation on the host")
+	}
+
+	recordEvent(m.recorder, host, corev1.EventTypeNormal,
+		RemediationRebootReason, "Rebooted by Metal3Remediation %s, retry %d",
+		m.Remediation.Name, m.Remediation.Status.RetryCount+1,
+	)
+	recordEvent(m.recorder, m.Remediation, corev1.EventTypeNormal,
+		RemediationRebootReason, "Rebooting host %s, retry %d", host.Name,
+		m.Remediation.Status.RetryCount+1,
+	)
+
+	now := metav1.Now()
+	m.Remediation.Status.RetryCount++
+	m.Remediation.Status.LastRemediated = &now
+	m.Remediation.Status.Phase = capm3.PhaseWaiting
+	return &RequeueAfterError{RequeueAfter: m.timeout()}
+}
+
+// deleteMachine marks the host unhealthy and deletes the Machine.
+func (m *RemediationManager) deleteMachine(ctx context.Context,
+	host *bmh.BareMetalHost, helper *patch.Helper,
+) error {
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	if _, ok := host.Annotations[capm3.UnhealthyAnnotation]; !ok {
+		m.Log.Info("Marking the host unhealthy", "host", host.Name)
+		host.Annotations[capm3.UnhealthyAnnotation] = ""
+		if err := helper.Patch(ctx, host); err != nil {
+			return errors.Wrap(err, "failed to set the unhealthy annotation on the host")
+		}
+	}
+
+	if !m.Machine.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	m.Log.Info("Deleting the unhealthy Machine", "machine", m.Machine.Name)
+	if err := m.client.Delete(ctx, m.Machine); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete the Machine")
+	}
+	recordEvent(m.recorder, m.Remediation, corev1.EventTypeWarning,
+		RemediationMachineDeletedReason,
+		"Deleting Machine %s after %d reboots of host %s", m.Machine.Name,
+		m.Remediation.Status.RetryCount, host.Name,
+	)
+	return nil
+}
+
+// getHost returns the host of the Metal3Machine and a patch helper for it.
+func (m *RemediationManager) getHost(ctx context.Context) (*bmh.BareMetalHost, *patch.Helper, error) {
+	host, err := getHost(ctx, m.Metal3Machine, m.client, m.Log)
+	if err != nil || host == nil {
+		return host, nil, err
+	}
+	helper, err := patch.NewHelper(host, m.client)
+	return host, helper, err
+}
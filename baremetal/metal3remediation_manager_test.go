@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metal3Remediation manager", func() {
+
+	type testCaseReconcile struct {
+		Strategy            *capm3.RemediationStrategy
+		Status              capm3.Metal3RemediationStatus
+		HostAnnotation      string
+		ExpectRequeue       bool
+		ExpectedPhase       string
+		ExpectedRetryCount  int
+		ExpectReboot        bool
+		ExpectUnhealthy     bool
+		ExpectMachineDelete bool
+		ExpectedEvents      []string
+	}
+
+	rebootStrategy := &capm3.RemediationStrategy{
+		Type:       capm3.RebootRemediationStrategy,
+		RetryLimit: pointer.Int32Ptr(2),
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}
+	justRebooted := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	rebootedLongAgo := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+	DescribeTable("Test Reconcile",
+		func(tc testCaseReconcile) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myhost",
+					Namespace: "myns",
+				},
+			}
+			machine := &capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mymachine",
+					Namespace: "myns",
+				},
+			}
+			m3m := &capm3.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mym3machine",
+					Namespace: "myns",
+				},
+			}
+			if tc.HostAnnotation != "" {
+				m3m.Annotations = map[string]string{
+					HostAnnotation: tc.HostAnnotation,
+				}
+			}
+			remediation := &capm3.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mymachine",
+					Namespace: "myns",
+				},
+				Spec:   capm3.Metal3RemediationSpec{Strategy: tc.Strategy},
+				Status: tc.Status,
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+				[]runtime.Object{host, machine}...,
+			)
+
+			recorder := record.NewFakeRecorder(10)
+			remediationMgr, err := NewRemediationManager(c, recorder,
+				remediation, m3m, machine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = remediationMgr.Reconcile(context.TODO())
+			if tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(remediation.Status.Phase).To(Equal(tc.ExpectedPhase))
+			Expect(remediation.Status.RetryCount).To(Equal(tc.ExpectedRetryCount))
+
+			savedHost := bmh.BareMetalHost{}
+			err = c.Get(context.TODO(), client.ObjectKey{
+				Name: "myhost", Namespace: "myns",
+			}, &savedHost)
+			Expect(err).NotTo(HaveOccurred())
+			_, rebooted := savedHost.Annotations[capm3.RebootAnnotation]
+			Expect(rebooted).To(Equal(tc.ExpectReboot))
+			_, unhealthy := savedHost.Annotations[capm3.UnhealthyAnnotation]
+			Expect(unhealthy).To(Equal(tc.ExpectUnhealthy))
+
+			savedMachine := capi.Machine{}
+			err = c.Get(context.TODO(), client.ObjectKey{
+				Name: "mymachine", Namespace: "myns",
+			}, &savedMachine)
+			if tc.ExpectMachineDelete {
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(recorder.Events).To(HaveLen(len(tc.ExpectedEvents)))
+			for _, expectedEvent := range tc.ExpectedEvents {
+				Expect(<-recorder.Events).To(Equal(expectedEvent))
+			}
+		},
+		Entry("Unsupported strategy", testCaseReconcile{
+			HostAnnotation: "myns/myhost",
+		}),
+		Entry("No host", testCaseReconcile{
+			Strategy: rebootStrategy,
+		}),
+		Entry("First reboot", testCaseReconcile{
+			Strategy:           rebootStrategy,
+			HostAnnotation:     "myns/myhost",
+			ExpectRequeue:      true,
+			ExpectedPhase:      capm3.PhaseWaiting,
+			ExpectedRetryCount: 1,
+			ExpectReboot:       true,
+			ExpectedEvents: []string{
+				"Normal RemediationReboot Rebooted by Metal3Remediation mymachine, retry 1",
+				"Normal RemediationReboot Rebooting host myhost, retry 1",
+			},
+		}),
+		Entry("Waiting for the node", testCaseReconcile{
+			Strategy:       rebootStrategy,
+			HostAnnotation: "myns/myhost",
+			Status: capm3.Metal3RemediationStatus{
+				Phase:          capm3.PhaseWaiting,
+				RetryCount:     1,
+				LastRemediated: &justRebooted,
+			},
+			ExpectRequeue:      true,
+			ExpectedPhase:      capm3.PhaseWaiting,
+			ExpectedRetryCount: 1,
+		}),
+		Entry("Timed out, reboot again", testCaseReconcile{
+			Strategy:       rebootStrategy,
+			HostAnnotation: "myns/myhost",
+			Status: capm3.Metal3RemediationStatus{
+				Phase:          capm3.PhaseWaiting,
+				RetryCount:     1,
+				LastRemediated: &rebootedLongAgo,
+			},
+			ExpectRequeue:      true,
+			ExpectedPhase:      capm3.PhaseWaiting,
+			ExpectedRetryCount: 2,
+			ExpectReboot:       true,
+			ExpectedEvents: []string{
+				"Normal RemediationReboot Rebooted by Metal3Remediation mymachine, retry 2",
+				"Normal RemediationReboot Rebooting host myhost, retry 2",
+			},
+		}),
+		Entry("Timed out, retry limit reached", testCaseReconcile{
+			Strategy:       rebootStrategy,
+			HostAnnotation: "myns/myhost",
+			Status: capm3.Metal3RemediationStatus{
+				Phase:          capm3.PhaseWaiting,
+				RetryCount:     2,
+				LastRemediated: &rebootedLongAgo,
+			},
+			ExpectedPhase:       capm3.PhaseDeleting,
+			ExpectedRetryCount:  2,
+			ExpectUnhealthy:     true,
+			ExpectMachineDelete: true,
+			ExpectedEvents: []string{
+				"Warning RemediationMachineDeleted Deleting Machine mymachine after 2 reboots of host myhost",
+			},
+		}),
+		Entry("Default retry limit", testCaseReconcile{
+			Strategy: &capm3.RemediationStrategy{
+				Type: capm3.RebootRemediationStrategy,
+			},
+			HostAnnotation:     "myns/myhost",
+			ExpectRequeue:      true,
+			ExpectedPhase:      capm3.PhaseWaiting,
+			ExpectedRetryCount: 1,
+			ExpectReboot:       true,
+			ExpectedEvents: []string{
+				"Normal RemediationReboot Rebooted by Metal3Remediation mymachine, retry 1",
+				"Normal RemediationReboot Rebooting host myhost, retry 1",
+			},
+		}),
+		Entry("No retry allowed", testCaseReconcile{
+			Strategy: &capm3.RemediationStrategy{
+				Type:       capm3.RebootRemediationStrategy,
+				RetryLimit: pointer.Int32Ptr(0),
+			},
+			HostAnnotation:      "myns/myhost",
+			ExpectedPhase:       capm3.PhaseDeleting,
+			ExpectUnhealthy:     true,
+			ExpectMachineDelete: true,
+			ExpectedEvents: []string{
+				"Warning RemediationMachineDeleted Deleting Machine mymachine after 0 reboots of host myhost",
+			},
+		}),
+	)
+})
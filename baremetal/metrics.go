@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	hostStatusAvailable = "available"
+	hostStatusConsumed  = "consumed"
+	hostStatusUnhealthy = "unhealthy"
+
+	// hostPoolListTimeout is the timeout of the listing of the BareMetalHosts
+	// when the metrics are scraped.
+	hostPoolListTimeout = 10 * time.Second
+
+	// ipClaimObservedAnnotation is the key for an annotation on the IPClaims
+	// of the Metal3Datas recording that the allocation of their address was
+	// observed.
+	ipClaimObservedAnnotation = "metal3.io/allocation-observed"
+)
+
+var (
+	machineProvisioningDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "metal3_machine_provisioning_duration_seconds",
+			Help:    "Time from the creation of a Metal3Machine to it being ready.",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+		}, []string{"role"},
+	)
+
+	machineDeprovisioningDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "metal3_machine_deprovisioning_duration_seconds",
+			Help:    "Time from the deletion of a Metal3Machine to its BareMetalHost being released.",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+		}, []string{"role"},
+	)
+
+	bareMetalHostsDesc = prometheus.NewDesc("metal3_baremetalhosts",
+		"Number of BareMetalHosts, by namespace and status.",
+		[]string{"namespace", "status"}, nil,
+	)
+
+	ipClaimWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "metal3_ipclaim_wait_duration_seconds",
+			Help:    "Time from the creation of an IPClaim to its address being allocated.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"namespace", "pool"},
+	)
+
+	// RequeueTotal counts the RequeueAfterErrors returned to the controllers,
+	// by reason.
+	RequeueTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "metal3_requeue_total",
+			Help: "Number of reconciliations requeued after a RequeueAfterError, by reason.",
+		}, []string{"reason"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		machineProvisioningDuration,
+		machineDeprovisioningDuration,
+		ipClaimWaitDuration,
+		RequeueTotal,
+	)
+}
+
+// observeDuration observes the time elapsed since start, unless start is
+// not set.
+func observeDuration(histogram *prometheus.HistogramVec, start *metav1.Time,
+	labelValues ...string,
+) {
+	if start == nil || start.IsZero() {
+		return
+	}
+	histogram.WithLabelValues(labelValues...).Observe(
+		time.Since(start.Time).Seconds(),
+	)
+}
+
+// observeInterval observes the time elapsed from start to end, unless one of
+// them is not set.
+func observeInterval(histogram *prometheus.HistogramVec, start, end metav1.Time,
+	labelValues ...string,
+) {
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	histogram.WithLabelValues(labelValues...).Observe(
+		end.Sub(start.Time).Seconds(),
+	)
+}
+
+// HostPoolCollector reports the number of BareMetalHosts by namespace and
+// status. The hosts are listed when the metrics are scraped, so that the
+// values are never stale.
+type HostPoolCollector struct {
+	client client.Reader
+}
+
+// NewHostPoolCollector returns a collector listing the BareMetalHosts with the
+// client.
+func NewHostPoolCollector(client client.Reader) *HostPoolCollector {
+	return &HostPoolCollector{client: client}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *HostPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bareMetalHostsDesc
+}
+
+// Collect implements the prometheus.Collector interface. Nothing is reported
+// if the hosts can not be listed.
+func (c *HostPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostPoolListTimeout)
+	defer cancel()
+	hosts := bmh.BareMetalHostList{}
+	if err := c.client.List(ctx, &hosts); err != nil {
+		log.Log.WithName("metrics").Error(err, "failed to list the BareMetalHosts")
+		return
+	}
+
+	counts := map[string]map[string]int{}
+	for _, host := range hosts.Items {
+		if _, ok := counts[host.Namespace]; !ok {
+			counts[host.Namespace] = map[string]int{
+				hostStatusAvailable: 0,
+				hostStatusConsumed:  0,
+				hostStatusUnhealthy: 0,
+			}
+		}
+		if status := hostPoolStatus(&host); status != "" {
+			counts[host.Namespace][status]++
+		}
+	}
+	for namespace, namespaceCounts := range counts {
+		for status, count := range namespaceCounts {
+			ch <- prometheus.MustNewConstMetric(bareMetalHostsDesc,
+				prometheus.GaugeValue, float64(count), namespace, status,
+			)
+		}
+	}
+}
+
+// hostPoolStatus returns the status of the host in the pool, or an empty
+// string if the host is neither consumed, unhealthy nor available.
+func hostPoolStatus(host *bmh.BareMetalHost) string {
+	if host.Spec.ConsumerRef != nil {
+		return hostStatusConsumed
+	}
+	if _, ok := host.Annotations[capm3.UnhealthyAnnotation]; ok ||
+		host.HasError() {
+		return hostStatusUnhealthy
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return hostStatusAvailable
+	}
+	return ""
+}
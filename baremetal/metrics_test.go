@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metrics", func() {
+
+	host := func(name, rack string, state bmh.ProvisioningState,
+		consumed bool, annotations map[string]string,
+	) bmh.BareMetalHost {
+		host := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "metrics",
+				Labels:      map[string]string{"rack": rack},
+				Annotations: annotations,
+			},
+		}
+		host.Status.Provisioning.State = state
+		if consumed {
+			host.Spec.ConsumerRef = &corev1.ObjectReference{Name: "m3m"}
+		}
+		return host
+	}
+
+	It("Counts the hosts by namespace and status when scraped", func() {
+		hosts := []runtime.Object{}
+		for _, h := range []bmh.BareMetalHost{
+			host("host-0", "rack-1", bmh.StateReady, false, nil),
+			host("host-1", "rack-1", bmh.StateAvailable, false, nil),
+			host("host-2", "rack-1", bmh.StateProvisioned, true, nil),
+			host("host-3", "rack-1", bmh.StateReady, false,
+				map[string]string{capm3.UnhealthyAnnotation: ""},
+			),
+			host("host-4", "rack-1", bmh.StateInspecting, false, nil),
+		} {
+			hosts = append(hosts, h.DeepCopy())
+		}
+		otherHost := host("host-5", "rack-2", bmh.StateInspecting, false, nil)
+		otherHost.Namespace = "other"
+		hosts = append(hosts, &otherHost)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), hosts...)
+
+		expected := `
+# HELP metal3_baremetalhosts Number of BareMetalHosts, by namespace and status.
+# TYPE metal3_baremetalhosts gauge
+metal3_baremetalhosts{namespace="metrics",status="available"} 2
+metal3_baremetalhosts{namespace="metrics",status="consumed"} 1
+metal3_baremetalhosts{namespace="metrics",status="unhealthy"} 1
+metal3_baremetalhosts{namespace="other",status="available"} 0
+metal3_baremetalhosts{namespace="other",status="consumed"} 0
+metal3_baremetalhosts{namespace="other",status="unhealthy"} 0
+`
+		Expect(testutil.CollectAndCompare(NewHostPoolCollector(c),
+			strings.NewReader(expected),
+		)).To(Succeed())
+	})
+
+	It("Observes the intervals only if both times are set", func() {
+		histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "test_interval_seconds",
+		}, []string{"pool"})
+
+		start := metav1.NewTime(time.Now().Add(-time.Hour))
+		observeInterval(histogram, start, metav1.Time{}, "pool1")
+		observeInterval(histogram, metav1.Time{}, start, "pool1")
+		Expect(testutil.CollectAndCount(histogram)).To(Equal(0))
+
+		observeInterval(histogram, start,
+			metav1.NewTime(start.Add(5*time.Second)), "pool1",
+		)
+		Expect(testutil.CollectAndCount(histogram)).To(Equal(1))
+	})
+
+	It("Observes the durations only if the start time is set", func() {
+		histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "test_duration_seconds",
+		}, []string{"role"})
+
+		observeDuration(histogram, nil, bmRoleNode)
+		observeDuration(histogram, &metav1.Time{}, bmRoleNode)
+		Expect(testutil.CollectAndCount(histogram)).To(Equal(0))
+
+		start := metav1.NewTime(time.Now().Add(-time.Minute))
+		observeDuration(histogram, &start, bmRoleNode)
+		Expect(testutil.CollectAndCount(histogram)).To(Equal(1))
+	})
+})
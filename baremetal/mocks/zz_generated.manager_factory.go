@@ -113,3 +113,18 @@ func (mr *MockManagerFactoryInterfaceMockRecorder) NewDataManager(arg0, arg1 int
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDataManager", reflect.TypeOf((*MockManagerFactoryInterface)(nil).NewDataManager), arg0, arg1)
 }
+
+// NewRemediationManager mocks base method
+func (m *MockManagerFactoryInterface) NewRemediationManager(arg0 *v1alpha4.Metal3Remediation, arg1 *v1alpha4.Metal3Machine, arg2 *v1alpha3.Machine, arg3 logr.Logger) (baremetal.RemediationManagerInterface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewRemediationManager", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(baremetal.RemediationManagerInterface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewRemediationManager indicates an expected call of NewRemediationManager
+func (mr *MockManagerFactoryInterfaceMockRecorder) NewRemediationManager(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRemediationManager", reflect.TypeOf((*MockManagerFactoryInterface)(nil).NewRemediationManager), arg0, arg1, arg2, arg3)
+}
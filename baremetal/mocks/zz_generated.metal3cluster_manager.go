@@ -66,17 +66,17 @@ func (mr *MockClusterManagerInterfaceMockRecorder) Create(arg0 interface{}) *gom
 }
 
 // Delete mocks base method
-func (m *MockClusterManagerInterface) Delete() error {
+func (m *MockClusterManagerInterface) Delete(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete")
+	ret := m.ctrl.Call(m, "Delete", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockClusterManagerInterfaceMockRecorder) Delete() *gomock.Call {
+func (mr *MockClusterManagerInterfaceMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClusterManagerInterface)(nil).Delete))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClusterManagerInterface)(nil).Delete), arg0)
 }
 
 // UpdateClusterStatus mocks base method
@@ -131,3 +131,31 @@ func (mr *MockClusterManagerInterfaceMockRecorder) CountDescendants(arg0 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDescendants", reflect.TypeOf((*MockClusterManagerInterface)(nil).CountDescendants), arg0)
 }
+
+// SyncLoadBalancerBackends mocks base method
+func (m *MockClusterManagerInterface) SyncLoadBalancerBackends(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncLoadBalancerBackends", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncLoadBalancerBackends indicates an expected call of SyncLoadBalancerBackends
+func (mr *MockClusterManagerInterfaceMockRecorder) SyncLoadBalancerBackends(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncLoadBalancerBackends", reflect.TypeOf((*MockClusterManagerInterface)(nil).SyncLoadBalancerBackends), arg0)
+}
+
+// CheckControlPlaneEndpoint mocks base method
+func (m *MockClusterManagerInterface) CheckControlPlaneEndpoint(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckControlPlaneEndpoint", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckControlPlaneEndpoint indicates an expected call of CheckControlPlaneEndpoint
+func (mr *MockClusterManagerInterfaceMockRecorder) CheckControlPlaneEndpoint(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckControlPlaneEndpoint", reflect.TypeOf((*MockClusterManagerInterface)(nil).CheckControlPlaneEndpoint), arg0)
+}
@@ -191,6 +191,20 @@ func (mr *MockMachineManagerInterfaceMockRecorder) GetProviderIDAndBMHID() *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderIDAndBMHID", reflect.TypeOf((*MockMachineManagerInterface)(nil).GetProviderIDAndBMHID))
 }
 
+// BuildProviderID mocks base method
+func (m *MockMachineManagerInterface) BuildProviderID(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildProviderID", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BuildProviderID indicates an expected call of BuildProviderID
+func (mr *MockMachineManagerInterfaceMockRecorder) BuildProviderID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildProviderID", reflect.TypeOf((*MockMachineManagerInterface)(nil).BuildProviderID), arg0)
+}
+
 // SetNodeProviderID mocks base method
 func (m *MockMachineManagerInterface) SetNodeProviderID(arg0 context.Context, arg1, arg2 string, arg3 baremetal.ClientGetter) error {
 	m.ctrl.T.Helper()
@@ -205,6 +219,34 @@ func (mr *MockMachineManagerInterfaceMockRecorder) SetNodeProviderID(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNodeProviderID", reflect.TypeOf((*MockMachineManagerInterface)(nil).SetNodeProviderID), arg0, arg1, arg2, arg3)
 }
 
+// SyncNode mocks base method
+func (m *MockMachineManagerInterface) SyncNode(arg0 context.Context, arg1 baremetal.ClientGetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncNode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncNode indicates an expected call of SyncNode
+func (mr *MockMachineManagerInterfaceMockRecorder) SyncNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncNode", reflect.TypeOf((*MockMachineManagerInterface)(nil).SyncNode), arg0, arg1)
+}
+
+// CheckNode mocks base method
+func (m *MockMachineManagerInterface) CheckNode(arg0 context.Context, arg1 baremetal.ClientGetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckNode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckNode indicates an expected call of CheckNode
+func (mr *MockMachineManagerInterfaceMockRecorder) CheckNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckNode", reflect.TypeOf((*MockMachineManagerInterface)(nil).CheckNode), arg0, arg1)
+}
+
 // SetProviderID mocks base method
 func (m *MockMachineManagerInterface) SetProviderID(arg0 string) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,66 @@
+// /*
+// Copyright The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// */
+//
+//
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./baremetal/metal3remediation_manager.go
+
+// Package baremetal_mocks is a generated GoMock package.
+package baremetal_mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRemediationManagerInterface is a mock of RemediationManagerInterface interface
+type MockRemediationManagerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRemediationManagerInterfaceMockRecorder
+}
+
+// MockRemediationManagerInterfaceMockRecorder is the mock recorder for MockRemediationManagerInterface
+type MockRemediationManagerInterfaceMockRecorder struct {
+	mock *MockRemediationManagerInterface
+}
+
+// NewMockRemediationManagerInterface creates a new mock instance
+func NewMockRemediationManagerInterface(ctrl *gomock.Controller) *MockRemediationManagerInterface {
+	mock := &MockRemediationManagerInterface{ctrl: ctrl}
+	mock.recorder = &MockRemediationManagerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRemediationManagerInterface) EXPECT() *MockRemediationManagerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method
+func (m *MockRemediationManagerInterface) Reconcile(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconcile indicates an expected call of Reconcile
+func (mr *MockRemediationManagerInterfaceMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockRemediationManagerInterface)(nil).Reconcile), ctx)
+}
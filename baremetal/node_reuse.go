@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeReusePolicy returns the node reuse policy of the Metal3MachineTemplate
+// the metal3 machine was cloned from. It is empty if the metal3 machine was
+// not created from a template, or if the template is gone.
+func (m *MachineManager) nodeReusePolicy(ctx context.Context,
+) (capm3.NodeReusePolicy, error) {
+	templateName, ok := m.Metal3Machine.Annotations[capi.TemplateClonedFromNameAnnotation]
+	if !ok || templateName == "" {
+		return "", nil
+	}
+	groupKind := m.Metal3Machine.Annotations[capi.TemplateClonedFromGroupKindAnnotation]
+	if groupKind != "" && groupKind != "Metal3MachineTemplate."+capm3.GroupVersion.Group {
+		return "", nil
+	}
+
+	template := &capm3.Metal3MachineTemplate{}
+	key := client.ObjectKey{
+		Name:      templateName,
+		Namespace: m.Metal3Machine.Namespace,
+	}
+	if err := m.client.Get(ctx, key, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			m.Log.Info("Metal3MachineTemplate not found, not reusing hosts",
+				"template", templateName,
+			)
+			return "", nil
+		}
+		return "", err
+	}
+	return template.Spec.NodeReuse, nil
+}
+
+// nodeReuseOwner returns the name of the MachineDeployment or of the
+// KubeadmControlPlane of the machine, empty if it has none.
+func (m *MachineManager) nodeReuseOwner() string {
+	if m.Machine == nil {
+		return ""
+	}
+	if deployment, ok := m.Machine.Labels[capi.MachineDeploymentLabelName]; ok {
+		return deployment
+	}
+	for _, ownerRef := range m.Machine.OwnerReferences {
+		if ownerRef.Kind == "KubeadmControlPlane" {
+			return ownerRef.Name
+		}
+	}
+	return ""
+}
+
+// setNodeReuseLabel labels the host being released with the owner of the
+// machine if the template of the metal3 machine enables node reuse, and removes
+// the label otherwise. The label is removed when the host is chosen again.
+func (m *MachineManager) setNodeReuseLabel(ctx context.Context,
+	host *bmh.BareMetalHost,
+) error {
+	policy, err := m.nodeReusePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	owner := m.nodeReuseOwner()
+	if policy == "" || owner == "" {
+		delete(host.Labels, capm3.NodeReuseLabelName)
+		return nil
+	}
+	if host.Labels == nil {
+		host.Labels = map[string]string{}
+	}
+	m.Log.Info("Labelling the host for reuse", "host", host.Name, "owner", owner)
+	host.Labels[capm3.NodeReuseLabelName] = owner
+	return nil
+}
+
+// applyNodeReuse keeps the candidates released by the owner of the machine if
+// the template of the metal3 machine enables node reuse. With the Required
+// policy, no candidate is kept while a host released by the owner, e.g. still
+// deprovisioning, is not available. A released host that can not become
+// available, since it is deleted, in error or marked unhealthy, is not waited
+// for. hosts is the complete list of BareMetalHosts in the namespace. The
+// candidates held back while waiting are counted in rejections.
+func (m *MachineManager) applyNodeReuse(ctx context.Context,
+	candidates []*bmh.BareMetalHost, hosts []bmh.BareMetalHost,
+	rejections map[string]int,
+) ([]*bmh.BareMetalHost, error) {
+	policy, err := m.nodeReusePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := m.nodeReuseOwner()
+	if policy == "" || owner == "" {
+		return candidates, nil
+	}
+
+	reused := []*bmh.BareMetalHost{}
+	for _, candidate := range candidates {
+		if candidate.Labels[capm3.NodeReuseLabelName] == owner {
+			reused = append(reused, candidate)
+		}
+	}
+	if len(reused) > 0 {
+		m.Log.Info("Reusing a host released by the owner", "owner", owner)
+		return reused, nil
+	}
+
+	if policy == capm3.NodeReuseRequired {
+		failed := []string{}
+		for i := range hosts {
+			host := &hosts[i]
+			if host.Labels[capm3.NodeReuseLabelName] != owner {
+				continue
+			}
+			if !hostCanBeReused(host) {
+				failed = append(failed, host.Name)
+				continue
+			}
+			m.Log.Info("Waiting for a host released by the owner",
+				"owner", owner, "host", host.Name,
+			)
+			rejections[hostRejectedNodeReuse] += len(candidates)
+			recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeNormal,
+				NodeReuseWaitingReason,
+				"Waiting for BareMetalHost %s released by %s",
+				host.Name, owner,
+			)
+			return []*bmh.BareMetalHost{}, nil
+		}
+		if len(failed) > 0 {
+			m.Log.Info("The hosts released by the owner can not be reused",
+				"owner", owner, "hosts", failed,
+			)
+			recordEvent(m.recorder, m.Metal3Machine, corev1.EventTypeWarning,
+				NodeReuseFailedReason,
+				"BareMetalHosts %s released by %s can not be reused, choosing another host",
+				strings.Join(failed, ", "), owner,
+			)
+		}
+	}
+	return candidates, nil
+}
+
+// hostCanBeReused returns false if the host released for reuse can not become
+// available again without an action of the user, since it is deleted, in
+// error or marked unhealthy.
+func hostCanBeReused(host *bmh.BareMetalHost) bool {
+	if host.GetDeletionTimestamp() != nil || host.Status.ErrorMessage != "" {
+		return false
+	}
+	_, unhealthy := host.Annotations[capm3.UnhealthyAnnotation]
+	return !unhealthy
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Node reuse", func() {
+
+	templateWithNodeReuse := func(policy capm3.NodeReusePolicy,
+	) *capm3.Metal3MachineTemplate {
+		return &capm3.Metal3MachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "workers",
+				Namespace: "myns",
+			},
+			Spec: capm3.Metal3MachineTemplateSpec{NodeReuse: policy},
+		}
+	}
+
+	newMachineManager := func(policy capm3.NodeReusePolicy,
+		machine *capi.Machine,
+	) *MachineManager {
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(),
+			templateWithNodeReuse(policy),
+		)
+		m3machine := &capm3.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker-new",
+				Namespace: "myns",
+				Annotations: map[string]string{
+					capi.TemplateClonedFromNameAnnotation:      "workers",
+					capi.TemplateClonedFromGroupKindAnnotation: "Metal3MachineTemplate.infrastructure.cluster.x-k8s.io",
+				},
+			},
+		}
+		machineMgr, err := NewMachineManager(c, nil, nil, nil, machine, m3machine,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return machineMgr
+	}
+
+	deploymentMachine := &capi.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-new",
+			Namespace: "myns",
+			Labels:    map[string]string{capi.MachineDeploymentLabelName: "md-1"},
+		},
+	}
+	controlPlaneMachine := &capi.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp-new",
+			Namespace: "myns",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "KubeadmControlPlane", Name: "kcp-1"},
+			},
+		},
+	}
+
+	hostReusedBy := func(name, owner string, consumed bool) bmh.BareMetalHost {
+		host := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "myns",
+			},
+		}
+		if owner != "" {
+			host.Labels = map[string]string{capm3.NodeReuseLabelName: owner}
+		}
+		if consumed {
+			host.Spec.ConsumerRef = &corev1.ObjectReference{
+				Kind: "Metal3Machine",
+				Name: "worker-old",
+			}
+		}
+		return host
+	}
+
+	hostInError := func(host bmh.BareMetalHost) bmh.BareMetalHost {
+		host.Status.ErrorMessage = "Provisioning failed"
+		return host
+	}
+	hostUnhealthy := func(host bmh.BareMetalHost) bmh.BareMetalHost {
+		host.Annotations = map[string]string{capm3.UnhealthyAnnotation: ""}
+		return host
+	}
+
+	type testCaseApplyNodeReuse struct {
+		Policy             capm3.NodeReusePolicy
+		Machine            *capi.Machine
+		Hosts              []bmh.BareMetalHost
+		ExpectedHostNames  []string
+		ExpectedRejections int
+		ExpectedEvent      string
+	}
+
+	DescribeTable("Test applyNodeReuse",
+		func(tc testCaseApplyNodeReuse) {
+			machineMgr := newMachineManager(tc.Policy, tc.Machine)
+			recorder := record.NewFakeRecorder(10)
+			machineMgr.recorder = recorder
+
+			candidates := []*bmh.BareMetalHost{}
+			for i := range tc.Hosts {
+				if tc.Hosts[i].Spec.ConsumerRef == nil {
+					candidates = append(candidates, &tc.Hosts[i])
+				}
+			}
+			rejections := map[string]int{}
+			result, err := machineMgr.applyNodeReuse(context.TODO(),
+				candidates, tc.Hosts, rejections,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			resultNames := []string{}
+			for _, host := range result {
+				resultNames = append(resultNames, host.Name)
+			}
+			Expect(resultNames).To(Equal(tc.ExpectedHostNames))
+			Expect(rejections[hostRejectedNodeReuse]).To(Equal(tc.ExpectedRejections))
+			if tc.ExpectedEvent != "" {
+				Expect(recorder.Events).To(Receive(Equal(tc.ExpectedEvent)))
+			}
+		},
+		Entry("No node reuse", testCaseApplyNodeReuse{
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "md-1", false),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-0", "host-1"},
+		}),
+		Entry("Preferred, released host available", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReusePreferred,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "md-2", false),
+				hostReusedBy("host-1", "md-1", false),
+				hostReusedBy("host-2", "", false),
+			},
+			ExpectedHostNames: []string{"host-1"},
+		}),
+		Entry("Preferred, released host deprovisioning", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReusePreferred,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "md-1", true),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-1"},
+		}),
+		Entry("Required, released host deprovisioning", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "md-1", true),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames:  []string{},
+			ExpectedRejections: 1,
+			ExpectedEvent:      "Normal NodeReuseWaiting Waiting for BareMetalHost host-0 released by md-1",
+		}),
+		Entry("Required, released host in error", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostInError(hostReusedBy("host-0", "md-1", true)),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-1"},
+		}),
+		Entry("Required, released host unhealthy", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostUnhealthy(hostReusedBy("host-0", "md-1", true)),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-1"},
+		}),
+		Entry("Required, released hosts unhealthy and deprovisioning", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: deploymentMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostUnhealthy(hostReusedBy("host-0", "md-1", true)),
+				hostReusedBy("host-1", "md-1", true),
+				hostReusedBy("host-2", "", false),
+			},
+			ExpectedHostNames:  []string{},
+			ExpectedRejections: 1,
+			ExpectedEvent:      "Normal NodeReuseWaiting Waiting for BareMetalHost host-1 released by md-1",
+		}),
+		Entry("Required, no released host", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: controlPlaneMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "md-1", false),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-0", "host-1"},
+		}),
+		Entry("Required, control plane released host", testCaseApplyNodeReuse{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: controlPlaneMachine,
+			Hosts: []bmh.BareMetalHost{
+				hostReusedBy("host-0", "kcp-1", false),
+				hostReusedBy("host-1", "", false),
+			},
+			ExpectedHostNames: []string{"host-0"},
+		}),
+	)
+
+	type testCaseSetNodeReuseLabel struct {
+		Policy        capm3.NodeReusePolicy
+		Machine       *capi.Machine
+		HostLabels    map[string]string
+		ExpectedLabel string
+	}
+
+	DescribeTable("Test setNodeReuseLabel",
+		func(tc testCaseSetNodeReuseLabel) {
+			machineMgr := newMachineManager(tc.Policy, tc.Machine)
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "host-0",
+					Namespace: "myns",
+					Labels:    tc.HostLabels,
+				},
+			}
+
+			err := machineMgr.setNodeReuseLabel(context.TODO(), host)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(host.Labels[capm3.NodeReuseLabelName]).To(Equal(tc.ExpectedLabel))
+		},
+		Entry("No node reuse, stale label removed", testCaseSetNodeReuseLabel{
+			Machine:    deploymentMachine,
+			HostLabels: map[string]string{capm3.NodeReuseLabelName: "md-2"},
+		}),
+		Entry("Machine deployment", testCaseSetNodeReuseLabel{
+			Policy:        capm3.NodeReusePreferred,
+			Machine:       deploymentMachine,
+			ExpectedLabel: "md-1",
+		}),
+		Entry("Control plane", testCaseSetNodeReuseLabel{
+			Policy:        capm3.NodeReuseRequired,
+			Machine:       controlPlaneMachine,
+			HostLabels:    map[string]string{"foo": "bar"},
+			ExpectedLabel: "kcp-1",
+		}),
+		Entry("Machine without owner", testCaseSetNodeReuseLabel{
+			Policy:  capm3.NodeReuseRequired,
+			Machine: &capi.Machine{},
+		}),
+	)
+})
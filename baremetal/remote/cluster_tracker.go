@@ -0,0 +1,272 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kcfg "sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// NodeUUIDLabel is the label of the Nodes of the workload clusters
+	// containing the UID of their BareMetalHost.
+	NodeUUIDLabel = "metal3.io/uuid"
+
+	// healthCheckTimeout is the timeout of a request to the /healthz endpoint
+	// of a workload cluster.
+	healthCheckTimeout = 10 * time.Second
+)
+
+var trackerLog = log.Log.WithName("workload-cluster-tracker")
+
+// ClusterClientTracker reuses the clients of the workload clusters, keyed by
+// the namespace and name of the Cluster. The client of a cluster is rebuilt
+// when its kubeconfig secret changes, and dropped when the cluster is
+// deleted. The tracker periodically checks that the workload clusters are
+// reachable, reporting them in the metal3_workload_cluster_reachable metric,
+// and can watch their Nodes.
+type ClusterClientTracker struct {
+	lock     sync.Mutex
+	clusters map[types.NamespacedName]*trackedCluster
+
+	// interval is the period of the health checks.
+	interval time.Duration
+	// probe checks that a workload cluster is reachable.
+	probe func(context.Context, corev1.CoreV1Interface) error
+}
+
+// trackedCluster is the client of a workload cluster, along with the
+// kubeconfig it was built from and the channel stopping the watch on its
+// Nodes, if any.
+type trackedCluster struct {
+	kubeconfig    []byte
+	client        corev1.CoreV1Interface
+	stopNodeWatch chan struct{}
+}
+
+// stop stops the watch on the Nodes of the cluster.
+func (tc *trackedCluster) stop() {
+	if tc.stopNodeWatch != nil {
+		close(tc.stopNodeWatch)
+		tc.stopNodeWatch = nil
+	}
+}
+
+// NewClusterClientTracker returns a new tracker checking the workload
+// clusters every interval.
+func NewClusterClientTracker(interval time.Duration) *ClusterClientTracker {
+	return &ClusterClientTracker{
+		clusters: map[types.NamespacedName]*trackedCluster{},
+		interval: interval,
+		probe:    probeHealthz,
+	}
+}
+
+// GetClient returns the client of the workload cluster, building it only if
+// the cluster is not tracked yet or if its kubeconfig changed. It matches the
+// ClientGetter prototype of the baremetal package.
+func (t *ClusterClientTracker) GetClient(ctx context.Context, c client.Client,
+	cluster *clusterv1.Cluster,
+) (corev1.CoreV1Interface, error) {
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	if !cluster.DeletionTimestamp.IsZero() {
+		t.Delete(key)
+		return NewClusterClient(ctx, c, cluster)
+	}
+
+	kubeconfig, err := kcfg.FromSecret(ctx, c, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %q in namespace %q",
+			cluster.Name, cluster.Namespace)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	tracked, ok := t.clusters[key]
+	if ok && bytes.Equal(tracked.kubeconfig, kubeconfig) {
+		return tracked.client, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client configuration for Cluster %q in namespace %q",
+			cluster.Name, cluster.Namespace)
+	}
+	remoteClient, err := corev1.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		tracked.stop()
+	}
+	t.clusters[key] = &trackedCluster{
+		kubeconfig: kubeconfig,
+		client:     remoteClient,
+	}
+	return remoteClient, nil
+}
+
+// Delete drops the client of the workload cluster.
+func (t *ClusterClientTracker) Delete(key types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if tracked, ok := t.clusters[key]; ok {
+		tracked.stop()
+		delete(t.clusters, key)
+	}
+	workloadClusterReachable.DeleteLabelValues(key.Namespace, key.Name)
+}
+
+// WatchNodes watches the Nodes of the workload cluster carrying the
+// metal3.io/uuid label, calling handler whenever one is added, deleted, or
+// updated with other labels, annotations or spec. The watch is only started once per client of the cluster, and
+// stops when the client is rebuilt or dropped.
+func (t *ClusterClientTracker) WatchNodes(ctx context.Context, c client.Client,
+	cluster *clusterv1.Cluster,
+	handler func(cluster types.NamespacedName, node *corev1api.Node, deleted bool),
+) error {
+	remoteClient, err := t.GetClient(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	tracked, ok := t.clusters[key]
+	if !ok || tracked.client != remoteClient || tracked.stopNodeWatch != nil {
+		return nil
+	}
+
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = NodeUUIDLabel
+			return remoteClient.Nodes().List(context.Background(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = NodeUUIDLabel
+			return remoteClient.Nodes().Watch(context.Background(), options)
+		},
+	}, &corev1api.Node{}, 0, cache.Indexers{})
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if node, ok := obj.(*corev1api.Node); ok {
+				handler(key, node, false)
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldNode, ok := oldObj.(*corev1api.Node)
+			if !ok {
+				return
+			}
+			if node, ok := obj.(*corev1api.Node); ok && nodeChanged(oldNode, node) {
+				handler(key, node, false)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if node, ok := obj.(*corev1api.Node); ok {
+				handler(key, node, true)
+			}
+		},
+	})
+	tracked.stopNodeWatch = make(chan struct{})
+	go informer.Run(tracked.stopNodeWatch)
+	return nil
+}
+
+// nodeChanged returns true if the labels, the annotations or the spec of the
+// node changed, ignoring the status updates of the kubelet.
+func nodeChanged(oldNode, node *corev1api.Node) bool {
+	return !equality.Semantic.DeepEqual(oldNode.Labels, node.Labels) ||
+		!equality.Semantic.DeepEqual(oldNode.Annotations, node.Annotations) ||
+		!equality.Semantic.DeepEqual(oldNode.Spec, node.Spec)
+}
+
+// Start checks the workload clusters periodically until stop is closed. It
+// implements the Runnable interface of the controller-runtime manager.
+func (t *ClusterClientTracker) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			t.checkClusters(context.Background())
+		}
+	}
+}
+
+// checkClusters probes all the tracked workload clusters and records the
+// result in the metal3_workload_cluster_reachable metric. An unreachable
+// cluster is also logged.
+func (t *ClusterClientTracker) checkClusters(ctx context.Context) {
+	t.lock.Lock()
+	clients := map[types.NamespacedName]corev1.CoreV1Interface{}
+	for key, tracked := range t.clusters {
+		clients[key] = tracked.client
+	}
+	t.lock.Unlock()
+
+	for key, remoteClient := range clients {
+		err := t.probe(ctx, remoteClient)
+		t.lock.Lock()
+		// The client may have been rebuilt or dropped during the probe
+		if tracked, ok := t.clusters[key]; ok && tracked.client == remoteClient {
+			reachable := 1.0
+			if err != nil {
+				reachable = 0
+				trackerLog.Info("Workload cluster unreachable", "cluster", key.String(),
+					"error", err.Error(),
+				)
+			}
+			workloadClusterReachable.WithLabelValues(key.Namespace, key.Name).
+				Set(reachable)
+		}
+		t.lock.Unlock()
+	}
+}
+
+// probeHealthz requests the /healthz endpoint of the workload cluster.
+func probeHealthz(ctx context.Context, remoteClient corev1.CoreV1Interface) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return remoteClient.RESTClient().Get().AbsPath("/healthz").Do(ctx).Error()
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterClientTrackerGetClient(t *testing.T) {
+	key := types.NamespacedName{Name: "test1", Namespace: "test"}
+	client := fake.NewFakeClient(validSecret.DeepCopy())
+	tracker := NewClusterClientTracker(time.Minute)
+
+	c1, err := tracker.GetClient(context.TODO(), client, clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	c2, err := tracker.GetClient(context.TODO(), client, clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	if c1 != c2 {
+		t.Fatal("Expected the client to be reused")
+	}
+
+	updatedSecret := validSecret.DeepCopy()
+	updatedSecret.Data[secret.KubeconfigDataName] = []byte(strings.Replace(
+		validKubeConfig, "test-cluster-api:6443", "test-cluster-api:6444", 1,
+	))
+	if err := client.Update(context.TODO(), updatedSecret); err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	c3, err := tracker.GetClient(context.TODO(), client, clusterWithValidKubeConfig)
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	if c3 == c1 {
+		t.Fatal("Expected the client to be rebuilt after a kubeconfig change")
+	}
+
+	deletingCluster := clusterWithValidKubeConfig.DeepCopy()
+	now := metav1.Now()
+	deletingCluster.DeletionTimestamp = &now
+	if _, err := tracker.GetClient(context.TODO(), client, deletingCluster); err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	if _, ok := tracker.clusters[key]; ok {
+		t.Fatal("Expected the client of a deleted cluster to be dropped")
+	}
+
+	if _, err := tracker.GetClient(context.TODO(), client, clusterWithNoKubeConfig); err == nil {
+		t.Fatal("Expected an error for a cluster without kubeconfig")
+	}
+}
+
+func TestClusterClientTrackerCheckClusters(t *testing.T) {
+	client := fake.NewFakeClient(validSecret.DeepCopy())
+	tracker := NewClusterClientTracker(time.Minute)
+	remoteClient, err := tracker.GetClient(context.TODO(), client,
+		clusterWithValidKubeConfig,
+	)
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	reachable := workloadClusterReachable.WithLabelValues("test", "test1")
+
+	tracker.probe = func(_ context.Context, c corev1.CoreV1Interface) error {
+		return nil
+	}
+	tracker.checkClusters(context.TODO())
+	if value := testutil.ToFloat64(reachable); value != 1 {
+		t.Fatalf("Expected the cluster to be reachable, got %v", value)
+	}
+
+	tracker.probe = func(_ context.Context, c corev1.CoreV1Interface) error {
+		if c == remoteClient {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	tracker.checkClusters(context.TODO())
+	if value := testutil.ToFloat64(reachable); value != 0 {
+		t.Fatalf("Expected the cluster to be unreachable, got %v", value)
+	}
+
+	tracker.Delete(types.NamespacedName{Name: "test1", Namespace: "test"})
+	if count := testutil.CollectAndCount(workloadClusterReachable); count != 0 {
+		t.Fatalf("Expected the metric of the deleted cluster to be dropped, got %d",
+			count,
+		)
+	}
+}
+
+func TestClusterClientTrackerWatchNodes(t *testing.T) {
+	key := types.NamespacedName{Name: "test1", Namespace: "test"}
+	client := fake.NewFakeClient(validSecret.DeepCopy())
+	tracker := NewClusterClientTracker(time.Minute)
+	remoteClient := fakeclientset.NewSimpleClientset().CoreV1()
+	tracker.clusters[key] = &trackedCluster{
+		kubeconfig: []byte(validKubeConfig),
+		client:     remoteClient,
+	}
+
+	nodes := make(chan string, 10)
+	handler := func(cluster types.NamespacedName, node *corev1api.Node, deleted bool) {
+		if cluster != key {
+			t.Errorf("Expected cluster %v, got %v", key, cluster)
+		}
+		if deleted {
+			nodes <- "deleted " + node.Name
+			return
+		}
+		nodes <- node.Name
+	}
+	for i := 0; i < 2; i++ {
+		if err := tracker.WatchNodes(context.TODO(), client,
+			clusterWithValidKubeConfig, handler,
+		); err != nil {
+			t.Fatalf("Expected no errors, got %v", err)
+		}
+	}
+
+	_, err := remoteClient.Nodes().Create(context.TODO(), &corev1api.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1",
+			Labels: map[string]string{NodeUUIDLabel: "abc"},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	select {
+	case name := <-nodes:
+		if name != "node1" {
+			t.Fatalf("Expected node1, got %s", name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the Node to be watched")
+	}
+	select {
+	case name := <-nodes:
+		t.Fatalf("Expected a single watch, got a second event for %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	node, err := remoteClient.Nodes().Get(context.TODO(), "node1",
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	node.Status.Phase = corev1api.NodeRunning
+	if node, err = remoteClient.Nodes().UpdateStatus(context.TODO(), node,
+		metav1.UpdateOptions{},
+	); err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	select {
+	case name := <-nodes:
+		t.Fatalf("Expected the status update to be ignored, got an event for %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+	node.Labels["node-class"] = "storage"
+	if _, err = remoteClient.Nodes().Update(context.TODO(), node,
+		metav1.UpdateOptions{},
+	); err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	select {
+	case name := <-nodes:
+		if name != "node1" {
+			t.Fatalf("Expected node1, got %s", name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the Node update to be watched")
+	}
+
+	if err := remoteClient.Nodes().Delete(context.TODO(), "node1",
+		metav1.DeleteOptions{},
+	); err != nil {
+		t.Fatalf("Expected no errors, got %v", err)
+	}
+	select {
+	case name := <-nodes:
+		if name != "deleted node1" {
+			t.Fatalf("Expected the deletion of node1, got %s", name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the Node deletion to be watched")
+	}
+
+	tracker.Delete(key)
+	if _, ok := tracker.clusters[key]; ok {
+		t.Fatal("Expected the cluster to be dropped")
+	}
+}
@@ -28,6 +28,8 @@ type HasRequeueAfterError interface {
 	// GetRequeueAfter gets the duration to wait until the managed object is
 	// requeued for further processing.
 	GetRequeueAfter() time.Duration
+	// GetReason gets the reason why the managed object is requeued.
+	GetReason() string
 }
 
 // RequeueAfterError represents that an actuator managed object should be
@@ -35,10 +37,15 @@ type HasRequeueAfterError interface {
 // passed.
 type RequeueAfterError struct {
 	RequeueAfter time.Duration
+	// Reason is a short description of what the managed object waits for.
+	Reason string
 }
 
 // Error implements the error interface
 func (e *RequeueAfterError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("requeue in: %s: %s", e.RequeueAfter, e.Reason)
+	}
 	return fmt.Sprintf("requeue in: %s", e.RequeueAfter)
 }
 
@@ -47,3 +54,8 @@ func (e *RequeueAfterError) Error() string {
 func (e *RequeueAfterError) GetRequeueAfter() time.Duration {
 	return e.RequeueAfter
 }
+
+// GetReason gets the reason why the managed object is requeued.
+func (e *RequeueAfterError) GetReason() string {
+	return e.Reason
+}
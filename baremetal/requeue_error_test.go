@@ -31,13 +31,23 @@ const (
 
 var _ = Describe("Errors testing", func() {
 	It("returns the correct error", func() {
-		err := &RequeueAfterError{time.Second * RequeueDuration1}
+		err := &RequeueAfterError{RequeueAfter: time.Second * RequeueDuration1}
 		Expect(err.Error()).To(Equal(fmt.Sprintf("requeue in: %vs", RequeueDuration1)))
 	})
 
+	It("returns the reason in the error", func() {
+		err := &RequeueAfterError{RequeueAfter: time.Second * RequeueDuration1,
+			Reason: "waiting for the host",
+		}
+		Expect(err.Error()).To(Equal(fmt.Sprintf(
+			"requeue in: %vs: waiting for the host", RequeueDuration1,
+		)))
+		Expect(err.GetReason()).To(Equal("waiting for the host"))
+	})
+
 	It("Gets the correct duration", func() {
 		duration, _ := time.ParseDuration(fmt.Sprintf("%vs", RequeueDuration2))
-		err := &RequeueAfterError{time.Second * RequeueDuration2}
+		err := &RequeueAfterError{RequeueAfter: time.Second * RequeueDuration2}
 		Expect(err.GetRequeueAfter()).To(Equal(duration))
 	})
 })
@@ -210,7 +210,9 @@ func fetchM3DataTemplate(ctx context.Context,
 	if err := cl.Get(ctx, metal3DataTemplateName, metal3DataTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
 			mLog.Info("Metadata not found, requeuing")
-			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Metal3DataTemplate not found",
+			}
 		} else {
 			err := errors.Wrap(err, "Failed to get metadata")
 			return nil, err
@@ -237,7 +239,9 @@ func fetchM3DataClaim(ctx context.Context, cl client.Client, mLog logr.Logger,
 	if err := cl.Get(ctx, metal3DataName, m3Data); err != nil {
 		if apierrors.IsNotFound(err) {
 			mLog.Info("Data Claim not found")
-			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Metal3DataClaim not found",
+			}
 		} else {
 			err := errors.Wrap(err, "Failed to get metadata")
 			return nil, err
@@ -258,7 +262,9 @@ func fetchM3Data(ctx context.Context, cl client.Client, mLog logr.Logger,
 	if err := cl.Get(ctx, metal3DataName, m3Data); err != nil {
 		if apierrors.IsNotFound(err) {
 			mLog.Info("Rendered data not found, requeuing")
-			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+				Reason: "Metal3Data not found",
+			}
 		} else {
 			err := errors.Wrap(err, "Failed to get metadata")
 			return nil, err
@@ -282,7 +288,9 @@ func getM3Machine(ctx context.Context, cl client.Client, mLog logr.Logger,
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			if requeueifNotFound {
-				return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+				return nil, &RequeueAfterError{RequeueAfter: requeueAfter,
+					Reason: "Metal3Machine not found",
+				}
 			}
 			return nil, nil
 		} else {
@@ -308,6 +316,40 @@ func getM3Machine(ctx context.Context, cl client.Client, mLog logr.Logger,
 	return tmpM3Machine, nil
 }
 
-func parseProviderID(providerID string) string {
-	return strings.TrimPrefix(providerID, "metal3://")
+// providerIDPrefix is the prefix of the providerIDs of the Metal3Machines.
+const providerIDPrefix = "metal3://"
+
+// providerIDParts are the parts of a providerID, either the UID of the
+// BareMetalHost, or its namespace and name and the name of the Metal3Machine.
+type providerIDParts struct {
+	HostUID           string
+	Namespace         string
+	HostName          string
+	Metal3MachineName string
+}
+
+// parseProviderID parses a providerID in the metal3://<bmh-uid> or the
+// metal3://<namespace>/<bmh-name>/<metal3machine-name> format.
+func parseProviderID(providerID string) (providerIDParts, error) {
+	if !strings.HasPrefix(providerID, providerIDPrefix) {
+		return providerIDParts{}, errors.Errorf("providerID %q does not start with %s",
+			providerID, providerIDPrefix,
+		)
+	}
+	parts := strings.Split(strings.TrimPrefix(providerID, providerIDPrefix), "/")
+	for _, part := range parts {
+		if part == "" {
+			return providerIDParts{}, errors.Errorf("invalid providerID %q", providerID)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return providerIDParts{HostUID: parts[0]}, nil
+	case 3:
+		return providerIDParts{Namespace: parts[0], HostName: parts[1],
+			Metal3MachineName: parts[2],
+		}, nil
+	default:
+		return providerIDParts{}, errors.Errorf("invalid providerID %q", providerID)
+	}
 }
@@ -732,8 +732,25 @@ var _ = Describe("Metal3 manager utils", func() {
 		}),
 	)
 
-	It("Parses the providerID properly", func() {
-		Expect(parseProviderID("metal3://abcd")).To(Equal("abcd"))
-		Expect(parseProviderID("foo://abcd")).To(Equal("foo://abcd"))
-	})
+	DescribeTable("Test parseProviderID",
+		func(providerID string, expectedParts providerIDParts, expectError bool) {
+			parts, err := parseProviderID(providerID)
+			if expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parts).To(Equal(expectedParts))
+		},
+		Entry("UID format", "metal3://abcd", providerIDParts{HostUID: "abcd"},
+			false,
+		),
+		Entry("Name format", "metal3://myns/host1/m3m1", providerIDParts{
+			Namespace: "myns", HostName: "host1", Metal3MachineName: "m3m1",
+		}, false),
+		Entry("Other provider", "foo://abcd", providerIDParts{}, true),
+		Entry("Empty UID", "metal3://", providerIDParts{}, true),
+		Entry("Missing name", "metal3://myns/host1", providerIDParts{}, true),
+		Entry("Empty name", "metal3://myns//m3m1", providerIDParts{}, true),
+	)
 })
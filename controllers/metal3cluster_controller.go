@@ -25,14 +25,20 @@ import (
 
 	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal/remote"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -48,11 +54,20 @@ type Metal3ClusterReconciler struct {
 	Client         client.Client
 	ManagerFactory baremetal.ManagerFactoryInterface
 	Log            logr.Logger
+	ClusterTracker *remote.ClusterClientTracker
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3clusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3clusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.metal3.io,resources=ipclaims,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=ipam.metal3.io,resources=ipaddresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update;delete
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3datatemplates,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a Metal3Cluster object and makes changes based on the state read
 // and what is in the Metal3Cluster.Spec
@@ -78,6 +93,9 @@ func (r *Metal3ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	}
 	// Always patch metal3Cluster when exiting this function so we can persist any metal3Cluster changes.
 	defer func() {
+		conditions.SetSummary(metal3Cluster,
+			conditions.WithConditions(capm3.BaremetalInfrastructureReadyCondition),
+		)
 		err := helper.Patch(ctx, metal3Cluster)
 		if err != nil {
 			clusterLog.Error(err, "failed to Patch metal3Cluster")
@@ -118,6 +136,8 @@ func (r *Metal3ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 
 	// Handle deleted clusters
 	if !metal3Cluster.DeletionTimestamp.IsZero() {
+		// Drop the cached client of the workload cluster
+		r.ClusterTracker.Delete(util.ObjectKey(cluster))
 		return reconcileDelete(ctx, clusterMgr)
 	}
 
@@ -129,9 +149,12 @@ func reconcileNormal(ctx context.Context, clusterMgr baremetal.ClusterManagerInt
 	// If the Metal3Cluster doesn't have finalizer, add it.
 	clusterMgr.SetFinalizer()
 
-	//Create the Metal3 cluster (no-op)
+	// Create the Metal3 cluster, allocating the control plane endpoint if
+	// needed
 	if err := clusterMgr.Create(ctx); err != nil {
-		return ctrl.Result{}, err
+		return checkRequeueError(objectBackoff{}, err,
+			"failed to create the Metal3Cluster",
+		)
 	}
 
 	// Set APIEndpoints so the Cluster API Cluster Controller can pull it
@@ -139,6 +162,18 @@ func reconcileNormal(ctx context.Context, clusterMgr baremetal.ClusterManagerInt
 		return ctrl.Result{}, errors.Wrap(err, "failed to get ip for the API endpoint")
 	}
 
+	// Keep the backends of the external load balancer in sync
+	if err := clusterMgr.SyncLoadBalancerBackends(ctx); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to sync the load balancer backends")
+	}
+
+	// Probe the control plane endpoint periodically
+	if err := clusterMgr.CheckControlPlaneEndpoint(ctx); err != nil {
+		return checkRequeueError(objectBackoff{}, err,
+			"failed to check the control plane endpoint",
+		)
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -156,8 +191,11 @@ func reconcileDelete(ctx context.Context,
 		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
 	}
 
-	if err := clusterMgr.Delete(); err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to delete Metal3Cluster")
+	// Sweep the resources left behind by the machines of the cluster
+	if err := clusterMgr.Delete(ctx); err != nil {
+		return checkRequeueError(objectBackoff{}, err,
+			"failed to delete Metal3Cluster",
+		)
 	}
 
 	// Cluster is deleted so remove the finalizer.
@@ -167,8 +205,9 @@ func reconcileDelete(ctx context.Context,
 }
 
 // SetupWithManager will add watches for this controller
-func (r *Metal3ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *Metal3ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
 		For(&capm3.Metal3Cluster{}).
 		Watches(
 			&source.Kind{Type: &capi.Cluster{}},
@@ -178,5 +217,78 @@ func (r *Metal3ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				),
 			},
 		).
+		Watches(
+			&source.Kind{Type: &ipamv1.IPClaim{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.IPClaimToMetal3Cluster),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &capm3.Metal3Machine{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.Metal3MachineToMetal3Cluster),
+			},
+		).
 		Complete(r)
 }
+
+// Metal3MachineToMetal3Cluster will return a reconcile request for the
+// Metal3Cluster of a control plane Metal3Machine, to keep the backends of the
+// external load balancer in sync.
+func (r *Metal3ClusterReconciler) Metal3MachineToMetal3Cluster(obj handler.MapObject) []ctrl.Request {
+	requests := []ctrl.Request{}
+	if _, ok := obj.Meta.GetLabels()[capi.MachineControlPlaneLabelName]; !ok {
+		return requests
+	}
+	clusterName, ok := obj.Meta.GetLabels()[capi.ClusterLabelName]
+	if !ok {
+		return requests
+	}
+	cluster, err := util.GetClusterByName(context.Background(), r.Client,
+		obj.Meta.GetNamespace(), clusterName,
+	)
+	if err != nil {
+		if !apierrors.IsNotFound(errors.Cause(err)) {
+			r.Log.Error(err, "failed to get the Cluster of the Metal3Machine")
+		}
+		return requests
+	}
+	infraRef := cluster.Spec.InfrastructureRef
+	if infraRef == nil || infraRef.Kind != "Metal3Cluster" {
+		return requests
+	}
+	return append(requests, ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      infraRef.Name,
+			Namespace: cluster.Namespace,
+		},
+	})
+}
+
+// IPClaimToMetal3Cluster will return a reconcile request for a Metal3Cluster
+// if the event is for an IPClaim of its control plane endpoint.
+func (r *Metal3ClusterReconciler) IPClaimToMetal3Cluster(obj handler.MapObject) []ctrl.Request {
+	requests := []ctrl.Request{}
+	if ipClaim, ok := obj.Object.(*ipamv1.IPClaim); ok {
+		for _, ownerRef := range ipClaim.OwnerReferences {
+			if ownerRef.Kind != "Metal3Cluster" {
+				continue
+			}
+			aGV, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+			if err != nil {
+				r.Log.Error(err, "failed to parse the API version")
+				continue
+			}
+			if aGV.Group != capm3.GroupVersion.Group {
+				continue
+			}
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ownerRef.Name,
+					Namespace: ipClaim.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
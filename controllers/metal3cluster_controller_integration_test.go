@@ -51,7 +51,7 @@ var _ = Describe("Reconcile metal3Cluster", func() {
 
 			r := &Metal3ClusterReconciler{
 				Client:         c,
-				ManagerFactory: baremetal.NewManagerFactory(c),
+				ManagerFactory: baremetal.NewManagerFactory(c, nil),
 				Log:            klogr.New(),
 			}
 
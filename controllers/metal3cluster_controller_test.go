@@ -14,21 +14,36 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/golang/mock/gomock"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
 	baremetal_mocks "github.com/metal3-io/cluster-api-provider-metal3/baremetal/mocks"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 var _ = Describe("Metal3Cluster controller", func() {
 
 	type testCaseClusterNormal struct {
 		CreateError   bool
+		CreateRequeue bool
 		UpdateError   bool
+		SyncError     bool
+		CheckRequeue  bool
 		ExpectError   bool
 		ExpectRequeue bool
 	}
@@ -37,6 +52,7 @@ var _ = Describe("Metal3Cluster controller", func() {
 		DescendantsCount int
 		DescendantsError bool
 		DeleteError      bool
+		DeleteRequeue    bool
 		ExpectError      bool
 		ExpectRequeue    bool
 	}
@@ -61,11 +77,32 @@ var _ = Describe("Metal3Cluster controller", func() {
 			if tc.CreateError {
 				returnedError = errors.New("Error")
 				m.EXPECT().UpdateClusterStatus().MaxTimes(0)
+			} else if tc.CreateRequeue {
+				returnedError = &baremetal.RequeueAfterError{}
+				m.EXPECT().UpdateClusterStatus().MaxTimes(0)
 			} else {
 				if tc.UpdateError {
 					returnedError = errors.New("Error")
+					m.EXPECT().SyncLoadBalancerBackends(context.TODO()).MaxTimes(0)
 				} else {
 					returnedError = nil
+					var syncError error
+					if tc.SyncError {
+						syncError = errors.New("Error")
+						m.EXPECT().CheckControlPlaneEndpoint(context.TODO()).
+							MaxTimes(0)
+					} else {
+						var checkError error
+						if tc.CheckRequeue {
+							checkError = &baremetal.RequeueAfterError{
+								RequeueAfter: time.Minute,
+							}
+						}
+						m.EXPECT().CheckControlPlaneEndpoint(context.TODO()).
+							Return(checkError)
+					}
+					m.EXPECT().SyncLoadBalancerBackends(context.TODO()).
+						Return(syncError)
 				}
 				m.EXPECT().UpdateClusterStatus().Return(returnedError)
 				returnedError = nil
@@ -98,12 +135,27 @@ var _ = Describe("Metal3Cluster controller", func() {
 			ExpectError:   true,
 			ExpectRequeue: false,
 		}),
+		Entry("Create requeue", testCaseClusterNormal{
+			CreateRequeue: true,
+			ExpectError:   false,
+			ExpectRequeue: true,
+		}),
 		Entry("Update error", testCaseClusterNormal{
 			CreateError:   false,
 			UpdateError:   true,
 			ExpectError:   true,
 			ExpectRequeue: false,
 		}),
+		Entry("Sync error", testCaseClusterNormal{
+			SyncError:     true,
+			ExpectError:   true,
+			ExpectRequeue: false,
+		}),
+		Entry("Periodic health check", testCaseClusterNormal{
+			CheckRequeue:  true,
+			ExpectError:   false,
+			ExpectRequeue: true,
+		}),
 	)
 
 	DescribeTable("Test ClusterReconcileDelete",
@@ -114,7 +166,7 @@ var _ = Describe("Metal3Cluster controller", func() {
 			// If we get an error while listing descendants or some still exists,
 			// we will exit with error or requeue.
 			if tc.DescendantsError || tc.DescendantsCount != 0 {
-				m.EXPECT().Delete().MaxTimes(0)
+				m.EXPECT().Delete(context.TODO()).MaxTimes(0)
 				m.EXPECT().UnsetFinalizer().MaxTimes(0)
 			} else {
 				// if no descendants are left, but we hit an error during delete,
@@ -122,11 +174,14 @@ var _ = Describe("Metal3Cluster controller", func() {
 				if tc.DeleteError {
 					m.EXPECT().UnsetFinalizer().MaxTimes(0)
 					returnedError = errors.New("Error")
+				} else if tc.DeleteRequeue {
+					m.EXPECT().UnsetFinalizer().MaxTimes(0)
+					returnedError = &baremetal.RequeueAfterError{}
 				} else {
 					m.EXPECT().UnsetFinalizer()
 					returnedError = nil
 				}
-				m.EXPECT().Delete().Return(returnedError)
+				m.EXPECT().Delete(context.TODO()).Return(returnedError)
 			}
 
 			if tc.DescendantsError {
@@ -180,5 +235,138 @@ var _ = Describe("Metal3Cluster controller", func() {
 			ExpectError:      true,
 			ExpectRequeue:    false,
 		}),
+		Entry("Delete requeue", testCaseClusterDelete{
+			DescendantsCount: 0,
+			DeleteRequeue:    true,
+			ExpectError:      false,
+			ExpectRequeue:    true,
+		}),
+	)
+
+	type testCaseIPClaimToMetal3Cluster struct {
+		ownerRefs        []metav1.OwnerReference
+		expectedRequests []ctrl.Request
+	}
+
+	DescribeTable("test IPClaimToMetal3Cluster",
+		func(tc testCaseIPClaimToMetal3Cluster) {
+			ipClaim := &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "myns",
+					OwnerReferences: tc.ownerRefs,
+				},
+			}
+			r := Metal3ClusterReconciler{}
+			obj := handler.MapObject{
+				Object: ipClaim,
+			}
+			reqs := r.IPClaimToMetal3Cluster(obj)
+			Expect(reqs).To(Equal(tc.expectedRequests))
+		},
+		Entry("No OwnerRefs", testCaseIPClaimToMetal3Cluster{
+			expectedRequests: []ctrl.Request{},
+		}),
+		Entry("OwnerRefs", testCaseIPClaimToMetal3Cluster{
+			ownerRefs: []metav1.OwnerReference{
+				{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "Metal3Cluster",
+					Name:       "abc",
+				},
+				{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "Metal3Data",
+					Name:       "bcd",
+				},
+				{
+					APIVersion: "foo.bar/v1",
+					Kind:       "Metal3Cluster",
+					Name:       "cde",
+				},
+			},
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "abc",
+						Namespace: "myns",
+					},
+				},
+			},
+		}),
+	)
+
+	type testCaseMetal3MachineToMetal3Cluster struct {
+		labels           map[string]string
+		infraKind        string
+		expectedRequests []ctrl.Request
+	}
+
+	DescribeTable("test Metal3MachineToMetal3Cluster",
+		func(tc testCaseMetal3MachineToMetal3Cluster) {
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster1",
+					Namespace: "myns",
+				},
+				Spec: capi.ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{
+						Kind: tc.infraKind,
+						Name: "abc",
+					},
+				},
+			}
+			m3m := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "m3m",
+					Namespace: "myns",
+					Labels:    tc.labels,
+				},
+			}
+			r := Metal3ClusterReconciler{
+				Client: fake.NewFakeClientWithScheme(setupScheme(), cluster),
+				Log:    klogr.New(),
+			}
+			reqs := r.Metal3MachineToMetal3Cluster(handler.MapObject{
+				Meta:   m3m,
+				Object: m3m,
+			})
+			Expect(reqs).To(Equal(tc.expectedRequests))
+		},
+		Entry("Worker machine", testCaseMetal3MachineToMetal3Cluster{
+			labels:           map[string]string{capi.ClusterLabelName: "cluster1"},
+			infraKind:        "Metal3Cluster",
+			expectedRequests: []ctrl.Request{},
+		}),
+		Entry("Control plane machine", testCaseMetal3MachineToMetal3Cluster{
+			labels: map[string]string{
+				capi.ClusterLabelName:             "cluster1",
+				capi.MachineControlPlaneLabelName: "",
+			},
+			infraKind: "Metal3Cluster",
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "abc",
+						Namespace: "myns",
+					},
+				},
+			},
+		}),
+		Entry("Cluster not found", testCaseMetal3MachineToMetal3Cluster{
+			labels: map[string]string{
+				capi.ClusterLabelName:             "cluster2",
+				capi.MachineControlPlaneLabelName: "",
+			},
+			infraKind:        "Metal3Cluster",
+			expectedRequests: []ctrl.Request{},
+		}),
+		Entry("Other infrastructure", testCaseMetal3MachineToMetal3Cluster{
+			labels: map[string]string{
+				capi.ClusterLabelName:             "cluster1",
+				capi.MachineControlPlaneLabelName: "",
+			},
+			infraKind:        "DockerCluster",
+			expectedRequests: []ctrl.Request{},
+		}),
 	)
 })
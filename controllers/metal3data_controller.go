@@ -30,9 +30,11 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -46,23 +48,27 @@ type Metal3DataReconciler struct {
 	Client         client.Client
 	ManagerFactory baremetal.ManagerFactoryInterface
 	Log            logr.Logger
+	RequeueBackoff *RequeueBackoff
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3datas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3datas/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ipam.metal3.io,resources=ipclaims,verbs=get;list;watch;create;patch;delete
 
 // Reconcile handles Metal3Machine events
 func (r *Metal3DataReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr error) {
 	ctx := context.Background()
 	metadataLog := r.Log.WithName(dataControllerName).WithValues("metal3-data", req.NamespacedName)
+	backoff := r.RequeueBackoff.forObject(req.NamespacedName)
 
 	// Fetch the Metal3Data instance.
 	capm3Metadata := &capm3.Metal3Data{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, capm3Metadata); err != nil {
 		if apierrors.IsNotFound(err) {
+			backoff.reset()
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -73,6 +79,9 @@ func (r *Metal3DataReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr
 	}
 	// Always patch capm3Machine exiting this function so we can persist any Metal3Machine changes.
 	defer func() {
+		conditions.SetSummary(capm3Metadata,
+			conditions.WithConditions(capm3.SecretsReadyCondition),
+		)
 		err := helper.Patch(ctx, capm3Metadata)
 		if err != nil {
 			metadataLog.Info("failed to Patch Metal3Data")
@@ -110,15 +119,15 @@ func (r *Metal3DataReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr
 
 	// Handle deleted metadata
 	if !capm3Metadata.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, metadataMgr)
+		return r.reconcileDelete(ctx, metadataMgr, backoff)
 	}
 
 	// Handle non-deleted machines
-	return r.reconcileNormal(ctx, metadataMgr)
+	return r.reconcileNormal(ctx, metadataMgr, backoff)
 }
 
 func (r *Metal3DataReconciler) reconcileNormal(ctx context.Context,
-	metadataMgr baremetal.DataManagerInterface,
+	metadataMgr baremetal.DataManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 
 	// If the Metal3Data doesn't have finalizer, add it.
@@ -126,28 +135,31 @@ func (r *Metal3DataReconciler) reconcileNormal(ctx context.Context,
 
 	err := metadataMgr.Reconcile(ctx)
 	if err != nil {
-		return checkRequeueError(err, "Failed to create secrets")
+		return checkRequeueError(backoff, err, "Failed to create secrets")
 	}
+	backoff.reset()
 	return ctrl.Result{}, nil
 }
 
 func (r *Metal3DataReconciler) reconcileDelete(ctx context.Context,
-	metadataMgr baremetal.DataManagerInterface,
+	metadataMgr baremetal.DataManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 
 	err := metadataMgr.ReleaseLeases(ctx)
 	if err != nil {
-		return checkRequeueError(err, "Failed to release IP address leases")
+		return checkRequeueError(backoff, err, "Failed to release IP address leases")
 	}
 
 	metadataMgr.UnsetFinalizer()
 
+	backoff.reset()
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager will add watches for this controller
-func (r *Metal3DataReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *Metal3DataReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
 		For(&capm3.Metal3Data{}).
 		Watches(
 			&source.Kind{Type: &ipamv1.IPClaim{}},
@@ -254,7 +254,7 @@ var _ = Describe("Metal3Data manager", func() {
 
 				dataReconcile := &Metal3DataReconciler{
 					Client:         c,
-					ManagerFactory: baremetal.NewManagerFactory(c),
+					ManagerFactory: baremetal.NewManagerFactory(c, nil),
 					Log:            klogr.New(),
 				}
 				m := baremetal_mocks.NewMockDataManagerInterface(gomockCtrl)
@@ -269,7 +269,7 @@ var _ = Describe("Metal3Data manager", func() {
 					m.EXPECT().Reconcile(context.TODO()).Return(nil)
 				}
 
-				res, err := dataReconcile.reconcileNormal(context.TODO(), m)
+				res, err := dataReconcile.reconcileNormal(context.TODO(), m, objectBackoff{})
 				gomockCtrl.Finish()
 
 				if tc.ExpectError {
@@ -315,7 +315,7 @@ var _ = Describe("Metal3Data manager", func() {
 
 			dataReconcile := &Metal3DataReconciler{
 				Client:         c,
-				ManagerFactory: baremetal.NewManagerFactory(c),
+				ManagerFactory: baremetal.NewManagerFactory(c, nil),
 				Log:            klogr.New(),
 			}
 			m := baremetal_mocks.NewMockDataManagerInterface(gomockCtrl)
@@ -329,7 +329,7 @@ var _ = Describe("Metal3Data manager", func() {
 				m.EXPECT().UnsetFinalizer()
 			}
 
-			res, err := dataReconcile.reconcileDelete(context.TODO(), m)
+			res, err := dataReconcile.reconcileDelete(context.TODO(), m, objectBackoff{})
 			gomockCtrl.Finish()
 
 			if tc.ExpectError {
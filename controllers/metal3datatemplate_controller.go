@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -43,6 +44,7 @@ type Metal3DataTemplateReconciler struct {
 	Client         client.Client
 	ManagerFactory baremetal.ManagerFactoryInterface
 	Log            logr.Logger
+	RequeueBackoff *RequeueBackoff
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3datatemplates,verbs=get;list;watch;create;update;patch;delete
@@ -64,12 +66,14 @@ type Metal3DataTemplateReconciler struct {
 func (r *Metal3DataTemplateReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr error) {
 	ctx := context.Background()
 	metadataLog := r.Log.WithName(dataTemplateControllerName).WithValues("metal3-datatemplate", req.NamespacedName)
+	backoff := r.RequeueBackoff.forObject(req.NamespacedName)
 
 	// Fetch the Metal3DataTemplate instance.
 	capm3DataTemplate := &capm3.Metal3DataTemplate{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, capm3DataTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
+			backoff.reset()
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -121,33 +125,34 @@ func (r *Metal3DataTemplateReconciler) Reconcile(req ctrl.Request) (_ ctrl.Resul
 
 	// Handle deleted metadata
 	if !capm3DataTemplate.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, metadataMgr)
+		return r.reconcileDelete(ctx, metadataMgr, backoff)
 	}
 
 	// Handle non-deleted machines
-	return r.reconcileNormal(ctx, metadataMgr)
+	return r.reconcileNormal(ctx, metadataMgr, backoff)
 }
 
 func (r *Metal3DataTemplateReconciler) reconcileNormal(ctx context.Context,
-	metadataMgr baremetal.DataTemplateManagerInterface,
+	metadataMgr baremetal.DataTemplateManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 	// If the Metal3DataTemplate doesn't have finalizer, add it.
 	metadataMgr.SetFinalizer()
 
 	_, err := metadataMgr.UpdateDatas(ctx)
 	if err != nil {
-		return checkRequeueError(err, "Failed to recreate the status")
+		return checkRequeueError(backoff, err, "Failed to recreate the status")
 	}
+	backoff.reset()
 	return ctrl.Result{}, nil
 }
 
 func (r *Metal3DataTemplateReconciler) reconcileDelete(ctx context.Context,
-	metadataMgr baremetal.DataTemplateManagerInterface,
+	metadataMgr baremetal.DataTemplateManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 
 	allocationsNb, err := metadataMgr.UpdateDatas(ctx)
 	if err != nil {
-		return checkRequeueError(err, "Failed to recreate the status")
+		return checkRequeueError(backoff, err, "Failed to recreate the status")
 	}
 
 	if allocationsNb == 0 {
@@ -156,12 +161,14 @@ func (r *Metal3DataTemplateReconciler) reconcileDelete(ctx context.Context,
 		metadataMgr.UnsetFinalizer()
 	}
 
+	backoff.reset()
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager will add watches for this controller
-func (r *Metal3DataTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *Metal3DataTemplateReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
 		For(&capm3.Metal3DataTemplate{}).
 		Watches(
 			&source.Kind{Type: &capm3.Metal3DataClaim{}},
@@ -195,12 +202,14 @@ func (r *Metal3DataTemplateReconciler) Metal3DataClaimToMetal3DataTemplate(obj h
 	return []ctrl.Request{}
 }
 
-func checkRequeueError(err error, errMessage string) (ctrl.Result, error) {
+func checkRequeueError(backoff objectBackoff, err error, errMessage string,
+) (ctrl.Result, error) {
 	if err == nil {
+		backoff.reset()
 		return ctrl.Result{}, nil
 	}
 	if requeueErr, ok := errors.Cause(err).(baremetal.HasRequeueAfterError); ok {
-		return ctrl.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
+		return requeueResult(backoff, requeueErr, errMessage), nil
 	}
 	return ctrl.Result{}, errors.Wrap(err, errMessage)
 }
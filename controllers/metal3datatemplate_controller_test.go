@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -209,9 +210,10 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 	)
 
 	type reconcileNormalTestCase struct {
-		ExpectError   bool
-		ExpectRequeue bool
-		UpdateError   bool
+		ExpectError        bool
+		ExpectRequeue      bool
+		UpdateError        bool
+		UpdateRequeueError bool
 	}
 
 	DescribeTable("ReconcileNormal tests",
@@ -222,20 +224,29 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 
 			dataTemplateReconcile := &Metal3DataTemplateReconciler{
 				Client:         c,
-				ManagerFactory: baremetal.NewManagerFactory(c),
+				ManagerFactory: baremetal.NewManagerFactory(c, nil),
 				Log:            klogr.New(),
 			}
 			m := baremetal_mocks.NewMockDataTemplateManagerInterface(gomockCtrl)
 
 			m.EXPECT().SetFinalizer()
 
-			if !tc.UpdateError {
+			if tc.UpdateRequeueError {
+				m.EXPECT().UpdateDatas(context.TODO()).Return(0,
+					&baremetal.RequeueAfterError{RequeueAfter: requeueAfter},
+				)
+			} else if !tc.UpdateError {
 				m.EXPECT().UpdateDatas(context.TODO()).Return(1, nil)
 			} else {
 				m.EXPECT().UpdateDatas(context.TODO()).Return(0, errors.New(""))
 			}
 
-			res, err := dataTemplateReconcile.reconcileNormal(context.TODO(), m)
+			backoff := NewRequeueBackoff(time.Second, time.Minute).forObject(
+				types.NamespacedName{Name: "abc", Namespace: "myns"},
+			)
+			res, err := dataTemplateReconcile.reconcileNormal(context.TODO(), m,
+				backoff,
+			)
 			gomockCtrl.Finish()
 
 			if tc.ExpectError {
@@ -245,6 +256,7 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 			}
 			if tc.ExpectRequeue {
 				Expect(res.Requeue).To(BeTrue())
+				Expect(res.RequeueAfter).To(Equal(time.Second))
 			} else {
 				Expect(res.Requeue).To(BeFalse())
 			}
@@ -258,6 +270,11 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 			ExpectError:   true,
 			ExpectRequeue: false,
 		}),
+		Entry("Update requeue error", reconcileNormalTestCase{
+			UpdateRequeueError: true,
+			ExpectError:        false,
+			ExpectRequeue:      true,
+		}),
 	)
 
 	type reconcileDeleteTestCase struct {
@@ -275,7 +292,7 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 
 			dataTemplateReconcile := &Metal3DataTemplateReconciler{
 				Client:         c,
-				ManagerFactory: baremetal.NewManagerFactory(c),
+				ManagerFactory: baremetal.NewManagerFactory(c, nil),
 				Log:            klogr.New(),
 			}
 			m := baremetal_mocks.NewMockDataTemplateManagerInterface(gomockCtrl)
@@ -289,7 +306,9 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 				m.EXPECT().UpdateDatas(context.TODO()).Return(0, errors.New(""))
 			}
 
-			res, err := dataTemplateReconcile.reconcileDelete(context.TODO(), m)
+			res, err := dataTemplateReconcile.reconcileDelete(context.TODO(), m,
+				objectBackoff{},
+			)
 			gomockCtrl.Finish()
 
 			if tc.ExpectError {
@@ -391,19 +410,21 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 	)
 
 	It("Test checkRequeueError", func() {
-		result, err := checkRequeueError(nil, "")
+		result, err := checkRequeueError(objectBackoff{}, nil, "")
 		Expect(err).NotTo(HaveOccurred())
 		Expect(result).To(Equal(ctrl.Result{}))
 
-		result, err = checkRequeueError(errors.New("def"), "abc")
+		result, err = checkRequeueError(objectBackoff{}, errors.New("def"), "abc")
 		Expect(err).To(HaveOccurred())
 		Expect(result).To(Equal(ctrl.Result{}))
 
-		result, err = checkRequeueError(&baremetal.RequeueAfterError{}, "abc")
+		result, err = checkRequeueError(objectBackoff{},
+			&baremetal.RequeueAfterError{}, "abc",
+		)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(result).To(Equal(ctrl.Result{Requeue: true}))
 
-		result, err = checkRequeueError(
+		result, err = checkRequeueError(objectBackoff{},
 			&baremetal.RequeueAfterError{RequeueAfter: requeueAfter}, "abc",
 		)
 		Expect(err).NotTo(HaveOccurred())
@@ -24,23 +24,32 @@ import (
 	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal/remote"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	machineControllerName = "Metal3Machine-controller"
+	// nodeEventsBufferSize is the number of Node events buffered for the
+	// controller before the informers of the workload clusters drop them.
+	nodeEventsBufferSize = 100
 )
 
 // Metal3MachineReconciler reconciles a Metal3Machine object
@@ -49,6 +58,12 @@ type Metal3MachineReconciler struct {
 	ManagerFactory   baremetal.ManagerFactoryInterface
 	Log              logr.Logger
 	CapiClientGetter baremetal.ClientGetter
+	RequeueBackoff   *RequeueBackoff
+	ClusterTracker   *remote.ClusterClientTracker
+
+	// nodeEvents receives the Metal3Machines whose Node joined the workload
+	// cluster, changed or was deleted.
+	nodeEvents chan event.GenericEvent
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3machines,verbs=get;list;watch;create;update;patch;delete
@@ -69,12 +84,14 @@ type Metal3MachineReconciler struct {
 func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr error) {
 	ctx := context.Background()
 	machineLog := r.Log.WithName(machineControllerName).WithValues("metal3-machine", req.NamespacedName)
+	backoff := r.RequeueBackoff.forObject(req.NamespacedName)
 
 	// Fetch the Metal3Machine instance.
 	capm3Machine := &capm3.Metal3Machine{}
 
 	if err := r.Client.Get(ctx, req.NamespacedName, capm3Machine); err != nil {
 		if apierrors.IsNotFound(err) {
+			backoff.reset()
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -85,6 +102,17 @@ func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	}
 	// Always patch capm3Machine exiting this function so we can persist any Metal3Machine changes.
 	defer func() {
+		if capm3Machine.Status.FailureReason != nil {
+			capm3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhaseFailed)
+		}
+		conditions.SetSummary(capm3Machine,
+			conditions.WithConditions(
+				capm3.AssociateBMHCondition,
+				capm3.Metal3DataReadyCondition,
+				capm3.HostProvisionedCondition,
+				capm3.KubernetesNodeReadyCondition,
+			),
+		)
 		err := helper.Patch(ctx, capm3Machine)
 		if err != nil {
 			machineLog.Info("failed to Patch capm3Machine")
@@ -93,6 +121,10 @@ func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	//clear an error if one was previously set
 	clearErrorM3Machine(capm3Machine)
 
+	if capm3Machine.Status.Phase == "" {
+		capm3Machine.Status.SetTypedPhase(capm3.Metal3MachinePhasePending)
+	}
+
 	// Fetch the Machine.
 	capiMachine, err := util.GetOwnerMachine(ctx, r.Client, capm3Machine.ObjectMeta)
 
@@ -131,6 +163,9 @@ func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	// Make sure infrastructure is ready
 	if !cluster.Status.InfrastructureReady {
 		machineLog.Info("Waiting for Metal3Cluster Controller to create cluster infrastructure")
+		conditions.MarkFalse(capm3Machine, capm3.AssociateBMHCondition,
+			capm3.WaitingForClusterInfrastructureReason, capi.ConditionSeverityInfo, "",
+		)
 		return ctrl.Result{}, nil
 	}
 
@@ -147,6 +182,20 @@ func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 
 	machineLog = machineLog.WithValues("metal3-cluster", metal3Cluster.Name)
 
+	// Watch the Nodes of the workload cluster, to be notified as soon as the
+	// Node joins the cluster, drifts or is deleted.
+	if watchesNodes(metal3Cluster, capm3Machine) &&
+		cluster.Status.ControlPlaneInitialized && r.ClusterTracker != nil &&
+		r.nodeEvents != nil && capm3Machine.ObjectMeta.DeletionTimestamp.IsZero() {
+		if err := r.ClusterTracker.WatchNodes(ctx, r.Client, cluster,
+			r.NodeToMetal3Machine,
+		); err != nil {
+			machineLog.Info("failed to watch the Nodes of the workload cluster",
+				"error", err.Error(),
+			)
+		}
+	}
+
 	// Create a helper for managing the baremetal container hosting the machine.
 	machineMgr, err := r.ManagerFactory.NewMachineManager(cluster, metal3Cluster, capiMachine, capm3Machine, machineLog)
 	if err != nil {
@@ -177,15 +226,29 @@ func (r *Metal3MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 
 	// Handle deleted machines
 	if !capm3Machine.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, machineMgr)
+		return r.reconcileDelete(ctx, machineMgr, backoff)
 	}
 
 	// Handle non-deleted machines
-	return r.reconcileNormal(ctx, machineMgr)
+	return r.reconcileNormal(ctx, machineMgr, backoff)
+}
+
+// watchesNodes returns true if the Nodes of the workload cluster are watched
+// for the Metal3Machine, that is when CAPM3 sets the providerID of the Nodes,
+// or syncs labels, annotations or taints on them.
+func watchesNodes(metal3Cluster *capm3.Metal3Cluster,
+	capm3Machine *capm3.Metal3Machine,
+) bool {
+	return metal3Cluster.Spec.NoCloudProvider ||
+		metal3Cluster.Spec.SetProviderIDOnNodes ||
+		len(metal3Cluster.Spec.NodeLabelPrefixes) > 0 ||
+		len(metal3Cluster.Spec.NodeAnnotationPrefixes) > 0 ||
+		len(capm3Machine.Spec.NodeLabels) > 0 ||
+		len(capm3Machine.Spec.NodeTaints) > 0
 }
 
 func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
-	machineMgr baremetal.MachineManagerInterface,
+	machineMgr baremetal.MachineManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 	// If the Metal3Machine doesn't have finalizer, add it.
 	machineMgr.SetFinalizer()
@@ -193,8 +256,21 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 	// if the machine is already provisioned, update and return
 	if machineMgr.IsProvisioned() {
 		errType := capierrors.UpdateMachineError
-		return checkMachineError(machineMgr, machineMgr.Update(ctx),
-			"Failed to update the Metal3Machine", errType,
+		if err := machineMgr.Update(ctx); err != nil {
+			return checkMachineError(machineMgr, backoff, err,
+				"Failed to update the Metal3Machine", errType,
+			)
+		}
+		// Check that the node was not deleted
+		if err := machineMgr.CheckNode(ctx, r.CapiClientGetter); err != nil {
+			return checkMachineError(machineMgr, backoff, err,
+				"failed to check the target node", errType,
+			)
+		}
+		// Keep the labels and taints of the node in sync
+		return checkMachineError(machineMgr, backoff,
+			machineMgr.SyncNode(ctx, r.CapiClientGetter),
+			"failed to sync the target node", errType,
 		)
 	}
 
@@ -211,7 +287,7 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 		//Associate the baremetalhost hosting the machine
 		err := machineMgr.Associate(ctx)
 		if err != nil {
-			return checkMachineError(machineMgr, err,
+			return checkMachineError(machineMgr, backoff, err,
 				"failed to associate the Metal3Machine to a BaremetalHost", errType,
 			)
 		}
@@ -220,14 +296,14 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 	// Make sure that the metadata is ready if any
 	err := machineMgr.AssociateM3Metadata(ctx)
 	if err != nil {
-		return checkMachineError(machineMgr, err,
+		return checkMachineError(machineMgr, backoff, err,
 			"Failed to get the Metal3Metadata", errType,
 		)
 	}
 
 	err = machineMgr.Update(ctx)
 	if err != nil {
-		return checkMachineError(machineMgr, err,
+		return checkMachineError(machineMgr, backoff, err,
 			"failed to update BaremetalHost", errType,
 		)
 	}
@@ -236,44 +312,52 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 	if bmhID == nil {
 		bmhID, err = machineMgr.GetBaremetalHostID(ctx)
 		if err != nil {
-			return checkMachineError(machineMgr, err,
+			return checkMachineError(machineMgr, backoff, err,
 				"failed to get the providerID for the metal3machine", errType,
 			)
 		}
 		if bmhID != nil {
-			providerID = fmt.Sprintf("metal3://%s", *bmhID)
+			providerID = machineMgr.BuildProviderID(*bmhID)
 		}
 	}
 	if bmhID != nil {
 		// Set the providerID on the node if no Cloud provider
 		err = machineMgr.SetNodeProviderID(ctx, *bmhID, providerID, r.CapiClientGetter)
 		if err != nil {
-			return checkMachineError(machineMgr, err,
+			return checkMachineError(machineMgr, backoff, err,
 				"failed to set the target node providerID", errType,
 			)
 		}
 		// Make sure Spec.ProviderID is set and mark the capm3Machine ready
 		machineMgr.SetProviderID(providerID)
+		// The node is found by the providerID of the Metal3Machine
+		err = machineMgr.SyncNode(ctx, r.CapiClientGetter)
+		if err != nil {
+			return checkMachineError(machineMgr, backoff, err,
+				"failed to sync the target node", errType,
+			)
+		}
 	}
 
+	backoff.reset()
 	return ctrl.Result{}, err
 }
 
 func (r *Metal3MachineReconciler) reconcileDelete(ctx context.Context,
-	machineMgr baremetal.MachineManagerInterface,
+	machineMgr baremetal.MachineManagerInterface, backoff objectBackoff,
 ) (ctrl.Result, error) {
 
 	errType := capierrors.DeleteMachineError
 
 	// delete the machine
 	if err := machineMgr.Delete(ctx); err != nil {
-		return checkMachineError(machineMgr, err,
+		return checkMachineError(machineMgr, backoff, err,
 			"failed to delete Metal3Machine", errType,
 		)
 	}
 
 	if err := machineMgr.DissociateM3Metadata(ctx); err != nil {
-		return checkMachineError(machineMgr, err,
+		return checkMachineError(machineMgr, backoff, err,
 			"failed to dissociate Metadata", errType,
 		)
 	}
@@ -282,12 +366,15 @@ func (r *Metal3MachineReconciler) reconcileDelete(ctx context.Context,
 	// so remove the finalizer.
 	machineMgr.UnsetFinalizer()
 
+	backoff.reset()
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager will add watches for this controller
-func (r *Metal3MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *Metal3MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	r.nodeEvents = make(chan event.GenericEvent, nodeEventsBufferSize)
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
 		For(&capm3.Metal3Machine{}).
 		Watches(
 			&source.Kind{Type: &capi.Machine{}},
@@ -325,9 +412,76 @@ func (r *Metal3MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				ToRequests: handler.ToRequestsFunc(r.BareMetalHostToMetal3Machines),
 			},
 		).
+		Watches(
+			&source.Channel{Source: r.nodeEvents},
+			&handler.EnqueueRequestForObject{},
+		).
 		Complete(r)
 }
 
+// NodeToMetal3Machine is called by the watch on the Nodes of a workload
+// cluster. It enqueues the Metal3Machine consuming the BareMetalHost whose UID
+// is in the metal3.io/uuid label of a Node without providerID, of a Node with
+// labels, annotations or taints synced from the Metal3Machine, or of a deleted
+// Node.
+func (r *Metal3MachineReconciler) NodeToMetal3Machine(cluster types.NamespacedName,
+	node *corev1.Node, deleted bool,
+) {
+	if node.Spec.ProviderID != "" && !deleted &&
+		!baremetal.NodeHasSyncedValues(node) {
+		return
+	}
+	uid, ok := node.Labels[remote.NodeUUIDLabel]
+	if !ok {
+		return
+	}
+	log := r.Log.WithValues("cluster", cluster, "node", node.Name)
+
+	hosts := &bmh.BareMetalHostList{}
+	if err := r.Client.List(context.TODO(), hosts,
+		client.InNamespace(cluster.Namespace),
+	); err != nil {
+		log.Error(err, "failed to list BareMetalHosts")
+		return
+	}
+	for _, host := range hosts.Items {
+		if string(host.UID) != uid {
+			continue
+		}
+		ref := host.Spec.ConsumerRef
+		if ref == nil || ref.Kind != "Metal3Machine" ||
+			ref.GroupVersionKind().Group != capm3.GroupVersion.Group {
+			return
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = host.Namespace
+		}
+		m3m := &capm3.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: namespace},
+		}
+		r.enqueueNodeEvent(event.GenericEvent{Meta: m3m, Object: m3m})
+		return
+	}
+}
+
+// enqueueNodeEvent sends the event to the controller without blocking the
+// informer of the workload cluster. If the buffer is full, the event is
+// dropped: the Metal3Machine is reconciled again on its next requeue or
+// resync.
+func (r *Metal3MachineReconciler) enqueueNodeEvent(evt event.GenericEvent) {
+	select {
+	case r.nodeEvents <- evt:
+	default:
+		r.Log.V(1).Info("Dropping the Node event, the buffer is full",
+			"metal3-machine", types.NamespacedName{
+				Name:      evt.Meta.GetName(),
+				Namespace: evt.Meta.GetNamespace(),
+			},
+		)
+	}
+}
+
 // ClusterToMetal3Machines is a handler.ToRequestsFunc to be used to enqeue
 // requests for reconciliation of Metal3Machines.
 func (r *Metal3MachineReconciler) ClusterToMetal3Machines(o handler.MapObject) []ctrl.Request {
@@ -513,14 +667,16 @@ func clearErrorM3Machine(m3m *capm3.Metal3Machine) {
 
 }
 
-func checkMachineError(machineMgr baremetal.MachineManagerInterface, err error,
-	errMessage string, errType capierrors.MachineStatusError,
+func checkMachineError(machineMgr baremetal.MachineManagerInterface,
+	backoff objectBackoff, err error, errMessage string,
+	errType capierrors.MachineStatusError,
 ) (ctrl.Result, error) {
 	if err == nil {
+		backoff.reset()
 		return ctrl.Result{}, nil
 	}
 	if requeueErr, ok := errors.Cause(err).(baremetal.HasRequeueAfterError); ok {
-		return ctrl.Result{Requeue: true, RequeueAfter: requeueErr.GetRequeueAfter()}, nil
+		return requeueResult(backoff, requeueErr, errMessage), nil
 	}
 	machineMgr.SetError(errMessage, errType)
 	return ctrl.Result{}, errors.Wrap(err, errMessage)
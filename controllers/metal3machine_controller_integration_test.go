@@ -189,7 +189,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 
 			r := &Metal3MachineReconciler{
 				Client:           c,
-				ManagerFactory:   baremetal.NewManagerFactory(c),
+				ManagerFactory:   baremetal.NewManagerFactory(c, nil),
 				Log:              klogr.New(),
 				CapiClientGetter: mockCapiClientGetter,
 			}
@@ -38,6 +38,7 @@ import (
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
@@ -52,6 +53,7 @@ type reconcileNormalTestCase struct {
 	GetBMHIDFails          bool
 	BMHIDSet               bool
 	SetNodeProviderIDFails bool
+	SyncNodeRequeues       bool
 }
 
 func setReconcileNormalExpectations(ctrl *gomock.Controller,
@@ -66,6 +68,14 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 	m.EXPECT().IsProvisioned().Return(tc.Provisioned)
 	if tc.Provisioned {
 		m.EXPECT().Update(context.TODO()).Return(nil)
+		m.EXPECT().CheckNode(context.TODO(), nil).Return(nil)
+		if tc.SyncNodeRequeues {
+			m.EXPECT().SyncNode(context.TODO(), nil).Return(
+				&baremetal.RequeueAfterError{RequeueAfter: requeueAfter},
+			)
+		} else {
+			m.EXPECT().SyncNode(context.TODO(), nil).Return(nil)
+		}
 		m.EXPECT().IsBootstrapReady().MaxTimes(0)
 		m.EXPECT().AssociateM3Metadata(context.TODO()).MaxTimes(0)
 		m.EXPECT().HasAnnotation().MaxTimes(0)
@@ -123,6 +133,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 			m.EXPECT().GetBaremetalHostID(context.TODO()).Return(
 				pointer.StringPtr("abc"), nil,
 			)
+			m.EXPECT().BuildProviderID("abc").Return("metal3://abc")
 		} else {
 			m.EXPECT().GetProviderIDAndBMHID().Return(
 				"metal3://abc", pointer.StringPtr("abc"),
@@ -144,6 +155,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		m.EXPECT().
 			SetNodeProviderID(context.TODO(), "abc", "metal3://abc", nil).
 			Return(nil)
+		m.EXPECT().SyncNode(context.TODO(), nil).Return(nil)
 		m.EXPECT().SetProviderID("metal3://abc")
 
 		// We did not get an id (got nil), so we'll requeue and not go further
@@ -204,7 +216,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 			bmReconcile = &Metal3MachineReconciler{
 				Client:           c,
-				ManagerFactory:   baremetal.NewManagerFactory(c),
+				ManagerFactory:   baremetal.NewManagerFactory(c, nil),
 				Log:              klogr.New(),
 				CapiClientGetter: nil,
 			}
@@ -217,7 +229,7 @@ var _ = Describe("Metal3Machine manager", func() {
 		DescribeTable("ReconcileNormal tests",
 			func(tc reconcileNormalTestCase) {
 				m := setReconcileNormalExpectations(gomockCtrl, tc)
-				res, err := bmReconcile.reconcileNormal(context.TODO(), m)
+				res, err := bmReconcile.reconcileNormal(context.TODO(), m, objectBackoff{})
 
 				if tc.ExpectError {
 					Expect(err).To(HaveOccurred())
@@ -235,6 +247,12 @@ var _ = Describe("Metal3Machine manager", func() {
 				ExpectRequeue: false,
 				Provisioned:   true,
 			}),
+			Entry("Provisioned, SyncNode requeues", reconcileNormalTestCase{
+				ExpectError:      false,
+				ExpectRequeue:    true,
+				Provisioned:      true,
+				SyncNodeRequeues: true,
+			}),
 			Entry("Bootstrap not ready", reconcileNormalTestCase{
 				ExpectError:       false,
 				ExpectRequeue:     false,
@@ -293,7 +311,7 @@ var _ = Describe("Metal3Machine manager", func() {
 
 			bmReconcile = &Metal3MachineReconciler{
 				Client:           c,
-				ManagerFactory:   baremetal.NewManagerFactory(c),
+				ManagerFactory:   baremetal.NewManagerFactory(c, nil),
 				Log:              klogr.New(),
 				CapiClientGetter: nil,
 			}
@@ -306,7 +324,7 @@ var _ = Describe("Metal3Machine manager", func() {
 		DescribeTable("Deletion tests",
 			func(tc reconcileDeleteTestCase) {
 				m := setReconcileDeleteExpectations(gomockCtrl, tc)
-				res, err := bmReconcile.reconcileDelete(context.TODO(), m)
+				res, err := bmReconcile.reconcileDelete(context.TODO(), m, objectBackoff{})
 
 				if tc.ExpectError {
 					Expect(err).To(HaveOccurred())
@@ -678,4 +696,175 @@ var _ = Describe("Metal3Machine manager", func() {
 			},
 		}),
 	)
+
+	DescribeTable("test watchesNodes",
+		func(clusterSpec infrav1.Metal3ClusterSpec,
+			machineSpec infrav1.Metal3MachineSpec, expected bool,
+		) {
+			Expect(watchesNodes(&infrav1.Metal3Cluster{Spec: clusterSpec},
+				&infrav1.Metal3Machine{Spec: machineSpec},
+			)).To(Equal(expected))
+		},
+		Entry("Cloud provider", infrav1.Metal3ClusterSpec{},
+			infrav1.Metal3MachineSpec{}, false,
+		),
+		Entry("No cloud provider", infrav1.Metal3ClusterSpec{
+			NoCloudProvider: true,
+		}, infrav1.Metal3MachineSpec{}, true),
+		Entry("providerID set on the Nodes", infrav1.Metal3ClusterSpec{
+			SetProviderIDOnNodes: true,
+		}, infrav1.Metal3MachineSpec{}, true),
+		Entry("Label prefixes", infrav1.Metal3ClusterSpec{
+			NodeLabelPrefixes: []string{"topology.metal3.io/"},
+		}, infrav1.Metal3MachineSpec{}, true),
+		Entry("Node taints", infrav1.Metal3ClusterSpec{},
+			infrav1.Metal3MachineSpec{
+				NodeTaints: []corev1.Taint{
+					{Key: "node-class", Effect: corev1.TaintEffectNoSchedule},
+				},
+			}, true,
+		),
+	)
+
+	It("Drops the Node events when the buffer is full", func() {
+		r := Metal3MachineReconciler{
+			Log:        klogr.New(),
+			nodeEvents: make(chan event.GenericEvent, 1),
+		}
+		for _, name := range []string{"m3m", "other"} {
+			m3m := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "myns"},
+			}
+			r.enqueueNodeEvent(event.GenericEvent{Meta: m3m, Object: m3m})
+		}
+		Expect(r.nodeEvents).To(HaveLen(1))
+		evt := <-r.nodeEvents
+		Expect(evt.Meta.GetName()).To(Equal("m3m"))
+		Consistently(r.nodeEvents).ShouldNot(Receive())
+	})
+
+	type testCaseNodeToMetal3Machine struct {
+		node            *corev1.Node
+		deleted         bool
+		consumerRef     *corev1.ObjectReference
+		expectedMachine string
+	}
+
+	DescribeTable("test NodeToMetal3Machine",
+		func(tc testCaseNodeToMetal3Machine) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "host1",
+					Namespace: "myns",
+					UID:       "abc-uid",
+				},
+				Spec: bmh.BareMetalHostSpec{ConsumerRef: tc.consumerRef},
+			}
+			c := fake.NewFakeClientWithScheme(setupScheme(), host)
+			r := Metal3MachineReconciler{
+				Client:     c,
+				Log:        klogr.New(),
+				nodeEvents: make(chan event.GenericEvent, 1),
+			}
+			r.NodeToMetal3Machine(types.NamespacedName{Name: "abc", Namespace: "myns"},
+				tc.node, tc.deleted,
+			)
+			if tc.expectedMachine == "" {
+				Expect(r.nodeEvents).To(BeEmpty())
+				return
+			}
+			Expect(r.nodeEvents).To(HaveLen(1))
+			evt := <-r.nodeEvents
+			Expect(evt.Meta.GetName()).To(Equal(tc.expectedMachine))
+			Expect(evt.Meta.GetNamespace()).To(Equal("myns"))
+		},
+		Entry("Node of a consumed host", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abc-uid"},
+				},
+			},
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			},
+			expectedMachine: "m3m",
+		}),
+		Entry("Node with a providerID", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abc-uid"},
+				},
+				Spec: corev1.NodeSpec{ProviderID: "metal3://abc-uid"},
+			},
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			},
+		}),
+		Entry("Node with a providerID and synced labels", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abc-uid"},
+					Annotations: map[string]string{
+						"metal3.io/synced-labels": "node-class",
+					},
+				},
+				Spec: corev1.NodeSpec{ProviderID: "metal3://abc-uid"},
+			},
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			},
+			expectedMachine: "m3m",
+		}),
+		Entry("Deleted node with a providerID", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abc-uid"},
+				},
+				Spec: corev1.NodeSpec{ProviderID: "metal3://abc-uid"},
+			},
+			deleted: true,
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			},
+			expectedMachine: "m3m",
+		}),
+		Entry("Node of an unknown host", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "def-uid"},
+				},
+			},
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			},
+		}),
+		Entry("Node of a host consumed by another kind", testCaseNodeToMetal3Machine{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1",
+					Labels: map[string]string{"metal3.io/uuid": "abc-uid"},
+				},
+			},
+			consumerRef: &corev1.ObjectReference{
+				Name:       "m3m",
+				Namespace:  "myns",
+				Kind:       "Machine",
+				APIVersion: "foo.bar/v1",
+			},
+		}),
+	)
 })
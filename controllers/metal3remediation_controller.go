@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	capm3 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+const (
+	remediationControllerName = "Metal3Remediation-controller"
+)
+
+// Metal3RemediationReconciler reconciles a Metal3Remediation object
+type Metal3RemediationReconciler struct {
+	Client         client.Client
+	ManagerFactory baremetal.ManagerFactoryInterface
+	Log            logr.Logger
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediationtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update;patch
+
+// Reconcile handles Metal3Remediation events
+func (r *Metal3RemediationReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rerr error) {
+	ctx := context.Background()
+	remediationLog := r.Log.WithName(remediationControllerName).WithValues("metal3-remediation", req.NamespacedName)
+
+	// Fetch the Metal3Remediation instance.
+	remediation := &capm3.Metal3Remediation{}
+
+	if err := r.Client.Get(ctx, req.NamespacedName, remediation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	helper, err := patch.NewHelper(remediation, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+	// Always patch the Metal3Remediation exiting this function so we can
+	// persist any status changes.
+	defer func() {
+		err := helper.Patch(ctx, remediation)
+		if err != nil {
+			remediationLog.Info("failed to Patch Metal3Remediation")
+		}
+	}()
+
+	// Nothing to clean up on deletion.
+	if !remediation.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Fetch the Machine, set as owner by the MachineHealthCheck.
+	capiMachine, err := util.GetOwnerMachine(ctx, r.Client, remediation.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get the owner Machine")
+	}
+	if capiMachine == nil {
+		remediationLog.Info("Waiting for Machine Controller to set OwnerRef on Metal3Remediation")
+		return ctrl.Result{}, nil
+	}
+	remediationLog = remediationLog.WithValues("machine", capiMachine.Name)
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, capiMachine.ObjectMeta)
+	if err != nil {
+		remediationLog.Info("Machine is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, nil
+	}
+	if cluster == nil {
+		remediationLog.Info(fmt.Sprintf("This machine is not yet associated with a cluster using the label %s: <name of cluster>", capi.ClusterLabelName))
+		return ctrl.Result{}, nil
+	}
+	remediationLog = remediationLog.WithValues("cluster", cluster.Name)
+
+	// Return early if the Remediation or Cluster is paused.
+	if util.IsPaused(cluster, remediation) {
+		remediationLog.Info("reconciliation is paused for this object")
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
+	}
+
+	// Fetch the Metal3Machine of the Machine.
+	capm3Machine := &capm3.Metal3Machine{}
+	key := client.ObjectKey{
+		Name:      capiMachine.Spec.InfrastructureRef.Name,
+		Namespace: capiMachine.Namespace,
+	}
+	if err := r.Client.Get(ctx, key, capm3Machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			remediationLog.Info("Metal3Machine of the Machine not found")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Create a helper for managing the remediation.
+	remediationMgr, err := r.ManagerFactory.NewRemediationManager(remediation,
+		capm3Machine, capiMachine, remediationLog,
+	)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the Metal3Remediation")
+	}
+
+	return r.reconcileNormal(ctx, remediationMgr)
+}
+
+func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface,
+) (ctrl.Result, error) {
+	err := remediationMgr.Reconcile(ctx)
+	if err != nil {
+		return checkRequeueError(objectBackoff{}, err, "Failed to remediate the Machine")
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for this controller
+func (r *Metal3RemediationReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		For(&capm3.Metal3Remediation{}).
+		Complete(r)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1alpha4"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	baremetal_mocks "github.com/metal3-io/cluster-api-provider-metal3/baremetal/mocks"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/klogr"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Metal3Remediation controller", func() {
+
+	remediationMeta := metav1.ObjectMeta{
+		Name:      "abc",
+		Namespace: "myns",
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: capi.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       "abc",
+			},
+		},
+	}
+	machineWithLabel := &capi.Machine{
+		ObjectMeta: testObjectMetaWithLabel,
+		Spec: capi.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{
+				Name: "abc",
+			},
+		},
+	}
+
+	type testCaseReconcile struct {
+		expectError      bool
+		expectRequeue    bool
+		expectManager    bool
+		remediation      *infrav1.Metal3Remediation
+		machine          *capi.Machine
+		m3m              *infrav1.Metal3Machine
+		cluster          *capi.Cluster
+		managerError     bool
+		reconcileError   bool
+		reconcileRequeue bool
+	}
+
+	DescribeTable("Test Reconcile",
+		func(tc testCaseReconcile) {
+			gomockCtrl := gomock.NewController(GinkgoT())
+			f := baremetal_mocks.NewMockManagerFactoryInterface(gomockCtrl)
+			m := baremetal_mocks.NewMockRemediationManagerInterface(gomockCtrl)
+
+			objects := []runtime.Object{}
+			if tc.remediation != nil {
+				objects = append(objects, tc.remediation)
+			}
+			if tc.machine != nil {
+				objects = append(objects, tc.machine)
+			}
+			if tc.m3m != nil {
+				objects = append(objects, tc.m3m)
+			}
+			if tc.cluster != nil {
+				objects = append(objects, tc.cluster)
+			}
+			c := fake.NewFakeClientWithScheme(setupScheme(), objects...)
+
+			if tc.managerError {
+				f.EXPECT().NewRemediationManager(gomock.Any(), gomock.Any(),
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New(""))
+			} else if tc.expectManager {
+				f.EXPECT().NewRemediationManager(gomock.Any(), gomock.Any(),
+					gomock.Any(), gomock.Any(),
+				).Return(m, nil)
+				if tc.reconcileError {
+					m.EXPECT().Reconcile(gomock.Any()).Return(errors.New(""))
+				} else if tc.reconcileRequeue {
+					m.EXPECT().Reconcile(gomock.Any()).Return(&baremetal.RequeueAfterError{})
+				} else {
+					m.EXPECT().Reconcile(gomock.Any()).Return(nil)
+				}
+			} else {
+				f.EXPECT().NewRemediationManager(gomock.Any(), gomock.Any(),
+					gomock.Any(), gomock.Any(),
+				).MaxTimes(0)
+			}
+
+			remediationReconcile := &Metal3RemediationReconciler{
+				Client:         c,
+				ManagerFactory: f,
+				Log:            klogr.New(),
+			}
+
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "abc",
+					Namespace: "myns",
+				},
+			}
+
+			result, err := remediationReconcile.Reconcile(req)
+
+			if tc.expectError || tc.managerError || tc.reconcileError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			if tc.expectRequeue {
+				Expect(result.Requeue).To(BeTrue())
+			} else {
+				Expect(result.Requeue).To(BeFalse())
+			}
+			gomockCtrl.Finish()
+		},
+		Entry("Metal3Remediation not found", testCaseReconcile{}),
+		Entry("Deletion", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "abc",
+					Namespace:         "myns",
+					DeletionTimestamp: &timestampNow,
+				},
+			},
+		}),
+		Entry("Missing owner Machine", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: testObjectMeta,
+			},
+		}),
+		Entry("Machine missing cluster label", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: &capi.Machine{
+				ObjectMeta: testObjectMeta,
+			},
+		}),
+		Entry("Paused cluster", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+				Spec: capi.ClusterSpec{
+					Paused: true,
+				},
+			},
+			expectRequeue: true,
+		}),
+		Entry("Metal3Machine not found", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+			},
+		}),
+		Entry("Error in manager", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta,
+			},
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+			},
+			managerError: true,
+		}),
+		Entry("Reconcile error", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta,
+			},
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+			},
+			expectManager:  true,
+			reconcileError: true,
+		}),
+		Entry("Reconcile requeue", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta,
+			},
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+			},
+			expectManager:    true,
+			reconcileRequeue: true,
+			expectRequeue:    true,
+		}),
+		Entry("Reconcile no error", testCaseReconcile{
+			remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: remediationMeta,
+			},
+			machine: machineWithLabel,
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta,
+			},
+			cluster: &capi.Cluster{
+				ObjectMeta: testObjectMeta,
+			},
+			expectManager: true,
+		}),
+	)
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// RequeueBackoff computes the delay before requeueing an object after a
+// RequeueAfterError. The delay starts at a minimum and doubles for each
+// consecutive requeue of the object, up to a maximum. It is reset once the
+// object is reconciled successfully.
+type RequeueBackoff struct {
+	limiter workqueue.RateLimiter
+}
+
+// NewRequeueBackoff returns a new RequeueBackoff.
+func NewRequeueBackoff(minDelay, maxDelay time.Duration) *RequeueBackoff {
+	return &RequeueBackoff{
+		limiter: workqueue.NewItemExponentialFailureRateLimiter(minDelay,
+			maxDelay,
+		),
+	}
+}
+
+// forObject returns the backoff of an object. The RequeueBackoff may be nil,
+// in which case the delays of the RequeueAfterErrors are used unchanged.
+func (b *RequeueBackoff) forObject(key types.NamespacedName) objectBackoff {
+	return objectBackoff{backoff: b, key: key}
+}
+
+// objectBackoff is the backoff of a single object.
+type objectBackoff struct {
+	backoff *RequeueBackoff
+	key     types.NamespacedName
+}
+
+// requeueAfter returns the delay before requeueing the object, given the
+// delay requested by the RequeueAfterError. Immediate requeues are kept. A
+// delay longer than the default requeueAfter is deliberate, for example the
+// rest of a grace period or the period of a health check, so the object is
+// not requeued before it.
+func (o objectBackoff) requeueAfter(requested time.Duration) time.Duration {
+	if o.backoff == nil || requested == 0 {
+		return requested
+	}
+	delay := o.backoff.limiter.When(o.key)
+	if requested > requeueAfter && requested > delay {
+		return requested
+	}
+	return delay
+}
+
+// reset forgets the previous requeues of the object.
+func (o objectBackoff) reset() {
+	if o.backoff == nil {
+		return
+	}
+	o.backoff.limiter.Forget(o.key)
+}
+
+// requeueResult returns the result requeueing the object after a
+// RequeueAfterError, and counts the requeue by reason, defaulting to the
+// error message of the caller.
+func requeueResult(backoff objectBackoff,
+	requeueErr baremetal.HasRequeueAfterError, errMessage string,
+) ctrl.Result {
+	reason := requeueErr.GetReason()
+	if reason == "" {
+		reason = errMessage
+	}
+	baremetal.RequeueTotal.WithLabelValues(reason).Inc()
+	return ctrl.Result{Requeue: true,
+		RequeueAfter: backoff.requeueAfter(requeueErr.GetRequeueAfter()),
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Requeue backoff", func() {
+	key := types.NamespacedName{Name: "abc", Namespace: "myns"}
+	otherKey := types.NamespacedName{Name: "def", Namespace: "myns"}
+
+	It("Doubles the delay of an object up to the maximum", func() {
+		backoff := NewRequeueBackoff(time.Second, 4*time.Second)
+		objBackoff := backoff.forObject(key)
+
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(time.Second))
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(2 * time.Second))
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(4 * time.Second))
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(4 * time.Second))
+		Expect(backoff.forObject(otherKey).requeueAfter(requeueAfter)).To(
+			Equal(time.Second),
+		)
+
+		objBackoff.reset()
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(time.Second))
+	})
+
+	It("Keeps immediate requeues", func() {
+		backoff := NewRequeueBackoff(time.Second, 4*time.Second)
+		Expect(backoff.forObject(key).requeueAfter(0)).To(BeZero())
+	})
+
+	It("Keeps the requested delays longer than the default", func() {
+		backoff := NewRequeueBackoff(time.Second, 4*time.Minute)
+		objBackoff := backoff.forObject(key)
+
+		Expect(objBackoff.requeueAfter(2 * time.Minute)).To(Equal(2 * time.Minute))
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(2 * time.Second))
+		for i := 0; i < 6; i++ {
+			objBackoff.requeueAfter(requeueAfter)
+		}
+		Expect(objBackoff.requeueAfter(2 * time.Minute)).To(Equal(4 * time.Minute))
+	})
+
+	It("Keeps the requested delay without backoff", func() {
+		var backoff *RequeueBackoff
+		objBackoff := backoff.forObject(key)
+		Expect(objBackoff.requeueAfter(requeueAfter)).To(Equal(requeueAfter))
+		objBackoff.reset()
+	})
+
+	It("Applies and resets the backoff in checkRequeueError", func() {
+		objBackoff := NewRequeueBackoff(time.Second, 4*time.Second).forObject(key)
+		requeueErr := &baremetal.RequeueAfterError{RequeueAfter: requeueAfter,
+			Reason: "waiting",
+		}
+
+		result, err := checkRequeueError(objBackoff, requeueErr, "abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{Requeue: true, RequeueAfter: time.Second}))
+		result, err = checkRequeueError(objBackoff, requeueErr, "abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{Requeue: true,
+			RequeueAfter: 2 * time.Second,
+		}))
+
+		result, err = checkRequeueError(objBackoff, nil, "abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		result, err = checkRequeueError(objBackoff, requeueErr, "abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{Requeue: true, RequeueAfter: time.Second}))
+	})
+})
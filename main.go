@@ -30,30 +30,45 @@ import (
 	capm3remote "github.com/metal3-io/cluster-api-provider-metal3/baremetal/remote"
 	"github.com/metal3-io/cluster-api-provider-metal3/controllers"
 	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	// +kubebuilder:scaffold:imports
 )
 
 var (
-	myscheme                = runtime.NewScheme()
-	setupLog                = ctrl.Log.WithName("setup")
-	waitForMetal3Controller = false
-	metricsAddr             string
-	enableLeaderElection    bool
-	syncPeriod              time.Duration
-	webhookPort             int
-	healthAddr              string
-	watchNamespace          string
+	myscheme                      = runtime.NewScheme()
+	setupLog                      = ctrl.Log.WithName("setup")
+	waitForMetal3Controller       = false
+	metricsAddr                   string
+	enableLeaderElection          bool
+	syncPeriod                    time.Duration
+	webhookPort                   int
+	healthAddr                    string
+	watchNamespace                string
+	requeueMinDelay               time.Duration
+	requeueMaxDelay               time.Duration
+	metal3MachineConcurrency      int
+	metal3ClusterConcurrency      int
+	metal3DataConcurrency         int
+	metal3DataTemplateConcurrency int
+	rateLimiterBaseDelay          time.Duration
+	rateLimiterMaxDelay           time.Duration
+	rateLimiterQPS                float64
+	rateLimiterBurst              int
+	clusterHealthCheckInterval    time.Duration
 )
 
 func init() {
@@ -80,6 +95,28 @@ func main() {
 		"Webhook Server port (set to 0 to disable)")
 	flag.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
+	flag.DurationVar(&requeueMinDelay, "requeue-min-delay", 5*time.Second,
+		"The initial delay before requeueing a Metal3Machine or a Metal3Data that waits for another object. The delay doubles for each consecutive requeue.")
+	flag.DurationVar(&requeueMaxDelay, "requeue-max-delay", 5*time.Minute,
+		"The maximum delay before requeueing a Metal3Machine or a Metal3Data that waits for another object.")
+	flag.IntVar(&metal3MachineConcurrency, "metal3machine-concurrency", 1,
+		"Number of Metal3Machines to process simultaneously")
+	flag.IntVar(&metal3ClusterConcurrency, "metal3cluster-concurrency", 1,
+		"Number of Metal3Clusters to process simultaneously")
+	flag.IntVar(&metal3DataConcurrency, "metal3data-concurrency", 1,
+		"Number of Metal3Datas to process simultaneously")
+	flag.IntVar(&metal3DataTemplateConcurrency, "metal3datatemplate-concurrency", 1,
+		"Number of Metal3DataTemplates to process simultaneously")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", 5*time.Millisecond,
+		"The initial delay before retrying an object whose reconciliation failed. The delay doubles for each consecutive failure.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", 1000*time.Second,
+		"The maximum delay before retrying an object whose reconciliation failed.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", 10,
+		"The overall number of reconciliations per second of each controller, when retrying objects.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", 100,
+		"The burst of reconciliations of each controller, when retrying objects.")
+	flag.DurationVar(&clusterHealthCheckInterval, "workload-cluster-health-check-interval", time.Minute,
+		"The interval at which the workload clusters are checked to be reachable.")
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
@@ -160,42 +197,91 @@ func setupReconcilers(mgr ctrl.Manager) {
 	if webhookPort != 0 {
 		return
 	}
+	clusterTracker := capm3remote.NewClusterClientTracker(clusterHealthCheckInterval)
+	if err := mgr.Add(clusterTracker); err != nil {
+		setupLog.Error(err, "unable to create the workload cluster tracker")
+		os.Exit(1)
+	}
+
+	if err := metrics.Registry.Register(
+		baremetal.NewHostPoolCollector(mgr.GetClient()),
+	); err != nil {
+		setupLog.Error(err, "unable to register the BareMetalHost metrics")
+		os.Exit(1)
+	}
+
 	if err := (&controllers.Metal3MachineReconciler{
 		Client:           mgr.GetClient(),
-		ManagerFactory:   baremetal.NewManagerFactory(mgr.GetClient()),
+		ManagerFactory:   baremetal.NewManagerFactory(mgr.GetClient(), mgr.GetEventRecorderFor("metal3machine-controller")),
 		Log:              ctrl.Log.WithName("controllers").WithName("Metal3Machine"),
-		CapiClientGetter: capm3remote.NewClusterClient,
-	}).SetupWithManager(mgr); err != nil {
+		CapiClientGetter: clusterTracker.GetClient,
+		RequeueBackoff:   controllers.NewRequeueBackoff(requeueMinDelay, requeueMaxDelay),
+		ClusterTracker:   clusterTracker,
+	}).SetupWithManager(mgr, concurrency(metal3MachineConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Metal3MachineReconciler")
 		os.Exit(1)
 	}
 
 	if err := (&controllers.Metal3ClusterReconciler{
 		Client:         mgr.GetClient(),
-		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient()),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient(), mgr.GetEventRecorderFor("metal3cluster-controller")),
 		Log:            ctrl.Log.WithName("controllers").WithName("Metal3Cluster"),
-	}).SetupWithManager(mgr); err != nil {
+		ClusterTracker: clusterTracker,
+	}).SetupWithManager(mgr, concurrency(metal3ClusterConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Metal3ClusterReconciler")
 		os.Exit(1)
 	}
 
 	if err := (&controllers.Metal3DataTemplateReconciler{
 		Client:         mgr.GetClient(),
-		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient()),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient(), mgr.GetEventRecorderFor("metal3datatemplate-controller")),
 		Log:            ctrl.Log.WithName("controllers").WithName("Metal3DataTemplate"),
-	}).SetupWithManager(mgr); err != nil {
+		RequeueBackoff: controllers.NewRequeueBackoff(requeueMinDelay, requeueMaxDelay),
+	}).SetupWithManager(mgr, concurrency(metal3DataTemplateConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Metal3DataTemplateReconciler")
 		os.Exit(1)
 	}
 
 	if err := (&controllers.Metal3DataReconciler{
 		Client:         mgr.GetClient(),
-		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient()),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient(), mgr.GetEventRecorderFor("metal3data-controller")),
 		Log:            ctrl.Log.WithName("controllers").WithName("Metal3Data"),
-	}).SetupWithManager(mgr); err != nil {
+		RequeueBackoff: controllers.NewRequeueBackoff(requeueMinDelay, requeueMaxDelay),
+	}).SetupWithManager(mgr, concurrency(metal3DataConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Metal3DataReconciler")
 		os.Exit(1)
 	}
+
+	if err := (&controllers.Metal3RemediationReconciler{
+		Client:         mgr.GetClient(),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient(), mgr.GetEventRecorderFor("metal3remediation-controller")),
+		Log:            ctrl.Log.WithName("controllers").WithName("Metal3Remediation"),
+	}).SetupWithManager(mgr, concurrency(1)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Metal3RemediationReconciler")
+		os.Exit(1)
+	}
+}
+
+// concurrency returns the options of a controller reconciling up to count
+// objects simultaneously.
+func concurrency(count int) controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: count,
+		RateLimiter:             rateLimiter(),
+	}
+}
+
+// rateLimiter returns a new rate limiter for the workqueue of a controller,
+// combining a per-object exponential backoff with an overall token bucket.
+func rateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(rateLimiterBaseDelay,
+			rateLimiterMaxDelay,
+		),
+		&workqueue.BucketRateLimiter{
+			Limiter: rate.NewLimiter(rate.Limit(rateLimiterQPS), rateLimiterBurst),
+		},
+	)
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -287,4 +373,14 @@ func setupWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3DataClaim")
 		os.Exit(1)
 	}
+
+	if err := (&infrav1.Metal3Remediation{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3Remediation")
+		os.Exit(1)
+	}
+
+	if err := (&infrav1.Metal3RemediationTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3RemediationTemplate")
+		os.Exit(1)
+	}
 }